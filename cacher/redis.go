@@ -6,6 +6,10 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Cacher :nodoc:
@@ -15,21 +19,62 @@ type Cacher interface {
 
 	// Set set a cache value by key with the given expiry time. The value should be a json string
 	Set(ctx context.Context, key string, value string, exp time.Duration) error
+
+	// SetNX set a cache value by key with the given expiry time only if the key does not exist yet.
+	// Return true if the value is set. Useful as a lock since the check and set is atomic
+	SetNX(ctx context.Context, key string, value string, exp time.Duration) (bool, error)
+
+	// Delete delete the cache value by key. Deleting non existing key is not an error
+	Delete(ctx context.Context, key string) error
 }
 
 type cacher struct {
 	client *redis.Client
+
+	// tracer if nil, tracing is disabled
+	tracer trace.Tracer
+}
+
+// Opts is the options for NewCacherWithOpts
+type Opts struct {
+	// TracerProvider enables tracing when not nil. A client span is created for every Get and Set
+	TracerProvider trace.TracerProvider
 }
 
 // NewCacher return a new model.Chacher instance
 func NewCacher(client *redis.Client) Cacher {
-	return &cacher{
+	return NewCacherWithOpts(client, nil)
+}
+
+// NewCacherWithOpts return a new model.Chacher instance with options
+func NewCacherWithOpts(client *redis.Client, opts *Opts) Cacher {
+	c := &cacher{
 		client: client,
 	}
+
+	if opts != nil && opts.TracerProvider != nil {
+		c.tracer = tracing.Tracer(opts.TracerProvider)
+	}
+
+	return c
 }
 
-func (c *cacher) Get(ctx context.Context, key string) (string, error) {
-	res, err := c.client.Get(ctx, key).Result()
+func (c *cacher) Get(ctx context.Context, key string) (res string, err error) {
+	if c.tracer != nil {
+		var span trace.Span
+		ctx, span = c.startSpan(ctx, "GET", key)
+		defer func() {
+			span.SetAttributes(attribute.Bool("cache.hit", err == nil))
+			if err == redis.Nil {
+				span.End()
+				return
+			}
+
+			tracing.EndSpan(span, err)
+		}()
+	}
+
+	res, err = c.client.Get(ctx, key).Result()
 	switch err {
 	case nil:
 		return res, nil
@@ -40,11 +85,54 @@ func (c *cacher) Get(ctx context.Context, key string) (string, error) {
 	}
 }
 
-func (c *cacher) Set(ctx context.Context, key string, val string, exp time.Duration) error {
-	err := c.client.Set(ctx, key, val, exp).Err()
+func (c *cacher) Set(ctx context.Context, key string, val string, exp time.Duration) (err error) {
+	if c.tracer != nil {
+		var span trace.Span
+		ctx, span = c.startSpan(ctx, "SET", key)
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+	}
+
+	err = c.client.Set(ctx, key, val, exp).Err()
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (c *cacher) SetNX(ctx context.Context, key string, val string, exp time.Duration) (ok bool, err error) {
+	if c.tracer != nil {
+		var span trace.Span
+		ctx, span = c.startSpan(ctx, "SETNX", key)
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+	}
+
+	return c.client.SetNX(ctx, key, val, exp).Result()
+}
+
+func (c *cacher) Delete(ctx context.Context, key string) (err error) {
+	if c.tracer != nil {
+		var span trace.Span
+		ctx, span = c.startSpan(ctx, "DEL", key)
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+	}
+
+	return c.client.Del(ctx, key).Err()
+}
+
+func (c *cacher) startSpan(ctx context.Context, operation, key string) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, "cache "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemRedis,
+			semconv.DBOperation(operation),
+			attribute.String("cache.key", key),
+		),
+	)
+}
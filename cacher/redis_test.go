@@ -3,10 +3,14 @@ package cacher
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 func TestCacher_Get(t *testing.T) {
@@ -87,3 +91,127 @@ func TestCacher_Set(t *testing.T) {
 		mr.SetError("")
 	})
 }
+
+func TestCacher_Tracing(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	defer mr.Close()
+
+	tp, exporter := tracing.NewInMemoryTracerProvider("test-service")
+	cacher := NewCacherWithOpts(redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+		DB:   0,
+	}), &Opts{TracerProvider: tp})
+
+	ctx := context.TODO()
+
+	t.Run("hit", func(t *testing.T) {
+		exporter.Reset()
+		assert.NoError(t, mr.Set("key", "value"))
+
+		_, err := cacher.Get(ctx, "key")
+		assert.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "cache GET", spans[0].Name)
+		assert.Contains(t, spans[0].Attributes, attribute.Bool("cache.hit", true))
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	})
+
+	t.Run("miss is not an error", func(t *testing.T) {
+		exporter.Reset()
+
+		_, err := cacher.Get(ctx, "not_found")
+		assert.Equal(t, redis.Nil, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Contains(t, spans[0].Attributes, attribute.Bool("cache.hit", false))
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		exporter.Reset()
+		mr.SetError("err redis")
+
+		err := cacher.Set(ctx, "key", "value", 1000)
+		assert.Error(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "cache SET", spans[0].Name)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+
+		mr.SetError("")
+	})
+}
+
+func TestCacher_SetNX(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	defer mr.Close()
+
+	cacher := NewCacher(redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+		DB:   0,
+	}))
+
+	ctx := context.TODO()
+
+	t.Run("ok", func(t *testing.T) {
+		ok, err := cacher.SetNX(ctx, "lock", "first", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = cacher.SetNX(ctx, "lock", "second", time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		res, err := mr.Get("lock")
+		assert.NoError(t, err)
+		assert.Equal(t, "first", res)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mr.SetError("err redis")
+
+		_, err := cacher.SetNX(ctx, "other", "value", time.Minute)
+		assert.Error(t, err)
+
+		mr.SetError("")
+	})
+}
+
+func TestCacher_Delete(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	defer mr.Close()
+
+	cacher := NewCacher(redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+		DB:   0,
+	}))
+
+	ctx := context.TODO()
+
+	t.Run("ok", func(t *testing.T) {
+		assert.NoError(t, mr.Set("key", "value"))
+
+		assert.NoError(t, cacher.Delete(ctx, "key"))
+		assert.False(t, mr.Exists("key"))
+
+		assert.NoError(t, cacher.Delete(ctx, "not_found"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mr.SetError("err redis")
+
+		assert.Error(t, cacher.Delete(ctx, "key"))
+
+		mr.SetError("")
+	})
+}
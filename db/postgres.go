@@ -2,17 +2,37 @@
 package db
 
 import (
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// PostgresOpts is the options for NewPostgresDBWithOpts
+type PostgresOpts struct {
+	// TracerProvider enables tracing when not nil by registering the plugin created using NewTracingPlugin.
+	// Use gorm.DB.WithContext so the query spans will be the children of the request span
+	TracerProvider trace.TracerProvider
+}
+
 // NewPostgresDB initialize postgres db connection
 // and return gorm.DB instance without any modification
 func NewPostgresDB(dsn string) (*gorm.DB, error) {
+	return NewPostgresDBWithOpts(dsn, nil)
+}
+
+// NewPostgresDBWithOpts initialize postgres db connection
+// and return gorm.DB instance configured based on the options
+func NewPostgresDBWithOpts(dsn string, opts *PostgresOpts) (*gorm.DB, error) {
 	conn, err := gorm.Open(postgres.Open(dsn))
 	if err != nil {
 		return nil, err
 	}
 
+	if opts != nil && opts.TracerProvider != nil {
+		if err := conn.Use(NewTracingPlugin(opts.TracerProvider)); err != nil {
+			return nil, err
+		}
+	}
+
 	return conn, nil
 }
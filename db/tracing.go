@@ -0,0 +1,111 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracingPluginName = "stdlib:tracing"
+	tracingSpanKey    = "stdlib:tracing:span"
+)
+
+type callbackRegisterer interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+type tracingPlugin struct {
+	tracer trace.Tracer
+}
+
+// NewTracingPlugin creates gorm plugin which creates a client span for every query executed using the statement context.
+// Only the SQL without the bound values is recorded, so no sensitive value will leak to the tracing backend.
+// If tp is nil, will use the OpenTelemetry global tracer provider
+func NewTracingPlugin(tp trace.TracerProvider) gorm.Plugin {
+	return &tracingPlugin{
+		tracer: tracing.Tracer(tp),
+	}
+}
+
+// Name return the plugin name
+func (p *tracingPlugin) Name() string {
+	return tracingPluginName
+}
+
+// Initialize registers the before and after callbacks surrounding every gorm operation
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	registrations := []struct {
+		operation string
+		before    callbackRegisterer
+		after     callbackRegisterer
+	}{
+		{"create", cb.Create().Before("gorm:create"), cb.Create().After("gorm:create")},
+		{"query", cb.Query().Before("gorm:query"), cb.Query().After("gorm:query")},
+		{"update", cb.Update().Before("gorm:update"), cb.Update().After("gorm:update")},
+		{"delete", cb.Delete().Before("gorm:delete"), cb.Delete().After("gorm:delete")},
+		{"row", cb.Row().Before("gorm:row"), cb.Row().After("gorm:row")},
+		{"raw", cb.Raw().Before("gorm:raw"), cb.Raw().After("gorm:raw")},
+	}
+
+	for _, r := range registrations {
+		if err := r.before.Register(tracingPluginName+":before_"+r.operation, p.before(r.operation)); err != nil {
+			return err
+		}
+
+		if err := r.after.Register(tracingPluginName+":after_"+r.operation, p.after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		_, span := p.tracer.Start(tx.Statement.Context, "db "+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemKey.String(tx.Dialector.Name()),
+				semconv.DBOperation(operation),
+			),
+		)
+
+		tx.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func (p *tracingPlugin) after(tx *gorm.DB) {
+	val, ok := tx.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+
+	span, ok := val.(trace.Span)
+	if !ok {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.DBStatement(tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	}
+
+	if tx.Statement.Table != "" {
+		attrs = append(attrs, semconv.DBSQLTable(tx.Statement.Table))
+	}
+
+	span.SetAttributes(attrs...)
+
+	err := tx.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+
+	tracing.EndSpan(span, err)
+}
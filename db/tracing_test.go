@@ -0,0 +1,60 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/db"
+	"github.com/sweet-go/stdlib/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type user struct {
+	ID   int64
+	Name string
+}
+
+func TestTracingPlugin(t *testing.T) {
+	tp, exporter := tracing.NewInMemoryTracerProvider("test-service")
+
+	conn, err := gorm.Open(postgres.Open("host=localhost user=test dbname=test"), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, conn.Use(db.NewTracingPlugin(tp)))
+
+	t.Run("ok", func(t *testing.T) {
+		exporter.Reset()
+
+		ctx, parent := tracing.Tracer(tp).Start(context.TODO(), "parent")
+		res := conn.WithContext(ctx).Where("name = ?", "secret name").Find(&[]user{})
+		assert.NoError(t, res.Error)
+		parent.End()
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 2)
+		assert.Equal(t, "db query", spans[0].Name)
+		assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+		assert.Contains(t, spans[0].Attributes, semconv.DBSQLTable("users"))
+		assert.Contains(t, spans[0].Attributes, semconv.DBStatement(`SELECT * FROM "users" WHERE name = $1`))
+	})
+
+	t.Run("create", func(t *testing.T) {
+		exporter.Reset()
+
+		res := conn.WithContext(context.TODO()).Create(&user{Name: "test"})
+		assert.NoError(t, res.Error)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "db create", spans[0].Name)
+		assert.Contains(t, spans[0].Attributes, semconv.DBOperation("create"))
+	})
+}
@@ -0,0 +1,194 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// list of supported JWE algorithms
+const (
+	// JWEAlgRSAOAEP256 is the key management algorithm used to wrap the content encryption key
+	JWEAlgRSAOAEP256 = "RSA-OAEP-256"
+
+	// JWEEncA256GCM is the content encryption algorithm used to encrypt the payload
+	JWEEncA256GCM = "A256GCM"
+
+	// JWEContentTypeJWT is the content type used when the JWE payload is a signed JWT (nested token)
+	JWEContentTypeJWT = "JWT"
+)
+
+var (
+	// ErrJWEMalformed is returned when the supplied JWE token is not in compact serialization format
+	ErrJWEMalformed = errors.New("jwe error: malformed token")
+
+	// ErrJWEUnsupportedAlgorithm is returned when the JWE header contains unsupported alg or enc
+	ErrJWEUnsupportedAlgorithm = errors.New("jwe error: unsupported algorithm")
+
+	// ErrJWEKeyNotConfigured is returned when the required key to encrypt or decrypt the JWE is nil
+	ErrJWEKeyNotConfigured = errors.New("jwe error: key is not configured")
+)
+
+// JWEHeader is the protected header of a JWE token
+type JWEHeader struct {
+	Alg   string `json:"alg"`
+	Enc   string `json:"enc"`
+	Cty   string `json:"cty,omitempty"`
+	KeyID string `json:"kid,omitempty"`
+}
+
+// JWEEncryptionOpts is the options to encrypt a JWE token.
+// all struct fields are required unless otherwise noted.
+type JWEEncryptionOpts struct {
+	// Random if nil, will use crypto/rand.Reader
+	Random    io.Reader
+	PublicKey *rsa.PublicKey
+
+	// ContentType is optional. Use JWEContentTypeJWT when the payload is a signed JWT
+	ContentType string
+
+	// KeyID is optional
+	KeyID string
+}
+
+// JWEDecryptionOpts is the options to decrypt a JWE token.
+// all struct fields are required unless otherwise noted.
+type JWEDecryptionOpts struct {
+	// Random if nil, will use crypto/rand.Reader
+	Random     io.Reader
+	PrivateKey *rsa.PrivateKey
+}
+
+// EncryptJWE will encrypt the payload into a JWE compact serialization token
+// using RSA-OAEP-256 key wrapping and A256GCM content encryption
+func EncryptJWE(payload []byte, opts *JWEEncryptionOpts) (string, error) {
+	if opts == nil || opts.PublicKey == nil {
+		return "", ErrJWEKeyNotConfigured
+	}
+
+	random := opts.Random
+	if random == nil {
+		random = rand.Reader
+	}
+
+	header, err := json.Marshal(&JWEHeader{
+		Alg:   JWEAlgRSAOAEP256,
+		Enc:   JWEEncA256GCM,
+		Cty:   opts.ContentType,
+		KeyID: opts.KeyID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	cek := make([]byte, AES256)
+	if _, err := io.ReadFull(random, cek); err != nil {
+		return "", err
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), random, opts.PublicKey, cek, nil)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newJWEGCM(cek)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(random, iv); err != nil {
+		return "", err
+	}
+
+	encodedHeader := base64.RawURLEncoding.EncodeToString(header)
+	sealed := gcm.Seal(nil, iv, payload, []byte(encodedHeader))
+	tagStart := len(sealed) - gcm.Overhead()
+
+	return strings.Join([]string{
+		encodedHeader,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(sealed[:tagStart]),
+		base64.RawURLEncoding.EncodeToString(sealed[tagStart:]),
+	}, "."), nil
+}
+
+// DecryptJWE will decrypt the JWE compact serialization token and return the plain payload
+// alongside the protected header
+func DecryptJWE(token string, opts *JWEDecryptionOpts) ([]byte, *JWEHeader, error) {
+	if opts == nil || opts.PrivateKey == nil {
+		return nil, nil, ErrJWEKeyNotConfigured
+	}
+
+	random := opts.Random
+	if random == nil {
+		random = rand.Reader
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, nil, ErrJWEMalformed
+	}
+
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		b, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return nil, nil, ErrJWEMalformed
+		}
+
+		decoded[i] = b
+	}
+
+	header := &JWEHeader{}
+	if err := json.Unmarshal(decoded[0], header); err != nil {
+		return nil, nil, ErrJWEMalformed
+	}
+
+	if header.Alg != JWEAlgRSAOAEP256 || header.Enc != JWEEncA256GCM {
+		return nil, header, ErrJWEUnsupportedAlgorithm
+	}
+
+	cek, err := rsa.DecryptOAEP(sha256.New(), random, opts.PrivateKey, decoded[1], nil)
+	if err != nil {
+		return nil, header, err
+	}
+
+	gcm, err := newJWEGCM(cek)
+	if err != nil {
+		return nil, header, err
+	}
+
+	if len(decoded[2]) != gcm.NonceSize() {
+		return nil, header, ErrJWEMalformed
+	}
+
+	sealed := append(decoded[3], decoded[4]...)
+	payload, err := gcm.Open(nil, decoded[2], sealed, []byte(parts[0]))
+	if err != nil {
+		return nil, header, err
+	}
+
+	return payload, header, nil
+}
+
+func newJWEGCM(cek []byte) (cipher.AEAD, error) {
+	if len(cek) != int(AES256) {
+		return nil, ErrJWEMalformed
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,86 @@
+package encryption_test
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/encryption"
+)
+
+func TestJWE(t *testing.T) {
+	key, err := encryption.GenerateKey(&encryption.KeyGenerationOpts{
+		Random: rand.Reader,
+		Bits:   2048,
+	})
+	assert.NoError(t, err)
+
+	payload := []byte(`{"email":"user@mail.test","phone":"+628123456789"}`)
+
+	t.Run("ok", func(t *testing.T) {
+		token, err := encryption.EncryptJWE(payload, &encryption.JWEEncryptionOpts{
+			PublicKey:   &key.PublicKey,
+			ContentType: encryption.JWEContentTypeJWT,
+			KeyID:       "key-1",
+		})
+		assert.NoError(t, err)
+		assert.Len(t, strings.Split(token, "."), 5)
+		assert.NotContains(t, token, "user@mail.test")
+
+		res, header, err := encryption.DecryptJWE(token, &encryption.JWEDecryptionOpts{
+			PrivateKey: key,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, payload, res)
+		assert.Equal(t, encryption.JWEAlgRSAOAEP256, header.Alg)
+		assert.Equal(t, encryption.JWEEncA256GCM, header.Enc)
+		assert.Equal(t, encryption.JWEContentTypeJWT, header.Cty)
+		assert.Equal(t, "key-1", header.KeyID)
+	})
+
+	t.Run("key not configured", func(t *testing.T) {
+		_, err := encryption.EncryptJWE(payload, &encryption.JWEEncryptionOpts{})
+		assert.ErrorIs(t, err, encryption.ErrJWEKeyNotConfigured)
+
+		_, _, err = encryption.DecryptJWE("a.b.c.d.e", nil)
+		assert.ErrorIs(t, err, encryption.ErrJWEKeyNotConfigured)
+	})
+
+	t.Run("malformed token", func(t *testing.T) {
+		_, _, err := encryption.DecryptJWE("not.a.jwe", &encryption.JWEDecryptionOpts{
+			PrivateKey: key,
+		})
+		assert.ErrorIs(t, err, encryption.ErrJWEMalformed)
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		token, err := encryption.EncryptJWE(payload, &encryption.JWEEncryptionOpts{
+			PublicKey: &key.PublicKey,
+		})
+		assert.NoError(t, err)
+
+		parts := strings.Split(token, ".")
+		parts[3] = strings.Repeat("A", len(parts[3]))
+
+		_, _, err = encryption.DecryptJWE(strings.Join(parts, "."), &encryption.JWEDecryptionOpts{
+			PrivateKey: key,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("wrong private key", func(t *testing.T) {
+		otherKey, err := encryption.GenerateKey(nil)
+		assert.NoError(t, err)
+
+		token, err := encryption.EncryptJWE(payload, &encryption.JWEEncryptionOpts{
+			PublicKey: &key.PublicKey,
+		})
+		assert.NoError(t, err)
+
+		_, _, err = encryption.DecryptJWE(token, &encryption.JWEDecryptionOpts{
+			PrivateKey: otherKey,
+		})
+		assert.Error(t, err)
+	})
+}
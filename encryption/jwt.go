@@ -19,11 +19,21 @@ type JWTTokenGenerator interface {
 	// BuildEchoJWTMiddleware builds a echo middleware for JWT token validation
 	// with configuration set according to supplied Method and SigningKey in NewJWTTokenHandler
 	BuildEchoJWTMiddleware() echo.MiddlewareFunc
+
+	// GenerateEncryptedJWTToken generates a signed JWT token string then encrypt it as JWE (nested token).
+	// Use this when the claims contain sensitive data that must not be readable by the client.
+	// Requires the handler to be created using NewJWTTokenHandlerWithEncryption
+	GenerateEncryptedJWTToken(payload jwt.Claims) (string, error)
+
+	// ValidateEncryptedJWTToken decrypts the JWE token and validates the nested JWT token.
+	// Same as ValidateJWTToken, if error is not nil, consider the token as invalid
+	ValidateEncryptedJWTToken(token string) (*jwt.Token, error)
 }
 
 type jwtToken struct {
-	Method     jwt.SigningMethod
-	SigningKey []byte
+	Method        jwt.SigningMethod
+	SigningKey    []byte
+	EncryptionKey *KeyComponent
 }
 
 // NewJWTTokenHandler creates a new JWTTokenGenerator
@@ -34,6 +44,17 @@ func NewJWTTokenHandler(method jwt.SigningMethod, signingKey []byte) JWTTokenGen
 	}
 }
 
+// NewJWTTokenHandlerWithEncryption creates a new JWTTokenGenerator able to issue and validate encrypted tokens.
+// encryptionKey is expected to be loaded using ReadKeyFromFile (to issue & validate)
+// or ReadPublicKeyFromFile (to issue only)
+func NewJWTTokenHandlerWithEncryption(method jwt.SigningMethod, signingKey []byte, encryptionKey *KeyComponent) JWTTokenGenerator {
+	return &jwtToken{
+		Method:        method,
+		SigningKey:    signingKey,
+		EncryptionKey: encryptionKey,
+	}
+}
+
 func (jtg *jwtToken) GenerateJWTToken(payload jwt.Claims) (string, error) {
 	token := jwt.NewWithClaims(jtg.Method, payload)
 
@@ -63,3 +84,38 @@ func (jtg *jwtToken) BuildEchoJWTMiddleware() echo.MiddlewareFunc {
 		SigningMethod: jtg.Method.Alg(),
 	})
 }
+
+func (jtg *jwtToken) GenerateEncryptedJWTToken(payload jwt.Claims) (string, error) {
+	if jtg.EncryptionKey == nil || jtg.EncryptionKey.PublicKey == nil {
+		return "", ErrJWEKeyNotConfigured
+	}
+
+	signed, err := jtg.GenerateJWTToken(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return EncryptJWE([]byte(signed), &JWEEncryptionOpts{
+		PublicKey:   jtg.EncryptionKey.PublicKey,
+		ContentType: JWEContentTypeJWT,
+	})
+}
+
+func (jtg *jwtToken) ValidateEncryptedJWTToken(token string) (*jwt.Token, error) {
+	if jtg.EncryptionKey == nil || jtg.EncryptionKey.PrivateKey == nil {
+		return nil, ErrJWEKeyNotConfigured
+	}
+
+	signed, header, err := DecryptJWE(token, &JWEDecryptionOpts{
+		PrivateKey: jtg.EncryptionKey.PrivateKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Cty != JWEContentTypeJWT {
+		return nil, ErrJWEMalformed
+	}
+
+	return jtg.ValidateJWTToken(string(signed))
+}
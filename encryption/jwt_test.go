@@ -1,6 +1,7 @@
 package encryption_test
 
 import (
+	"crypto/rand"
 	"testing"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -44,3 +45,78 @@ func TestGenerateJWTToken(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestEncryptedJWTToken(t *testing.T) {
+	key, err := encryption.GenerateKey(&encryption.KeyGenerationOpts{
+		Random: rand.Reader,
+		Bits:   2048,
+	})
+	assert.NoError(t, err)
+
+	keyComponent := &encryption.KeyComponent{
+		PrivateKey: key,
+		PublicKey:  &key.PublicKey,
+	}
+
+	type piiClaims struct {
+		jwt.RegisteredClaims
+		Email string `json:"email"`
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		jwtgen := encryption.NewJWTTokenHandlerWithEncryption(jwt.SigningMethodHS256, []byte("secret"), keyComponent)
+
+		token, err := jwtgen.GenerateEncryptedJWTToken(&piiClaims{
+			RegisteredClaims: jwt.RegisteredClaims{Issuer: "test"},
+			Email:            "user@mail.test",
+		})
+		assert.NoError(t, err)
+
+		_, err = jwtgen.ValidateJWTToken(token)
+		assert.Error(t, err)
+
+		res, err := jwtgen.ValidateEncryptedJWTToken(token)
+		assert.NoError(t, err)
+
+		claims, ok := res.Claims.(jwt.MapClaims)
+		assert.True(t, ok)
+		assert.Equal(t, "user@mail.test", claims["email"])
+	})
+
+	t.Run("encryption key not configured", func(t *testing.T) {
+		jwtgen := encryption.NewJWTTokenHandler(jwt.SigningMethodHS256, []byte("secret"))
+
+		_, err := jwtgen.GenerateEncryptedJWTToken(jwt.RegisteredClaims{Issuer: "test"})
+		assert.ErrorIs(t, err, encryption.ErrJWEKeyNotConfigured)
+
+		_, err = jwtgen.ValidateEncryptedJWTToken("a.b.c.d.e")
+		assert.ErrorIs(t, err, encryption.ErrJWEKeyNotConfigured)
+	})
+
+	t.Run("issue only using public key", func(t *testing.T) {
+		issuer := encryption.NewJWTTokenHandlerWithEncryption(jwt.SigningMethodHS256, []byte("secret"), &encryption.KeyComponent{
+			PublicKey: &key.PublicKey,
+		})
+
+		token, err := issuer.GenerateEncryptedJWTToken(jwt.RegisteredClaims{Issuer: "test"})
+		assert.NoError(t, err)
+
+		_, err = issuer.ValidateEncryptedJWTToken(token)
+		assert.ErrorIs(t, err, encryption.ErrJWEKeyNotConfigured)
+
+		validator := encryption.NewJWTTokenHandlerWithEncryption(jwt.SigningMethodHS256, []byte("secret"), keyComponent)
+		_, err = validator.ValidateEncryptedJWTToken(token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("bad nested signature", func(t *testing.T) {
+		issuer := encryption.NewJWTTokenHandlerWithEncryption(jwt.SigningMethodHS256, []byte("secret"), keyComponent)
+		validator := encryption.NewJWTTokenHandlerWithEncryption(jwt.SigningMethodHS256, []byte("other secret"), keyComponent)
+
+		token, err := issuer.GenerateEncryptedJWTToken(jwt.RegisteredClaims{Issuer: "test"})
+		assert.NoError(t, err)
+
+		_, err = validator.ValidateEncryptedJWTToken(token)
+		assert.Error(t, err)
+	})
+}
@@ -49,6 +49,21 @@ func (mr *MockJWTTokenGeneratorMockRecorder) BuildEchoJWTMiddleware() *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildEchoJWTMiddleware", reflect.TypeOf((*MockJWTTokenGenerator)(nil).BuildEchoJWTMiddleware))
 }
 
+// GenerateEncryptedJWTToken mocks base method.
+func (m *MockJWTTokenGenerator) GenerateEncryptedJWTToken(arg0 jwt.Claims) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateEncryptedJWTToken", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateEncryptedJWTToken indicates an expected call of GenerateEncryptedJWTToken.
+func (mr *MockJWTTokenGeneratorMockRecorder) GenerateEncryptedJWTToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateEncryptedJWTToken", reflect.TypeOf((*MockJWTTokenGenerator)(nil).GenerateEncryptedJWTToken), arg0)
+}
+
 // GenerateJWTToken mocks base method.
 func (m *MockJWTTokenGenerator) GenerateJWTToken(arg0 jwt.Claims) (string, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +79,21 @@ func (mr *MockJWTTokenGeneratorMockRecorder) GenerateJWTToken(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateJWTToken", reflect.TypeOf((*MockJWTTokenGenerator)(nil).GenerateJWTToken), arg0)
 }
 
+// ValidateEncryptedJWTToken mocks base method.
+func (m *MockJWTTokenGenerator) ValidateEncryptedJWTToken(arg0 string) (*jwt.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateEncryptedJWTToken", arg0)
+	ret0, _ := ret[0].(*jwt.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateEncryptedJWTToken indicates an expected call of ValidateEncryptedJWTToken.
+func (mr *MockJWTTokenGeneratorMockRecorder) ValidateEncryptedJWTToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateEncryptedJWTToken", reflect.TypeOf((*MockJWTTokenGenerator)(nil).ValidateEncryptedJWTToken), arg0)
+}
+
 // ValidateJWTToken mocks base method.
 func (m *MockJWTTokenGenerator) ValidateJWTToken(arg0 string) (*jwt.Token, error) {
 	m.ctrl.T.Helper()
@@ -2,18 +2,21 @@
 package example
 
 import (
+	"context"
 	"crypto"
 	"crypto/rand"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
 	"github.com/sweet-go/stdlib/encryption"
 	stdlib_http "github.com/sweet-go/stdlib/http"
 )
 
 // APIResponse is example how to run API and see the API response with signature
 func APIResponse() {
-	ec := echo.New()
+	srv := stdlib_http.NewServer(nil)
+	ec := srv.Echo()
 	privatePem := encryption.ParseTestKey(`-----BEGIN RSA TESTING KEY-----
 MIIEowIBAAKCAQEAwiSX09qKwzg+eunMwn4AulMCHc2z77jy2Mx0Ehc4x014l0Oz
 W5+V5rYadipEM2gLLCdb2nE9hY0+0zC3GEoxoT5ksLdDw6kSOiI5iYQsKkULG9aT
@@ -75,5 +78,7 @@ SGUVK+7JIUp8ee5gCqsJrzK82j00IH6kk6zrX6zvQN3Zd5O4ImZd
 		return c.JSON(http.StatusOK, resp)
 	})
 
-	ec.Logger.Fatal(ec.Start(":8080"))
+	if err := srv.Run(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
 }
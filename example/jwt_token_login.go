@@ -1,6 +1,7 @@
 package example
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -8,11 +9,13 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 	"github.com/sweet-go/stdlib/encryption"
+	stdlib_http "github.com/sweet-go/stdlib/http"
 )
 
 // JWTToken is an example of how to use JWT token
 func JWTToken() {
-	ec := echo.New()
+	srv := stdlib_http.NewServer(nil)
+	ec := srv.Echo()
 
 	privatePem := encryption.ParseTestKey(`-----BEGIN RSA TESTING KEY-----
 MIIEpQIBAAKCAQEA0Ft5dkFcydEexXT7QD/X+XHRd9PZ4SRLjHP86mJICmu1es7Y
@@ -83,5 +86,7 @@ ugO2voQz8H++ZICpQiCns4CdLtPC1fAq2LOzinOq45Hb3XFWT7c1N5k=
 		})
 	})
 
-	ec.Logger.Fatal(ec.Start(":8080"))
+	if err := srv.Run(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
 }
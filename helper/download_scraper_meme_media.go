@@ -3,16 +3,65 @@ package helper
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gabriel-vasile/mimetype"
 )
 
+// list of default DownloadOpts
+const (
+	DefaultDownloadTimeout = 2 * time.Minute
+	DefaultDownloadMaxSize = 100 << 20
+)
+
+// ErrDownloadTooLarge is returned when the downloaded media exceeds DownloadOpts.MaxSize
+var ErrDownloadTooLarge = errors.New("downloaded media is too large")
+
+// Doer is the http client executing the request. Satisfied by *http.Client and the resilient client
+// created by github.com/sweet-go/stdlib/http.NewClient
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DownloadOpts is the options for DownloadMemeScrapingResultMediaWithOpts
+type DownloadOpts struct {
+	// Client if nil, will use http.Client with DefaultDownloadTimeout, without retry nor circuit breaker.
+	// The downloader in github.com/sweet-go/stdlib/http uses its resilient client instead
+	Client Doer
+
+	// MaxSize is the max bytes of the media. Default to DefaultDownloadMaxSize
+	MaxSize int64
+}
+
 // DownloadMemeScrapingResultMedia is a function to download meme media from scraping result. For now, works for both meme
-// sourced from 9gag.com and 1cak.com
+// sourced from 9gag.com and 1cak.com. See DownloadMemeScrapingResultMediaWithOpts for more options
+//
+// Deprecated: use github.com/sweet-go/stdlib/http.DownloadMemeScrapingResultMedia, retrying the failed download
 func DownloadMemeScrapingResultMedia(ctx context.Context, url, outputPath, referer string) error {
+	return DownloadMemeScrapingResultMediaWithOpts(ctx, url, outputPath, referer, nil)
+}
+
+// DownloadMemeScrapingResultMediaWithOpts is a function to download meme media from scraping result using the given client.
+// The partially downloaded file is removed on failure
+func DownloadMemeScrapingResultMediaWithOpts(ctx context.Context, url, outputPath, referer string, opts *DownloadOpts) error {
+	if opts == nil {
+		opts = &DownloadOpts{}
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultDownloadTimeout}
+	}
+
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultDownloadMaxSize
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return err
@@ -21,7 +70,6 @@ func DownloadMemeScrapingResultMedia(ctx context.Context, url, outputPath, refer
 	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64; rv:10.0) Gecko/20100101 Firefox/10.0")
 	req.Header.Set("Referer", referer)
 
-	client := http.DefaultClient
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
@@ -30,7 +78,11 @@ func DownloadMemeScrapingResultMedia(ctx context.Context, url, outputPath, refer
 	defer WrapCloser(resp.Body.Close)
 
 	if resp.StatusCode != http.StatusOK {
-		return errors.New("9gag media returning non 200")
+		return fmt.Errorf("meme media returning non 200: %d", resp.StatusCode)
+	}
+
+	if resp.ContentLength > maxSize {
+		return ErrDownloadTooLarge
 	}
 
 	err = os.MkdirAll(outputPath, os.ModePerm)
@@ -40,21 +92,37 @@ func DownloadMemeScrapingResultMedia(ctx context.Context, url, outputPath, refer
 
 	filename := outputPath + GenerateID()
 
-	outfile, err := os.Create(filename)
+	if err := saveDownloadedMedia(filename, resp.Body, maxSize); err != nil {
+		_ = os.Remove(filename)
+		return err
+	}
+
+	mime, err := mimetype.DetectFile(filename)
 	if err != nil {
 		return err
 	}
 
-	_, err = io.Copy(outfile, resp.Body)
+	newFilename := filename + mime.Extension()
+	return os.Rename(filename, newFilename)
+}
+
+func saveDownloadedMedia(filename string, body io.Reader, maxSize int64) error {
+	outfile, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
 
-	mime, err := mimetype.DetectFile(filename)
+	defer WrapCloser(outfile.Close)
+
+	// copy one more byte than allowed to tell the media exceeding the limit apart from the media exactly at the limit
+	n, err := io.Copy(outfile, io.LimitReader(body, maxSize+1))
 	if err != nil {
 		return err
 	}
 
-	newFilename := filename + mime.Extension()
-	return os.Rename(filename, newFilename)
+	if n > maxSize {
+		return ErrDownloadTooLarge
+	}
+
+	return nil
 }
@@ -2,6 +2,7 @@ package helper_test
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/sweet-go/stdlib/helper"
+	stdlib_http "github.com/sweet-go/stdlib/http"
 )
 
 func TestHelper_MultipartFileSaver(t *testing.T) {
@@ -94,3 +96,54 @@ func TestHelper_MultipartFileSaver(t *testing.T) {
 		handler.ServeHTTP(rr, req)
 	})
 }
+
+func TestHelper_DownloadMemeScrapingResultMedia(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+
+	var referer string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		referer = r.Header.Get("Referer")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write(png)
+	}))
+	defer srv.Close()
+
+	t.Run("ok", func(t *testing.T) {
+		dir := t.TempDir() + "/"
+
+		err := helper.DownloadMemeScrapingResultMediaWithOpts(context.TODO(), srv.URL+"/media", dir, "https://9gag.com", &helper.DownloadOpts{
+			Client: stdlib_http.NewClient(&stdlib_http.ClientOpts{HTTPClient: srv.Client()}),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "https://9gag.com", referer)
+
+		files, err := filepath.Glob(dir + "*.png")
+		assert.NoError(t, err)
+		assert.Len(t, files, 1)
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		err := helper.DownloadMemeScrapingResultMediaWithOpts(context.TODO(), srv.URL+"/missing", t.TempDir()+"/", "", &helper.DownloadOpts{
+			Client: srv.Client(),
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		dir := t.TempDir() + "/"
+
+		err := helper.DownloadMemeScrapingResultMediaWithOpts(context.TODO(), srv.URL+"/media", dir, "", &helper.DownloadOpts{
+			Client:  srv.Client(),
+			MaxSize: 8,
+		})
+		assert.ErrorIs(t, err, helper.ErrDownloadTooLarge)
+
+		files, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Empty(t, files)
+	})
+}
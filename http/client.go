@@ -0,0 +1,377 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	echomiddleware "github.com/sweet-go/stdlib/http/echo_middleware"
+	"github.com/sweet-go/stdlib/internal/circuitbreaker"
+)
+
+var (
+	// ErrCircuitOpen is returned without sending the request when the circuit breaker of the host is open
+	ErrCircuitOpen = errors.New("http client error: circuit breaker is open")
+
+	// ErrResponseTooLarge is returned when the response body exceeds ClientOpts.MaxResponseSize
+	ErrResponseTooLarge = errors.New("http client error: response body is too large")
+)
+
+// list of default Client options
+const (
+	DefaultClientTimeout         = 30 * time.Second
+	DefaultClientMaxRetries      = 3
+	DefaultClientMinBackoff      = 100 * time.Millisecond
+	DefaultClientMaxBackoff      = 10 * time.Second
+	DefaultClientMaxRetryAfter   = time.Minute
+	DefaultClientMaxResponseSize = 10 << 20
+)
+
+// DefaultRetryableStatuses is the default list of response statuses to be retried by Client
+var DefaultRetryableStatuses = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooEarly,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// Client is a resilient http client. Satisfied by *http.Client, so the callers can depend on it
+// and use plain *http.Client in tests
+type Client interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// CircuitBreakerOpts is the options for the per host circuit breaker of Client
+type CircuitBreakerOpts = circuitbreaker.Opts
+
+// ClientOpts is the options for Client
+type ClientOpts struct {
+	// HTTPClient if nil, will use a new http.Client using http.DefaultTransport.
+	// Its Timeout should be left zero, use Timeout instead
+	HTTPClient *http.Client
+
+	// Timeout is the timeout of every attempt, including reading the response body. Default to DefaultClientTimeout
+	Timeout time.Duration
+
+	// MaxRetries default to DefaultClientMaxRetries. Set to negative value to disable retry.
+	// Only the requests with idempotent method or Idempotency-Key header are retried,
+	// and the request body must be replayable using http.Request.GetBody
+	MaxRetries int
+
+	// RetryableStatuses if nil, will use DefaultRetryableStatuses
+	RetryableStatuses []int
+
+	// MinBackoff is the base of the exponential backoff with jitter. Default to DefaultClientMinBackoff
+	MinBackoff time.Duration
+
+	// MaxBackoff default to DefaultClientMaxBackoff
+	MaxBackoff time.Duration
+
+	// MaxRetryAfter is the longest Retry-After header to be honored. Longer wait is not retried,
+	// and the response is returned instead. Default to DefaultClientMaxRetryAfter
+	MaxRetryAfter time.Duration
+
+	// MaxResponseSize is the max bytes of response body, reading beyond it returns ErrResponseTooLarge.
+	// Default to DefaultClientMaxResponseSize. Set to negative value to disable the limit
+	MaxResponseSize int64
+
+	// CircuitBreaker if nil, will use the default CircuitBreakerOpts
+	CircuitBreaker        *CircuitBreakerOpts
+	DisableCircuitBreaker bool
+
+	// DisableRequestID disables forwarding the request ID found in the request context as X-Request-ID header
+	DisableRequestID bool
+}
+
+type client struct {
+	httpClient        *http.Client
+	timeout           time.Duration
+	maxRetries        int
+	retryableStatuses map[int]bool
+	minBackoff        time.Duration
+	maxBackoff        time.Duration
+	maxRetryAfter     time.Duration
+	maxResponseSize   int64
+	breaker           *circuitbreaker.Breaker
+}
+
+// NewClient creates a resilient Client. Every attempt has its own timeout, the failed idempotent requests are retried
+// using exponential backoff with jitter honoring the Retry-After header, the consecutively failing hosts are short
+// circuited with ErrCircuitOpen, and the response body is limited to MaxResponseSize
+func NewClient(opts *ClientOpts) Client {
+	if opts == nil {
+		opts = &ClientOpts{}
+	}
+
+	httpClient := &http.Client{}
+	if opts.HTTPClient != nil {
+		c := *opts.HTTPClient
+		httpClient = &c
+	}
+
+	if !opts.DisableRequestID {
+		httpClient.Transport = &RequestIDTransport{
+			Base: httpClient.Transport,
+		}
+	}
+
+	c := &client{
+		httpClient:        httpClient,
+		timeout:           opts.Timeout,
+		maxRetries:        opts.MaxRetries,
+		retryableStatuses: make(map[int]bool),
+		minBackoff:        opts.MinBackoff,
+		maxBackoff:        opts.MaxBackoff,
+		maxRetryAfter:     opts.MaxRetryAfter,
+		maxResponseSize:   opts.MaxResponseSize,
+	}
+
+	if c.timeout <= 0 {
+		c.timeout = DefaultClientTimeout
+	}
+
+	if c.maxRetries == 0 {
+		c.maxRetries = DefaultClientMaxRetries
+	}
+
+	statuses := opts.RetryableStatuses
+	if statuses == nil {
+		statuses = DefaultRetryableStatuses
+	}
+
+	for _, s := range statuses {
+		c.retryableStatuses[s] = true
+	}
+
+	if c.minBackoff <= 0 {
+		c.minBackoff = DefaultClientMinBackoff
+	}
+
+	if c.maxBackoff <= 0 {
+		c.maxBackoff = DefaultClientMaxBackoff
+	}
+
+	if c.maxRetryAfter <= 0 {
+		c.maxRetryAfter = DefaultClientMaxRetryAfter
+	}
+
+	if c.maxResponseSize == 0 {
+		c.maxResponseSize = DefaultClientMaxResponseSize
+	}
+
+	if !opts.DisableCircuitBreaker {
+		c.breaker = circuitbreaker.New(opts.CircuitBreaker)
+	}
+
+	return c
+}
+
+// Do sends the request, retrying when allowed. When the retries are exhausted, the last response or error is returned
+func (c *client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+	retryable := c.isRetryableRequest(req)
+
+	for attempt := 0; ; attempt++ {
+		if !c.breaker.Allow(host) {
+			return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+		}
+
+		res, cancel, err := c.doAttempt(req, attempt)
+		if err != nil {
+			cancel()
+
+			// the caller gave up, the host is not necessarily unhealthy
+			if ctx.Err() != nil {
+				c.breaker.Release(host)
+				return nil, err
+			}
+
+			c.breaker.Failure(host)
+			if !retryable || attempt >= c.maxRetries {
+				return nil, err
+			}
+
+			if err := sleepContext(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if res.StatusCode >= http.StatusInternalServerError {
+			c.breaker.Failure(host)
+		} else {
+			c.breaker.Success(host)
+		}
+
+		if !c.retryableStatuses[res.StatusCode] || !retryable || attempt >= c.maxRetries {
+			return c.limitResponse(res, cancel)
+		}
+
+		wait, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+		if ok && wait > c.maxRetryAfter {
+			return c.limitResponse(res, cancel)
+		}
+
+		if !ok {
+			wait = c.backoff(attempt)
+		}
+
+		// drain a bit of the body so the connection can be reused
+		_, _ = io.CopyN(io.Discard, res.Body, 4<<10)
+		_ = res.Body.Close()
+		cancel()
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *client) doAttempt(req *http.Request, attempt int) (*http.Response, context.CancelFunc, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), c.timeout)
+
+	r := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, cancel, err
+		}
+
+		r.Body = body
+	}
+
+	res, err := c.httpClient.Do(r)
+
+	return res, cancel, err
+}
+
+func (c *client) isRetryableRequest(req *http.Request) bool {
+	if c.maxRetries < 0 {
+		return false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get(echomiddleware.HeaderIdempotencyKey) != ""
+	}
+}
+
+// backoff returns the exponential backoff of the attempt with equal jitter, capped at maxBackoff
+func (c *client) backoff(attempt int) time.Duration {
+	d := c.maxBackoff
+	if attempt < 32 {
+		if exp := c.minBackoff << attempt; exp > 0 && exp < c.maxBackoff {
+			d = exp
+		}
+	}
+
+	half := d / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// limitResponse wraps the response body to enforce maxResponseSize and to release the attempt timeout on close
+func (c *client) limitResponse(res *http.Response, cancel context.CancelFunc) (*http.Response, error) {
+	if c.maxResponseSize > 0 && res.ContentLength > c.maxResponseSize {
+		_ = res.Body.Close()
+		cancel()
+		return nil, ErrResponseTooLarge
+	}
+
+	res.Body = &limitedBody{
+		ReadCloser: res.Body,
+		remaining:  c.maxResponseSize,
+		cancel:     cancel,
+	}
+
+	return res, nil
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	cancel    context.CancelFunc
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return b.ReadCloser.Read(p)
+	}
+
+	// read one more byte than allowed to tell the body exceeding the limit apart from the body exactly at the limit
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		return n, ErrResponseTooLarge
+	}
+
+	b.remaining -= int64(n)
+
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
+
+// parseRetryAfter parses the Retry-After header, either in delay seconds or http date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+
+	return 0, true
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,266 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/http"
+	echomiddleware "github.com/sweet-go/stdlib/http/echo_middleware"
+)
+
+func TestClient(t *testing.T) {
+	newClient := func(opts *http.ClientOpts) http.Client {
+		opts.MinBackoff = time.Millisecond
+		opts.MaxBackoff = 5 * time.Millisecond
+		return http.NewClient(opts)
+	}
+
+	t.Run("retry retryable status", func(t *testing.T) {
+		var attempts int32
+		srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				w.WriteHeader(nethttp.StatusServiceUnavailable)
+				return
+			}
+
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer srv.Close()
+
+		res, err := newClient(&http.ClientOpts{}).Do(mustNewRequest(t, nethttp.MethodGet, srv.URL, nil))
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+		assert.EqualValues(t, 3, attempts)
+	})
+
+	t.Run("return last response when retries exhausted", func(t *testing.T) {
+		var attempts int32
+		srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(nethttp.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		res, err := newClient(&http.ClientOpts{MaxRetries: 2, DisableCircuitBreaker: true}).Do(mustNewRequest(t, nethttp.MethodGet, srv.URL, nil))
+		assert.NoError(t, err)
+		assert.NoError(t, res.Body.Close())
+
+		assert.Equal(t, nethttp.StatusBadGateway, res.StatusCode)
+		assert.EqualValues(t, 3, attempts)
+	})
+
+	t.Run("only retry idempotent request", func(t *testing.T) {
+		var attempts int32
+		var bodies []string
+		srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			if atomic.AddInt32(&attempts, 1)%2 == 1 {
+				w.WriteHeader(nethttp.StatusServiceUnavailable)
+				return
+			}
+
+			w.WriteHeader(nethttp.StatusCreated)
+		}))
+		defer srv.Close()
+
+		client := newClient(&http.ClientOpts{})
+
+		res, err := client.Do(mustNewRequest(t, nethttp.MethodPost, srv.URL, strings.NewReader("payload")))
+		assert.NoError(t, err)
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, nethttp.StatusServiceUnavailable, res.StatusCode)
+		assert.EqualValues(t, 1, attempts)
+
+		atomic.StoreInt32(&attempts, 0)
+		req := mustNewRequest(t, nethttp.MethodPost, srv.URL, strings.NewReader("payload"))
+		req.Header.Set(echomiddleware.HeaderIdempotencyKey, "key")
+		res, err = client.Do(req)
+		assert.NoError(t, err)
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, nethttp.StatusCreated, res.StatusCode)
+		assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+	})
+
+	t.Run("honor retry after", func(t *testing.T) {
+		var attempts int32
+		srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.Header().Set("Retry-After", r.URL.Query().Get("after"))
+				w.WriteHeader(nethttp.StatusTooManyRequests)
+				return
+			}
+
+			w.WriteHeader(nethttp.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		client := newClient(&http.ClientOpts{MaxRetryAfter: 2 * time.Second})
+
+		start := time.Now()
+		res, err := client.Do(mustNewRequest(t, nethttp.MethodGet, srv.URL+"?after=1", nil))
+		assert.NoError(t, err)
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, nethttp.StatusNoContent, res.StatusCode)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+		atomic.StoreInt32(&attempts, 0)
+		res, err = client.Do(mustNewRequest(t, nethttp.MethodGet, srv.URL+"?after=60", nil))
+		assert.NoError(t, err)
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, nethttp.StatusTooManyRequests, res.StatusCode)
+		assert.EqualValues(t, 1, attempts)
+	})
+
+	t.Run("per attempt timeout", func(t *testing.T) {
+		var attempts int32
+		srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				<-r.Context().Done()
+				return
+			}
+
+			w.WriteHeader(nethttp.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		res, err := newClient(&http.ClientOpts{Timeout: 50 * time.Millisecond}).Do(mustNewRequest(t, nethttp.MethodGet, srv.URL, nil))
+		assert.NoError(t, err)
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, nethttp.StatusNoContent, res.StatusCode)
+		assert.EqualValues(t, 2, attempts)
+	})
+
+	t.Run("caller context canceled", func(t *testing.T) {
+		srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			w.WriteHeader(nethttp.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+		defer cancel()
+
+		req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, srv.URL, nil)
+		assert.NoError(t, err)
+
+		_, err = http.NewClient(&http.ClientOpts{MinBackoff: time.Second, MaxBackoff: time.Second}).Do(req)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		var healthy atomic.Bool
+		var attempts int32
+		srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			atomic.AddInt32(&attempts, 1)
+			if !healthy.Load() {
+				w.WriteHeader(nethttp.StatusInternalServerError)
+				return
+			}
+
+			w.WriteHeader(nethttp.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		client := newClient(&http.ClientOpts{
+			MaxRetries: -1,
+			CircuitBreaker: &http.CircuitBreakerOpts{
+				FailureThreshold: 2,
+				OpenTimeout:      50 * time.Millisecond,
+			},
+		})
+
+		for i := 0; i < 2; i++ {
+			res, err := client.Do(mustNewRequest(t, nethttp.MethodGet, srv.URL, nil))
+			assert.NoError(t, err)
+			assert.NoError(t, res.Body.Close())
+		}
+
+		_, err := client.Do(mustNewRequest(t, nethttp.MethodGet, srv.URL, nil))
+		assert.ErrorIs(t, err, http.ErrCircuitOpen)
+		assert.EqualValues(t, 2, attempts)
+
+		time.Sleep(60 * time.Millisecond)
+		healthy.Store(true)
+
+		for i := 0; i < 2; i++ {
+			res, err := client.Do(mustNewRequest(t, nethttp.MethodGet, srv.URL, nil))
+			assert.NoError(t, err)
+			assert.NoError(t, res.Body.Close())
+			assert.Equal(t, nethttp.StatusNoContent, res.StatusCode)
+		}
+	})
+
+	t.Run("max response size", func(t *testing.T) {
+		srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			if r.URL.Query().Get("chunked") != "" {
+				w.(nethttp.Flusher).Flush()
+			}
+
+			_, _ = w.Write(bytes.Repeat([]byte("a"), 16))
+		}))
+		defer srv.Close()
+
+		_, err := newClient(&http.ClientOpts{MaxResponseSize: 8}).Do(mustNewRequest(t, nethttp.MethodGet, srv.URL, nil))
+		assert.ErrorIs(t, err, http.ErrResponseTooLarge)
+
+		res, err := newClient(&http.ClientOpts{MaxResponseSize: 8}).Do(mustNewRequest(t, nethttp.MethodGet, srv.URL+"?chunked=1", nil))
+		assert.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		assert.ErrorIs(t, err, http.ErrResponseTooLarge)
+		assert.Len(t, body, 8)
+		assert.NoError(t, res.Body.Close())
+
+		res, err = newClient(&http.ClientOpts{MaxResponseSize: 16}).Do(mustNewRequest(t, nethttp.MethodGet, srv.URL+"?chunked=1", nil))
+		assert.NoError(t, err)
+		body, err = io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		assert.Len(t, body, 16)
+		assert.NoError(t, res.Body.Close())
+	})
+
+	t.Run("forward request id", func(t *testing.T) {
+		var received string
+		srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			received = r.Header.Get(echo.HeaderXRequestID)
+			w.WriteHeader(nethttp.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		ctx := echomiddleware.ContextWithRequestID(context.TODO(), "request-id")
+		req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, srv.URL, nil)
+		assert.NoError(t, err)
+
+		res, err := newClient(&http.ClientOpts{}).Do(req)
+		assert.NoError(t, err)
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, "request-id", received)
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		srv := httptest.NewServer(nethttp.NotFoundHandler())
+		srv.Close()
+
+		_, err := newClient(&http.ClientOpts{MaxRetries: 1}).Do(mustNewRequest(t, nethttp.MethodGet, srv.URL, nil))
+		assert.Error(t, err)
+	})
+}
+
+func mustNewRequest(t *testing.T, method, url string, body io.Reader) *nethttp.Request {
+	req, err := nethttp.NewRequestWithContext(context.TODO(), method, url, body)
+	assert.NoError(t, err)
+
+	return req
+}
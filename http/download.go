@@ -0,0 +1,35 @@
+package http
+
+import (
+	"context"
+
+	"github.com/sweet-go/stdlib/helper"
+)
+
+// defaultDownloadClient is shared by every download, so the circuit breaker tracks the failing hosts across downloads.
+// The media size is limited by the downloader, so the response size limit is disabled
+var defaultDownloadClient = NewClient(&ClientOpts{
+	Timeout:         helper.DefaultDownloadTimeout,
+	MaxResponseSize: -1,
+})
+
+// DownloadMemeScrapingResultMedia downloads meme media from scraping result using the resilient Client,
+// retrying the failed download and short circuiting the failing host. See DownloadMemeScrapingResultMediaWithOpts
+func DownloadMemeScrapingResultMedia(ctx context.Context, url, outputPath, referer string) error {
+	return DownloadMemeScrapingResultMediaWithOpts(ctx, url, outputPath, referer, nil)
+}
+
+// DownloadMemeScrapingResultMediaWithOpts is the same as helper.DownloadMemeScrapingResultMediaWithOpts,
+// but DownloadOpts.Client if nil, will use the resilient Client with helper.DefaultDownloadTimeout per attempt
+func DownloadMemeScrapingResultMediaWithOpts(ctx context.Context, url, outputPath, referer string, opts *helper.DownloadOpts) error {
+	downloadOpts := helper.DownloadOpts{}
+	if opts != nil {
+		downloadOpts = *opts
+	}
+
+	if downloadOpts.Client == nil {
+		downloadOpts.Client = defaultDownloadClient
+	}
+
+	return helper.DownloadMemeScrapingResultMediaWithOpts(ctx, url, outputPath, referer, &downloadOpts)
+}
@@ -0,0 +1,40 @@
+package http_test
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/http"
+)
+
+func TestDownloadMemeScrapingResultMedia(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+
+	var calls int32
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(nethttp.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write(png)
+	}))
+	defer srv.Close()
+
+	t.Run("ok - retried by default", func(t *testing.T) {
+		dir := t.TempDir() + "/"
+
+		err := http.DownloadMemeScrapingResultMedia(context.TODO(), srv.URL+"/media", dir, "https://9gag.com")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+		files, err := filepath.Glob(dir + "*.png")
+		assert.NoError(t, err)
+		assert.Len(t, files, 1)
+	})
+}
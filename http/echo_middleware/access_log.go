@@ -0,0 +1,372 @@
+package echomiddleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/sweet-go/stdlib/tracing"
+)
+
+// RedactedValue is the value used to replace redacted headers and body fields
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactedHeaders is the default list of headers which value will be redacted from access log
+var DefaultRedactedHeaders = []string{
+	echo.HeaderAuthorization,
+	echo.HeaderCookie,
+	echo.HeaderSetCookie,
+	"X-Api-Key",
+}
+
+// DefaultRedactedFields is the default list of JSON body, form body and query string fields
+// which value will be redacted from access log
+var DefaultRedactedFields = []string{
+	"password",
+	"token",
+	"access_token",
+	"refresh_token",
+	"secret",
+	"api_key",
+}
+
+// AccessLogOpts is the options for AccessLog middleware
+type AccessLogOpts struct {
+	// Logger if nil, will use logrus standard logger
+	Logger *logrus.Logger
+
+	// Skipper if returning true, the request will not be logged. The request scoped logger is still set to context
+	Skipper func(c echo.Context) bool
+
+	CaptureRequestBody  bool
+	CaptureResponseBody bool
+
+	// MaxBodySize is the max captured body bytes. Default to 4096
+	MaxBodySize int
+
+	// RedactedHeaders if nil, will use DefaultRedactedHeaders
+	RedactedHeaders []string
+
+	// RedactedFields if nil, will use DefaultRedactedFields. Matched case insensitively on any depth of JSON body,
+	// on the form body and on the query string
+	RedactedFields []string
+
+	// UserIDExtractor if nil, will read `user_id` or `sub` claim from the JWT token set by echo-jwt middleware
+	UserIDExtractor func(c echo.Context) string
+}
+
+// AccessLog is a middleware to emit one structured log entry per request. Should be placed after RequestID middleware
+// so the request ID is available, and after Tracing middleware so the trace ID is logged. Also put request scoped logger
+// to the request context, retrievable using GetLoggerFromCtx. The error returned by the handler is handled
+// using echo.Context.Error to log the final status and is not returned, so it is handled only once
+func AccessLog(opts *AccessLogOpts) echo.MiddlewareFunc {
+	if opts == nil {
+		opts = &AccessLogOpts{}
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+
+	maxBodySize := opts.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = 4096
+	}
+
+	redactedHeaders := opts.RedactedHeaders
+	if redactedHeaders == nil {
+		redactedHeaders = DefaultRedactedHeaders
+	}
+
+	redactedFields := opts.RedactedFields
+	if redactedFields == nil {
+		redactedFields = DefaultRedactedFields
+	}
+
+	userIDExtractor := opts.UserIDExtractor
+	if userIDExtractor == nil {
+		userIDExtractor = UserIDFromJWT
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			entry := logrus.NewEntry(logger).WithField("request_id", GetRequestIDFromCtx(req.Context()))
+			if traceID := tracing.TraceIDFromContext(req.Context()); traceID != "" {
+				entry = entry.WithField("trace_id", traceID)
+			}
+
+			c.SetRequest(req.WithContext(setLoggerToContext(req.Context(), entry)))
+
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			var reqBody []byte
+			if opts.CaptureRequestBody && req.Body != nil {
+				reqBody, req.Body = peekBody(req.Body, maxBodySize)
+			}
+
+			var resBody *limitedBuffer
+			if opts.CaptureResponseBody {
+				resBody = &limitedBuffer{limit: maxBodySize}
+				c.Response().Writer = &bodyCaptureWriter{ResponseWriter: c.Response().Writer, buf: resBody}
+			}
+
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			res := c.Response()
+			fields := logrus.Fields{
+				"method":     req.Method,
+				"route":      c.Path(),
+				"uri":        redactURI(req.URL, redactedFields),
+				"status":     res.Status,
+				"latency_ms": time.Since(start).Milliseconds(),
+				"bytes_in":   req.ContentLength,
+				"bytes_out":  res.Size,
+				"client_ip":  c.RealIP(),
+				"user_agent": req.UserAgent(),
+				"headers":    RedactHeaders(req.Header, redactedHeaders),
+			}
+
+			if userID := userIDExtractor(c); userID != "" {
+				fields["user_id"] = userID
+			}
+
+			if opts.CaptureRequestBody {
+				fields["request_body"] = redactBody(reqBody, req.Header.Get(echo.HeaderContentType), redactedFields)
+			}
+
+			if resBody != nil {
+				fields["response_body"] = redactBody(resBody.Bytes(), res.Header().Get(echo.HeaderContentType), redactedFields)
+			}
+
+			logEntry := entry.WithFields(fields)
+			if err != nil {
+				logEntry = logEntry.WithError(err)
+			}
+
+			if res.Status >= http.StatusInternalServerError {
+				logEntry.Warn("access log")
+				return nil
+			}
+
+			logEntry.Info("access log")
+
+			return nil
+		}
+	}
+}
+
+// UserIDFromJWT reads the user ID from the JWT token set by echo-jwt middleware in context key `user`.
+// Will look for `user_id` then `sub` claim. Return empty string if not found
+func UserIDFromJWT(c echo.Context) string {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		return ""
+	}
+
+	switch claims := token.Claims.(type) {
+	case jwt.MapClaims:
+		if userID, ok := claims["user_id"].(string); ok && userID != "" {
+			return userID
+		}
+
+		sub, _ := claims["sub"].(string)
+		return sub
+	case *jwt.RegisteredClaims:
+		return claims.Subject
+	case jwt.RegisteredClaims:
+		return claims.Subject
+	}
+
+	return ""
+}
+
+// peekBody reads up to limit bytes from body and returns a new body containing the full original content
+func peekBody(body io.ReadCloser, limit int) ([]byte, io.ReadCloser) {
+	peeked, err := io.ReadAll(io.LimitReader(body, int64(limit)))
+	if err != nil {
+		logrus.Warn("access log: failed to read request body: ", err)
+	}
+
+	return peeked, &multiReadCloser{
+		Reader: io.MultiReader(bytes.NewReader(peeked), body),
+		closer: body,
+	}
+}
+
+type multiReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (mrc *multiReadCloser) Close() error {
+	return mrc.closer.Close()
+}
+
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (lb *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := lb.limit - lb.Len(); remaining > 0 {
+		if len(p) > remaining {
+			lb.Buffer.Write(p[:remaining])
+		} else {
+			lb.Buffer.Write(p)
+		}
+	}
+
+	return len(p), nil
+}
+
+type bodyCaptureWriter struct {
+	http.ResponseWriter
+	buf io.Writer
+}
+
+func (bcw *bodyCaptureWriter) Write(p []byte) (int, error) {
+	_, _ = bcw.buf.Write(p)
+	return bcw.ResponseWriter.Write(p)
+}
+
+func (bcw *bodyCaptureWriter) Flush() {
+	if flusher, ok := bcw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands the connection over, e.g. to the WebSocket handler. The hijacked connection is not captured
+func (bcw *bodyCaptureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := bcw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the wrapped writer, used by http.ResponseController
+func (bcw *bodyCaptureWriter) Unwrap() http.ResponseWriter {
+	return bcw.ResponseWriter
+}
+
+// RedactHeaders flattens the header values, replacing the value of redacted headers with RedactedValue
+func RedactHeaders(header http.Header, redacted []string) map[string]string {
+	res := make(map[string]string, len(header))
+	for k, v := range header {
+		res[k] = strings.Join(v, ",")
+	}
+
+	for _, k := range redacted {
+		k = http.CanonicalHeaderKey(k)
+		if _, ok := res[k]; ok {
+			res[k] = RedactedValue
+		}
+	}
+
+	return res
+}
+
+// redactBody redacts the JSON or form body fields. JSON body which can't be parsed (e.g. truncated) will be fully redacted
+// since the sensitive fields can't be located, while other content types are returned as is
+func redactBody(body []byte, contentType string, redacted []string) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	fields := make(map[string]bool, len(redacted))
+	for _, f := range redacted {
+		fields[strings.ToLower(f)] = true
+	}
+
+	switch {
+	case strings.Contains(contentType, "json"):
+		var decoded any
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			return RedactedValue
+		}
+
+		b, err := json.Marshal(redactValue(decoded, fields))
+		if err != nil {
+			return RedactedValue
+		}
+
+		return string(b)
+	case strings.HasPrefix(contentType, echo.MIMEApplicationForm):
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return RedactedValue
+		}
+
+		for k := range values {
+			if fields[strings.ToLower(k)] {
+				values.Set(k, RedactedValue)
+			}
+		}
+
+		return values.Encode()
+	}
+
+	return string(body)
+}
+
+// redactURI returns the path and the query string which redacted fields value is replaced with RedactedValue.
+// The query string which can't be parsed is fully redacted
+func redactURI(u *url.URL, redacted []string) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return u.Path + "?" + RedactedValue
+	}
+
+	for k := range values {
+		for _, f := range redacted {
+			if strings.EqualFold(k, f) {
+				values.Set(k, RedactedValue)
+				break
+			}
+		}
+	}
+
+	return u.Path + "?" + values.Encode()
+}
+
+func redactValue(v any, fields map[string]bool) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			if fields[strings.ToLower(k)] {
+				val[k] = RedactedValue
+				continue
+			}
+
+			val[k] = redactValue(child, fields)
+		}
+	case []any:
+		for i, child := range val {
+			val[i] = redactValue(child, fields)
+		}
+	}
+
+	return v
+}
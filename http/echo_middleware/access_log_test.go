@@ -0,0 +1,155 @@
+package echomiddleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoMiddleware_AccessLog(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	newEcho := func(opts *AccessLogOpts) *echo.Echo {
+		ec := echo.New()
+		ec.Use(RequestID(true), AccessLog(opts))
+		ec.POST("/users/:id", func(c echo.Context) error {
+			GetLoggerFromCtx(c.Request().Context()).Info("inside handler")
+			c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"sub": "user-1"}})
+
+			return c.JSON(http.StatusCreated, map[string]string{"token": "secret-token", "id": c.Param("id")})
+		})
+		ec.GET("/fail", func(c echo.Context) error {
+			return errors.New("boom")
+		})
+
+		return ec
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		hook.Reset()
+		ec := newEcho(&AccessLogOpts{
+			Logger:              logger,
+			CaptureRequestBody:  true,
+			CaptureResponseBody: true,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/users/10?x=1&API_KEY=k3y", strings.NewReader(`{"name":"a","password":"p4ss","nested":{"secret":"s"}}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer abc")
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Len(t, hook.Entries, 2)
+
+		handlerEntry := hook.Entries[0]
+		accessEntry := hook.Entries[1]
+		rid := rec.Header().Get(echo.HeaderXRequestID)
+
+		assert.Equal(t, rid, handlerEntry.Data["request_id"])
+		assert.Equal(t, rid, accessEntry.Data["request_id"])
+		assert.Equal(t, logrus.InfoLevel, accessEntry.Level)
+		assert.Equal(t, http.MethodPost, accessEntry.Data["method"])
+		assert.Equal(t, "/users/:id", accessEntry.Data["route"])
+		assert.Equal(t, "/users/10?API_KEY=%5BREDACTED%5D&x=1", accessEntry.Data["uri"])
+		assert.Equal(t, http.StatusCreated, accessEntry.Data["status"])
+		assert.Equal(t, "user-1", accessEntry.Data["user_id"])
+		assert.Equal(t, RedactedValue, accessEntry.Data["headers"].(map[string]string)[echo.HeaderAuthorization])
+		assert.Equal(t, `{"name":"a","nested":{"secret":"[REDACTED]"},"password":"[REDACTED]"}`, accessEntry.Data["request_body"])
+		assert.Equal(t, `{"id":"10","token":"[REDACTED]"}`, accessEntry.Data["response_body"])
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		hook.Reset()
+		ec := newEcho(&AccessLogOpts{Logger: logger})
+
+		handled := 0
+		errorHandler := ec.HTTPErrorHandler
+		ec.HTTPErrorHandler = func(err error, c echo.Context) {
+			handled++
+			errorHandler(err, c)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Len(t, hook.Entries, 1)
+		assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+		assert.Equal(t, http.StatusInternalServerError, hook.LastEntry().Data["status"])
+		assert.NotContains(t, hook.LastEntry().Data, "request_body")
+		assert.Equal(t, 1, handled)
+	})
+
+	t.Run("hijack with body capture", func(t *testing.T) {
+		ec := echo.New()
+		ec.Use(AccessLog(&AccessLogOpts{Logger: logger, CaptureResponseBody: true}))
+		ec.GET("/ws", func(c echo.Context) error {
+			conn, rw, err := c.Response().Hijack()
+			if err != nil {
+				return err
+			}
+
+			defer conn.Close()
+
+			_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n\r\n")
+			return rw.Flush()
+		})
+
+		srv := httptest.NewServer(ec)
+		defer srv.Close()
+
+		res, err := http.Get(srv.URL + "/ws")
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		hook.Reset()
+		ec := newEcho(&AccessLogOpts{
+			Logger:  logger,
+			Skipper: func(c echo.Context) bool { return true },
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/users/10", nil)
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Len(t, hook.Entries, 1)
+		assert.Equal(t, "inside handler", hook.LastEntry().Message)
+	})
+}
+
+func TestEchoMiddleware_redactBody(t *testing.T) {
+	fields := []string{"password"}
+
+	assert.Equal(t, "", redactBody(nil, echo.MIMEApplicationJSON, fields))
+	assert.Equal(t, RedactedValue, redactBody([]byte(`{"password":"trunc`), echo.MIMEApplicationJSON, fields))
+	assert.Equal(t, `[{"Password":"[REDACTED]"}]`, redactBody([]byte(`[{"Password":"x"}]`), echo.MIMEApplicationJSON, fields))
+	assert.Equal(t, "password=%5BREDACTED%5D&user=a", redactBody([]byte("user=a&password=x"), echo.MIMEApplicationForm, fields))
+	assert.Equal(t, "plain", redactBody([]byte("plain"), echo.MIMETextPlain, fields))
+}
+
+func TestEchoMiddleware_UserIDFromJWT(t *testing.T) {
+	ec := echo.New()
+	c := ec.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	assert.Equal(t, "", UserIDFromJWT(c))
+
+	c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": "user-1", "sub": "sub-1"}})
+	assert.Equal(t, "user-1", UserIDFromJWT(c))
+
+	c.Set("user", &jwt.Token{Claims: &jwt.RegisteredClaims{Subject: "sub-2"}})
+	assert.Equal(t, "sub-2", UserIDFromJWT(c))
+}
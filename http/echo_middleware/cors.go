@@ -0,0 +1,180 @@
+package echomiddleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultCORSAllowMethods is the default list of methods allowed by CORS middleware
+var DefaultCORSAllowMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// DefaultCORSAllowHeaders is the default list of request headers allowed by CORS middleware
+var DefaultCORSAllowHeaders = []string{
+	echo.HeaderContentType,
+	echo.HeaderAuthorization,
+	echo.HeaderXRequestID,
+	HeaderIdempotencyKey,
+	DefaultCSRFHeaderName,
+}
+
+// DefaultCORSExposeHeaders is the default list of response headers readable by the browser
+var DefaultCORSExposeHeaders = []string{
+	echo.HeaderXRequestID,
+	HeaderIdempotentReplayed,
+}
+
+// ErrCORSOriginNotAllowed is returned on preflight request from origin not in the allowlist
+var ErrCORSOriginNotAllowed = echo.NewHTTPError(http.StatusForbidden, "origin is not allowed")
+
+// CORSOpts is the options for CORS middleware
+type CORSOpts struct {
+	// AllowOrigins is the exact origins allowed, e.g. `https://admin.example.com`. A single leading wildcard
+	// subdomain is supported, e.g. `https://*.example.com`. The bare `*` is not allowed. Empty means no cross origin request is allowed
+	AllowOrigins []string
+
+	// AllowOriginsByEnv is the allowlist per environment, used instead of AllowOrigins when it has the Environment key
+	AllowOriginsByEnv map[string][]string
+
+	// Environment selects the allowlist from AllowOriginsByEnv, e.g. `production`
+	Environment string
+
+	// AllowMethods if nil, will use DefaultCORSAllowMethods
+	AllowMethods []string
+
+	// AllowHeaders if nil, will use DefaultCORSAllowHeaders
+	AllowHeaders []string
+
+	// ExposeHeaders if nil, will use DefaultCORSExposeHeaders
+	ExposeHeaders []string
+
+	AllowCredentials bool
+
+	// MaxAge is the preflight response cache duration in seconds. Default to 600
+	MaxAge int
+
+	// Skipper if returning true, no CORS header will be set
+	Skipper func(c echo.Context) bool
+}
+
+// CORS is a strict CORS middleware. Only the origins in the allowlist receive the CORS headers, and preflight request
+// from other origin is rejected with ErrCORSOriginNotAllowed. Will panic if the allowlist contains `*`
+func CORS(opts *CORSOpts) echo.MiddlewareFunc {
+	if opts == nil {
+		opts = &CORSOpts{}
+	}
+
+	origins := opts.AllowOrigins
+	if envOrigins, ok := opts.AllowOriginsByEnv[opts.Environment]; ok {
+		origins = envOrigins
+	}
+
+	for _, o := range origins {
+		if o == "*" {
+			panic("echo middleware: cors middleware does not allow wildcard origin")
+		}
+	}
+
+	allowMethods := opts.AllowMethods
+	if allowMethods == nil {
+		allowMethods = DefaultCORSAllowMethods
+	}
+
+	allowHeaders := opts.AllowHeaders
+	if allowHeaders == nil {
+		allowHeaders = DefaultCORSAllowHeaders
+	}
+
+	exposeHeaders := opts.ExposeHeaders
+	if exposeHeaders == nil {
+		exposeHeaders = DefaultCORSExposeHeaders
+	}
+
+	maxAge := opts.MaxAge
+	if maxAge <= 0 {
+		maxAge = 600
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			header := c.Response().Header()
+			origin := req.Header.Get(echo.HeaderOrigin)
+			preflight := req.Method == http.MethodOptions && req.Header.Get(echo.HeaderAccessControlRequestMethod) != ""
+
+			header.Add(echo.HeaderVary, echo.HeaderOrigin)
+			if origin == "" {
+				return next(c)
+			}
+
+			if !isOriginAllowed(origin, origins) {
+				if preflight {
+					return ErrCORSOriginNotAllowed
+				}
+
+				return next(c)
+			}
+
+			header.Set(echo.HeaderAccessControlAllowOrigin, origin)
+			if opts.AllowCredentials {
+				header.Set(echo.HeaderAccessControlAllowCredentials, "true")
+			}
+
+			if !preflight {
+				if len(exposeHeaders) > 0 {
+					header.Set(echo.HeaderAccessControlExposeHeaders, strings.Join(exposeHeaders, ","))
+				}
+
+				return next(c)
+			}
+
+			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+			header.Set(echo.HeaderAccessControlAllowMethods, strings.Join(allowMethods, ","))
+			header.Set(echo.HeaderAccessControlAllowHeaders, strings.Join(allowHeaders, ","))
+			header.Set(echo.HeaderAccessControlMaxAge, strconv.Itoa(maxAge))
+
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+}
+
+func isOriginAllowed(origin string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(origin, a) {
+			return true
+		}
+
+		scheme, host, ok := strings.Cut(a, "://*.")
+		if !ok {
+			continue
+		}
+
+		// the wildcard must match at least one subdomain label, so `https://example.com` doesn't match `https://*.example.com`
+		prefix := strings.ToLower(scheme + "://")
+		suffix := strings.ToLower("." + host)
+		lower := strings.ToLower(origin)
+		if !strings.HasPrefix(lower, prefix) || !strings.HasSuffix(lower, suffix) || len(lower) <= len(prefix)+len(suffix) {
+			continue
+		}
+
+		if subdomain := lower[len(prefix) : len(lower)-len(suffix)]; !strings.ContainsAny(subdomain, "/@:") {
+			return true
+		}
+	}
+
+	return false
+}
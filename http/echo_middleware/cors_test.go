@@ -0,0 +1,94 @@
+package echomiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoMiddleware_CORS(t *testing.T) {
+	ec := echo.New()
+	ec.Use(CORS(&CORSOpts{
+		AllowOrigins: []string{"https://example.com"},
+		AllowOriginsByEnv: map[string][]string{
+			"production": {"https://admin.example.com", "https://*.example.id"},
+		},
+		Environment:      "production",
+		AllowCredentials: true,
+	}))
+	ec.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	serve := func(method, origin string, preflight bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", nil)
+		if origin != "" {
+			req.Header.Set(echo.HeaderOrigin, origin)
+		}
+
+		if preflight {
+			req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodPost)
+		}
+
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("allowed origin", func(t *testing.T) {
+		rec := serve(http.MethodGet, "https://admin.example.com", false)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "https://admin.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Equal(t, "true", rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+		assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlExposeHeaders), echo.HeaderXRequestID)
+		assert.Equal(t, echo.HeaderOrigin, rec.Header().Get(echo.HeaderVary))
+	})
+
+	t.Run("environment allowlist is used", func(t *testing.T) {
+		rec := serve(http.MethodGet, "https://example.com", false)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+
+	t.Run("wildcard subdomain", func(t *testing.T) {
+		assert.Equal(t, "https://app.example.id", serve(http.MethodGet, "https://app.example.id", false).Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Empty(t, serve(http.MethodGet, "https://example.id", false).Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Empty(t, serve(http.MethodGet, "https://evil.com/.example.id", false).Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Empty(t, serve(http.MethodGet, "http://app.example.id", false).Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "https://admin.example.com", true)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlAllowMethods), http.MethodPost)
+		assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlAllowHeaders), HeaderIdempotencyKey)
+		assert.Equal(t, "600", rec.Header().Get(echo.HeaderAccessControlMaxAge))
+	})
+
+	t.Run("preflight from disallowed origin", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "https://evil.com", true)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+
+	t.Run("same origin request", func(t *testing.T) {
+		rec := serve(http.MethodGet, "", false)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+
+	t.Run("wildcard origin is not allowed", func(t *testing.T) {
+		assert.Panics(t, func() {
+			CORS(&CORSOpts{AllowOrigins: []string{"*"}})
+		})
+	})
+}
@@ -0,0 +1,165 @@
+package echomiddleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// list of default CSRF options
+const (
+	DefaultCSRFCookieName = "_csrf"
+	DefaultCSRFHeaderName = "X-CSRF-Token"
+	DefaultCSRFFormField  = "_csrf"
+)
+
+// CSRFContextKey is the echo context key of the CSRF token
+const CSRFContextKey = "github.com/sweet-go/stdlib:echo_middleware:CSRFToken"
+
+// list of errors returned by CSRF middleware
+var (
+	// ErrCSRFTokenMissing is returned when the unsafe request has no CSRF cookie or submitted token
+	ErrCSRFTokenMissing = echo.NewHTTPError(http.StatusForbidden, "missing csrf token")
+
+	// ErrCSRFTokenInvalid is returned when the submitted token doesn't match the CSRF cookie
+	ErrCSRFTokenInvalid = echo.NewHTTPError(http.StatusForbidden, "invalid csrf token")
+)
+
+// CSRFOpts is the options for CSRF middleware
+type CSRFOpts struct {
+	// CookieName default to DefaultCSRFCookieName
+	CookieName string
+
+	// HeaderName default to DefaultCSRFHeaderName
+	HeaderName string
+
+	// FormField default to DefaultCSRFFormField
+	FormField string
+
+	// TokenLength is the random bytes of the token. Default to 32
+	TokenLength int
+
+	// CookiePath default to `/`
+	CookiePath   string
+	CookieDomain string
+
+	// CookieMaxAge default to 12 hours
+	CookieMaxAge time.Duration
+
+	// CookieSameSite default to http.SameSiteStrictMode
+	CookieSameSite http.SameSite
+
+	// InsecureCookie allows the cookie to be sent over plain http. Should only be used for local development
+	InsecureCookie bool
+
+	// Skipper if returning true, the request is not protected
+	Skipper func(c echo.Context) bool
+}
+
+// CSRF is a double submit cookie CSRF protection middleware for server rendered pages.
+// Safe requests receive the token cookie, and the token is put to the echo context, retrievable using GetCSRFToken
+// to be rendered in the form field or meta tag. Unsafe requests must submit the same token through the header
+// or the form field, otherwise ErrCSRFTokenMissing or ErrCSRFTokenInvalid is returned
+func CSRF(opts *CSRFOpts) echo.MiddlewareFunc {
+	if opts == nil {
+		opts = &CSRFOpts{}
+	}
+
+	cookieName := opts.CookieName
+	if cookieName == "" {
+		cookieName = DefaultCSRFCookieName
+	}
+
+	headerName := opts.HeaderName
+	if headerName == "" {
+		headerName = DefaultCSRFHeaderName
+	}
+
+	formField := opts.FormField
+	if formField == "" {
+		formField = DefaultCSRFFormField
+	}
+
+	tokenLength := opts.TokenLength
+	if tokenLength <= 0 {
+		tokenLength = 32
+	}
+
+	cookiePath := opts.CookiePath
+	if cookiePath == "" {
+		cookiePath = "/"
+	}
+
+	cookieMaxAge := opts.CookieMaxAge
+	if cookieMaxAge <= 0 {
+		cookieMaxAge = 12 * time.Hour
+	}
+
+	sameSite := opts.CookieSameSite
+	if sameSite == 0 {
+		sameSite = http.SameSiteStrictMode
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			token := ""
+			if cookie, err := c.Cookie(cookieName); err == nil {
+				token = cookie.Value
+			}
+
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			default:
+				submitted := c.Request().Header.Get(headerName)
+				if submitted == "" {
+					submitted = c.FormValue(formField)
+				}
+
+				if token == "" || submitted == "" {
+					return ErrCSRFTokenMissing
+				}
+
+				if subtle.ConstantTimeCompare([]byte(token), []byte(submitted)) != 1 {
+					return ErrCSRFTokenInvalid
+				}
+			}
+
+			if token == "" {
+				var err error
+				token, err = generateRandomToken(tokenLength)
+				if err != nil {
+					return err
+				}
+			}
+
+			c.SetCookie(&http.Cookie{
+				Name:     cookieName,
+				Value:    token,
+				Path:     cookiePath,
+				Domain:   opts.CookieDomain,
+				Expires:  time.Now().Add(cookieMaxAge),
+				Secure:   !opts.InsecureCookie,
+				HttpOnly: true,
+				SameSite: sameSite,
+			})
+
+			c.Set(CSRFContextKey, token)
+			c.Response().Header().Add(echo.HeaderVary, echo.HeaderCookie)
+
+			return next(c)
+		}
+	}
+}
+
+// GetCSRFToken returns the CSRF token to be submitted by the rendered page.
+// Return empty string if CSRF middleware is not used
+func GetCSRFToken(c echo.Context) string {
+	token, _ := c.Get(CSRFContextKey).(string)
+	return token
+}
@@ -0,0 +1,84 @@
+package echomiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoMiddleware_CSRF(t *testing.T) {
+	ec := echo.New()
+	ec.Use(CSRF(nil))
+	ec.GET("/form", func(c echo.Context) error {
+		return c.String(http.StatusOK, GetCSRFToken(c))
+	})
+	ec.POST("/form", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	ec.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/form", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	token := rec.Body.String()
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, DefaultCSRFCookieName, cookies[0].Name)
+	assert.Equal(t, token, cookies[0].Value)
+	assert.True(t, cookies[0].Secure)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+
+	post := func(cookie, header, form string) *httptest.ResponseRecorder {
+		values := url.Values{}
+		if form != "" {
+			values.Set(DefaultCSRFFormField, form)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/form", strings.NewReader(values.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: DefaultCSRFCookieName, Value: cookie})
+		}
+
+		if header != "" {
+			req.Header.Set(DefaultCSRFHeaderName, header)
+		}
+
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("token in header", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, post(token, token, "").Code)
+	})
+
+	t.Run("token in form", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, post(token, "", token).Code)
+	})
+
+	t.Run("reuse cookie token on safe request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/form", nil)
+		req.AddCookie(&http.Cookie{Name: DefaultCSRFCookieName, Value: token})
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Equal(t, token, rec.Body.String())
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, post(token, "", "").Code)
+		assert.Equal(t, http.StatusForbidden, post("", token, "").Code)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, post(token, "forged", "").Code)
+	})
+}
@@ -0,0 +1,330 @@
+package echomiddleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/sweet-go/stdlib/cacher"
+)
+
+// list of idempotency headers
+const (
+	// HeaderIdempotencyKey is the request header carrying the client generated idempotency key
+	HeaderIdempotencyKey = "Idempotency-Key"
+
+	// HeaderIdempotentReplayed is set to `true` on the replayed response
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// list of idempotency record states
+const (
+	idempotencyStateInFlight  = "in_flight"
+	idempotencyStateCompleted = "completed"
+)
+
+// DefaultIdempotencyMethods is the default list of unsafe methods guarded by Idempotency middleware
+var DefaultIdempotencyMethods = []string{
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// list of errors returned by Idempotency middleware, handled by the echo HTTP error handler
+var (
+	// ErrIdempotencyKeyRequired is returned when Required is set and the request has no idempotency key
+	ErrIdempotencyKeyRequired = echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key header is required")
+
+	// ErrIdempotencyKeyInvalid is returned when the idempotency key is longer than MaxKeyLength
+	ErrIdempotencyKeyInvalid = echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key header is invalid")
+
+	// ErrIdempotencyKeyInFlight is returned when the original request using the same key is still being processed
+	ErrIdempotencyKeyInFlight = echo.NewHTTPError(http.StatusConflict, "request with the same Idempotency-Key is still in progress")
+
+	// ErrIdempotencyKeyReused is returned when the key is reused for a request with different method, path or body
+	ErrIdempotencyKeyReused = echo.NewHTTPError(http.StatusUnprocessableEntity, "Idempotency-Key is already used for a different request")
+)
+
+// IdempotencyOpts is the options for Idempotency middleware
+type IdempotencyOpts struct {
+	// Cacher is required
+	Cacher cacher.Cacher
+
+	// KeyPrefix is prepended to the cache key. Default to `idempotency:`
+	KeyPrefix string
+
+	// TTL is how long the completed response is kept to be replayed. Default to 24 hours
+	TTL time.Duration
+
+	// LockTTL is the max duration of the original request being considered in flight,
+	// after which the key can be used again. Default to 1 minute
+	LockTTL time.Duration
+
+	// Methods if nil, will use DefaultIdempotencyMethods
+	Methods []string
+
+	// Required rejects the guarded requests without the idempotency key
+	Required bool
+
+	// MaxKeyLength default to 255
+	MaxKeyLength int
+
+	// MaxBodySize is the max response body bytes to be stored. Bigger response will not be replayed. Default to 1 MiB
+	MaxBodySize int
+
+	// MaxRequestBodySize is the max request body bytes to be fingerprinted.
+	// Bigger request is rejected with 413 Request Entity Too Large. Default to 1 MiB
+	MaxRequestBodySize int64
+
+	// StoreTimeout is the timeout of storing the response and releasing the key. They are not cancelled
+	// when the client disconnects, so the retried request is replayed instead of executed again. Default to 5 seconds
+	StoreTimeout time.Duration
+
+	// ScopeExtractor scopes the key, so different users can't replay each other responses.
+	// If nil, will use UserIDFromJWT. When the scope is empty, e.g. the request has no JWT or the middleware is placed
+	// before the JWT middleware, the key is scoped by the client IP instead, see echo.Context.RealIP, so the anonymous
+	// clients don't share the same keys. Supply ScopeExtractor for the anonymous clients behind the same IP
+	ScopeExtractor func(c echo.Context) string
+
+	// Skipper if returning true, the request is not guarded
+	Skipper func(c echo.Context) bool
+}
+
+type idempotencyRecord struct {
+	State       string      `json:"state"`
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// idempotencyUnreplayedHeaders are response headers belonging to the replaying request instead of the original one
+var idempotencyUnreplayedHeaders = []string{
+	echo.HeaderXRequestID,
+	echo.HeaderSetCookie,
+}
+
+// Idempotency is a middleware to make unsafe requests carrying Idempotency-Key header safe to be retried.
+// The first response is stored using the cacher and replayed for the following requests using the same key,
+// while ErrIdempotencyKeyInFlight is returned when the original request is still in progress.
+// Reusing the key for different method, path or body returns ErrIdempotencyKeyReused.
+// Server error responses are not stored so the request can be retried. The error returned by the handler is handled
+// using echo.Context.Error to store the final response and is not returned, so it is handled only once.
+// Should be placed after the JWT middleware so the key is scoped per user, otherwise it is scoped by the client IP.
+// Will panic if the cacher is not supplied
+func Idempotency(opts *IdempotencyOpts) echo.MiddlewareFunc {
+	if opts == nil || opts.Cacher == nil {
+		panic("echo middleware: idempotency middleware requires cacher")
+	}
+
+	keyPrefix := opts.KeyPrefix
+	if keyPrefix == "" {
+		keyPrefix = "idempotency:"
+	}
+
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	lockTTL := opts.LockTTL
+	if lockTTL <= 0 {
+		lockTTL = time.Minute
+	}
+
+	methods := opts.Methods
+	if methods == nil {
+		methods = DefaultIdempotencyMethods
+	}
+
+	guarded := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		guarded[m] = true
+	}
+
+	maxKeyLength := opts.MaxKeyLength
+	if maxKeyLength <= 0 {
+		maxKeyLength = 255
+	}
+
+	maxBodySize := opts.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = 1 << 20
+	}
+
+	maxRequestBodySize := opts.MaxRequestBodySize
+	if maxRequestBodySize <= 0 {
+		maxRequestBodySize = 1 << 20
+	}
+
+	storeTimeout := opts.StoreTimeout
+	if storeTimeout <= 0 {
+		storeTimeout = 5 * time.Second
+	}
+
+	scopeExtractor := opts.ScopeExtractor
+	if scopeExtractor == nil {
+		scopeExtractor = UserIDFromJWT
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			req := c.Request()
+			if !guarded[req.Method] || (opts.Skipper != nil && opts.Skipper(c)) {
+				return next(c)
+			}
+
+			key := req.Header.Get(HeaderIdempotencyKey)
+			switch {
+			case key == "" && opts.Required:
+				return ErrIdempotencyKeyRequired
+			case key == "":
+				return next(c)
+			case len(key) > maxKeyLength:
+				return ErrIdempotencyKeyInvalid
+			}
+
+			fingerprint, err := requestFingerprint(c, maxRequestBodySize)
+			if err != nil {
+				return err
+			}
+
+			ctx := req.Context()
+			scope := scopeExtractor(c)
+			if scope == "" {
+				scope = "ip:" + c.RealIP()
+			}
+
+			cacheKey := keyPrefix + scope + ":" + key
+
+			lock, err := json.Marshal(&idempotencyRecord{State: idempotencyStateInFlight, Fingerprint: fingerprint})
+			if err != nil {
+				return err
+			}
+
+			acquired, err := opts.Cacher.SetNX(ctx, cacheKey, string(lock), lockTTL)
+			if err != nil {
+				return err
+			}
+
+			if !acquired {
+				return replayIdempotentResponse(c, opts.Cacher, cacheKey, fingerprint)
+			}
+
+			body := &limitedBuffer{limit: maxBodySize + 1}
+			c.Response().Writer = &bodyCaptureWriter{ResponseWriter: c.Response().Writer, buf: body}
+
+			if err = next(c); err != nil {
+				c.Error(err)
+			}
+
+			// detached from the request, so the disconnected client doesn't leave the key locked
+			storeCtx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+			defer cancel()
+
+			res := c.Response()
+			if res.Status >= http.StatusInternalServerError || body.Len() > maxBodySize || !res.Committed {
+				if delErr := opts.Cacher.Delete(storeCtx, cacheKey); delErr != nil {
+					GetLoggerFromCtx(ctx).WithError(delErr).Warn("idempotency: failed to release key")
+				}
+
+				return nil
+			}
+
+			storeIdempotentResponse(storeCtx, c, opts.Cacher, cacheKey, ttl, &idempotencyRecord{
+				State:       idempotencyStateCompleted,
+				Fingerprint: fingerprint,
+				Status:      res.Status,
+				Header:      res.Header().Clone(),
+				Body:        body.Bytes(),
+			})
+
+			return nil
+		}
+	}
+}
+
+// requestFingerprint hashes the method, path and body of the request. The body is restored to be read by the handler.
+// The body bigger than maxBodySize is rejected with 413 Request Entity Too Large
+func requestFingerprint(c echo.Context, maxBodySize int64) (string, error) {
+	req := c.Request()
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + "\n" + req.URL.RequestURI() + "\n"))
+
+	if req.Body != nil {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, maxBodySize))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return "", echo.ErrStatusRequestEntityTooLarge
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		hash.Write(body)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func replayIdempotentResponse(c echo.Context, cache cacher.Cacher, cacheKey, fingerprint string) error {
+	val, err := cache.Get(c.Request().Context(), cacheKey)
+	switch {
+	case errors.Is(err, redis.Nil):
+		// the original request has just finished with a non stored response, the client should retry
+		return ErrIdempotencyKeyInFlight
+	case err != nil:
+		return err
+	}
+
+	record := &idempotencyRecord{}
+	if err := json.Unmarshal([]byte(val), record); err != nil {
+		return err
+	}
+
+	if record.Fingerprint != fingerprint {
+		return ErrIdempotencyKeyReused
+	}
+
+	if record.State != idempotencyStateCompleted {
+		return ErrIdempotencyKeyInFlight
+	}
+
+	header := c.Response().Header()
+	for k, v := range record.Header {
+		header[k] = v
+	}
+
+	header.Set(HeaderIdempotentReplayed, "true")
+
+	return c.Blob(record.Status, record.Header.Get(echo.HeaderContentType), record.Body)
+}
+
+func storeIdempotentResponse(ctx context.Context, c echo.Context, cache cacher.Cacher, cacheKey string, ttl time.Duration, record *idempotencyRecord) {
+	for _, h := range idempotencyUnreplayedHeaders {
+		record.Header.Del(h)
+	}
+
+	logger := GetLoggerFromCtx(c.Request().Context())
+
+	val, err := json.Marshal(record)
+	if err != nil {
+		logger.WithError(err).Warn("idempotency: failed to encode response")
+		return
+	}
+
+	if err := cache.Set(ctx, cacheKey, string(val), ttl); err != nil {
+		logger.WithField("key", cacheKey).WithError(err).Warn("idempotency: failed to store response")
+	}
+}
@@ -0,0 +1,210 @@
+package echomiddleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/cacher"
+)
+
+func TestEchoMiddleware_Idempotency(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	defer mr.Close()
+
+	cache := cacher.NewCacher(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	executed := 0
+	disconnect := func() {}
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	ec := echo.New()
+	handled := 0
+	errorHandler := ec.HTTPErrorHandler
+	ec.HTTPErrorHandler = func(err error, c echo.Context) {
+		handled++
+		errorHandler(err, c)
+	}
+
+	ec.Use(RequestID(true), Idempotency(&IdempotencyOpts{
+		Cacher:  cache,
+		LockTTL: time.Minute,
+	}))
+	ec.POST("/charges", func(c echo.Context) error {
+		executed++
+		c.Response().Header().Set("X-Charge-ID", "charge-1")
+		return c.JSON(http.StatusCreated, map[string]int{"executed": executed})
+	})
+	ec.POST("/slow", func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.NoContent(http.StatusAccepted)
+	})
+	ec.POST("/fail", func(c echo.Context) error {
+		executed++
+		return echo.NewHTTPError(http.StatusServiceUnavailable)
+	})
+	ec.POST("/disconnect", func(c echo.Context) error {
+		executed++
+		disconnect()
+		return c.NoContent(http.StatusCreated)
+	})
+	ec.GET("/charges", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	serve := func(method, target, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("replay stored response", func(t *testing.T) {
+		executed = 0
+
+		first := serve(http.MethodPost, "/charges", "key-1", `{"amount":10}`)
+		assert.Equal(t, http.StatusCreated, first.Code)
+
+		second := serve(http.MethodPost, "/charges", "key-1", `{"amount":10}`)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "charge-1", second.Header().Get("X-Charge-ID"))
+		assert.Equal(t, "true", second.Header().Get(HeaderIdempotentReplayed))
+		assert.NotEqual(t, first.Header().Get(echo.HeaderXRequestID), second.Header().Get(echo.HeaderXRequestID))
+		assert.Empty(t, first.Header().Get(HeaderIdempotentReplayed))
+
+		assert.Equal(t, 1, executed)
+	})
+
+	t.Run("anonymous clients are scoped by ip", func(t *testing.T) {
+		executed = 0
+
+		for _, remoteAddr := range []string{"192.0.2.1:1234", "192.0.2.2:1234"} {
+			req := httptest.NewRequest(http.MethodPost, "/charges", strings.NewReader(`{"amount":10}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(HeaderIdempotencyKey, "key-anonymous")
+			req.RemoteAddr = remoteAddr
+
+			rec := httptest.NewRecorder()
+			ec.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Empty(t, rec.Header().Get(HeaderIdempotentReplayed))
+		}
+
+		assert.Equal(t, 2, executed)
+		assert.True(t, mr.Exists("idempotency:ip:192.0.2.2:key-anonymous"))
+	})
+
+	t.Run("reuse key for different request", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/charges", "key-1", `{"amount":20}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("in flight", func(t *testing.T) {
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			done <- serve(http.MethodPost, "/slow", "key-2", "")
+		}()
+
+		<-started
+		assert.Equal(t, http.StatusConflict, serve(http.MethodPost, "/slow", "key-2", "").Code)
+
+		close(release)
+		assert.Equal(t, http.StatusAccepted, (<-done).Code)
+		assert.Equal(t, http.StatusAccepted, serve(http.MethodPost, "/slow", "key-2", "").Code)
+	})
+
+	t.Run("server error is not stored", func(t *testing.T) {
+		executed = 0
+		handled = 0
+
+		assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPost, "/fail", "key-3", "").Code)
+		assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPost, "/fail", "key-3", "").Code)
+		assert.Equal(t, 2, executed)
+		assert.Equal(t, 2, handled)
+	})
+
+	t.Run("client disconnected", func(t *testing.T) {
+		executed = 0
+
+		ctx, cancel := context.WithCancel(context.Background())
+		disconnect = cancel
+
+		req := httptest.NewRequest(http.MethodPost, "/disconnect", nil).WithContext(ctx)
+		req.Header.Set(HeaderIdempotencyKey, "key-5")
+		ec.ServeHTTP(httptest.NewRecorder(), req)
+
+		rec := serve(http.MethodPost, "/disconnect", "key-5", "")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, 1, executed)
+	})
+
+	t.Run("request body too large", func(t *testing.T) {
+		ec := echo.New()
+		ec.Use(Idempotency(&IdempotencyOpts{Cacher: cache, MaxRequestBodySize: 8}))
+		ec.POST("/charges", func(c echo.Context) error {
+			return c.NoContent(http.StatusCreated)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/charges", strings.NewReader(`{"amount":10}`))
+		req.Header.Set(HeaderIdempotencyKey, "key-6")
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.False(t, mr.Exists("idempotency::key-6"))
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		executed = 0
+
+		serve(http.MethodPost, "/charges", "", `{"amount":10}`)
+		serve(http.MethodPost, "/charges", "", `{"amount":10}`)
+		assert.Equal(t, 2, executed)
+	})
+
+	t.Run("safe method is not guarded", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/charges", "key-4", "").Code)
+		assert.False(t, mr.Exists("idempotency::key-4"))
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/charges", strings.Repeat("a", 256), "").Code)
+	})
+
+	t.Run("required", func(t *testing.T) {
+		ec := echo.New()
+		ec.Use(Idempotency(&IdempotencyOpts{Cacher: cache, Required: true}))
+		ec.POST("/charges", func(c echo.Context) error {
+			return c.NoContent(http.StatusCreated)
+		})
+
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/charges", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("cacher is required", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Idempotency(&IdempotencyOpts{})
+		})
+	})
+}
@@ -0,0 +1,32 @@
+package echomiddleware
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LoggerCtxKeyType is the type for context key for request scoped logger
+type LoggerCtxKeyType string
+
+// LoggerCtxKey is the key for request scoped logger in context
+const LoggerCtxKey LoggerCtxKeyType = "github.com/sweet-go/stdlib:echo_middleware:LoggerCtxKey"
+
+// GetLoggerFromCtx returns the request scoped logger set by AccessLog middleware.
+// If not found, will return logrus standard logger entry with request_id field taken from the context
+func GetLoggerFromCtx(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(LoggerCtxKey).(*logrus.Entry); ok {
+		return entry
+	}
+
+	entry := logrus.NewEntry(logrus.StandardLogger())
+	if rid := GetRequestIDFromCtx(ctx); rid != "" {
+		entry = entry.WithField("request_id", rid)
+	}
+
+	return entry
+}
+
+func setLoggerToContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, LoggerCtxKey, entry)
+}
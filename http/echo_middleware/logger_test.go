@@ -0,0 +1,28 @@
+package echomiddleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoMiddleware_GetLoggerFromCtx(t *testing.T) {
+	t.Run("from context", func(t *testing.T) {
+		entry := logrus.WithField("request_id", "123")
+		ctx := setLoggerToContext(context.TODO(), entry)
+
+		assert.Equal(t, entry, GetLoggerFromCtx(ctx))
+	})
+
+	t.Run("fallback with request id", func(t *testing.T) {
+		ctx := ContextWithRequestID(context.TODO(), "123")
+
+		assert.Equal(t, "123", GetLoggerFromCtx(ctx).Data["request_id"])
+	})
+
+	t.Run("fallback without request id", func(t *testing.T) {
+		assert.NotContains(t, GetLoggerFromCtx(context.TODO()).Data, "request_id")
+	})
+}
@@ -0,0 +1,104 @@
+package echomiddleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sweet-go/stdlib/metrics"
+)
+
+// UnmatchedRoute is the route label value for requests not matching any registered route,
+// so the arbitrary request paths will not explode the metrics cardinality
+const UnmatchedRoute = "unmatched"
+
+// MetricsOpts is the options for Metrics middleware
+type MetricsOpts struct {
+	// Registerer if nil, will use prometheus.DefaultRegisterer
+	Registerer prometheus.Registerer
+
+	// Namespace and Subsystem are prepended to the metric names
+	Namespace string
+	Subsystem string
+
+	// Buckets is the request duration histogram buckets in seconds. Default to prometheus.DefBuckets
+	Buckets []float64
+
+	// Skipper if returning true, the request will not be recorded
+	Skipper func(c echo.Context) bool
+}
+
+// Metrics is a middleware to record the RED metrics for every request:
+// http_requests_total counter and http_request_duration_seconds histogram labeled by route template, method and status,
+// and http_requests_in_flight gauge labeled by route template and method.
+// The error returned by the handler is handled using echo.Context.Error to record the final status and is not returned,
+// so it is handled only once. Expose the metrics using MetricsHandler with the gatherer of the same registry
+func Metrics(opts *MetricsOpts) echo.MiddlewareFunc {
+	if opts == nil {
+		opts = &MetricsOpts{}
+	}
+
+	buckets := opts.Buckets
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+
+	requests := metrics.MustRegister(opts.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: opts.Namespace,
+		Subsystem: opts.Subsystem,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests processed.",
+	}, []string{"route", "method", "status"}))
+
+	duration := metrics.MustRegister(opts.Registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: opts.Namespace,
+		Subsystem: opts.Subsystem,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests in seconds.",
+		Buckets:   buckets,
+	}, []string{"route", "method", "status"}))
+
+	inFlight := metrics.MustRegister(opts.Registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: opts.Namespace,
+		Subsystem: opts.Subsystem,
+		Name:      "http_requests_in_flight",
+		Help:      "Number of HTTP requests currently being processed.",
+	}, []string{"route", "method"}))
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = UnmatchedRoute
+			}
+
+			method := c.Request().Method
+
+			gauge := inFlight.WithLabelValues(route, method)
+			gauge.Inc()
+			defer gauge.Dec()
+
+			start := time.Now()
+			if err = next(c); err != nil {
+				c.Error(err)
+			}
+
+			status := strconv.Itoa(c.Response().Status)
+			requests.WithLabelValues(route, method, status).Inc()
+			duration.WithLabelValues(route, method, status).Observe(time.Since(start).Seconds())
+
+			return nil
+		}
+	}
+}
+
+// MetricsHandler is echo handler exposing the metrics gathered by gatherer in Prometheus text format.
+// If gatherer is nil, will use prometheus.DefaultGatherer
+func MetricsHandler(gatherer prometheus.Gatherer) echo.HandlerFunc {
+	return echo.WrapHandler(metrics.Handler(gatherer))
+}
@@ -0,0 +1,87 @@
+package echomiddleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoMiddleware_Metrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	ec := echo.New()
+	handled := 0
+	errorHandler := ec.HTTPErrorHandler
+	ec.HTTPErrorHandler = func(err error, c echo.Context) {
+		handled++
+		errorHandler(err, c)
+	}
+
+	ec.Use(Metrics(&MetricsOpts{
+		Registerer: reg,
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == "/metrics"
+		},
+	}))
+	ec.GET("/users/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	ec.GET("/fail", func(c echo.Context) error {
+		return errors.New("boom")
+	})
+	ec.GET("/metrics", MetricsHandler(reg))
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		serve(http.MethodGet, "/users/1")
+		serve(http.MethodGet, "/users/2")
+		assert.Equal(t, http.StatusInternalServerError, serve(http.MethodGet, "/fail").Code)
+		assert.Equal(t, 1, handled)
+		serve(http.MethodGet, "/not/registered/123")
+
+		expected := `
+# HELP http_requests_total Total number of HTTP requests processed.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/fail",status="500"} 1
+http_requests_total{method="GET",route="/users/:id",status="204"} 2
+http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+		assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_requests_total"))
+		assert.Equal(t, 3, testutil.CollectAndCount(reg, "http_request_duration_seconds"))
+
+		expected = `
+# HELP http_requests_in_flight Number of HTTP requests currently being processed.
+# TYPE http_requests_in_flight gauge
+http_requests_in_flight{method="GET",route="/fail"} 0
+http_requests_in_flight{method="GET",route="/users/:id"} 0
+http_requests_in_flight{method="GET",route="unmatched"} 0
+`
+		assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_requests_in_flight"))
+	})
+
+	t.Run("expose metrics", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/metrics")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `http_requests_total{method="GET",route="/users/:id",status="204"} 2`)
+		assert.NotContains(t, rec.Body.String(), `route="/metrics"`)
+	})
+
+	t.Run("registering twice reuse the collectors", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			Metrics(&MetricsOpts{Registerer: reg})
+		})
+	})
+}
@@ -5,33 +5,103 @@ import (
 	"context"
 
 	"github.com/labstack/echo/v4"
-	"github.com/sweet-go/stdlib/helper"
+	"github.com/sweet-go/stdlib/requestid"
 )
 
 // ReqIDCtxKeyType is the type for context key for request ID
-type ReqIDCtxKeyType string
+type ReqIDCtxKeyType = requestid.CtxKeyType
 
 // ReqIDCtxKey is the key for request ID in context
-const ReqIDCtxKey ReqIDCtxKeyType = "github.com/sweet-go/stdlib:echo_middleware:ReqIDCtxKey"
+const ReqIDCtxKey = requestid.CtxKey
+
+// HeaderTraceParent is the W3C trace context header
+const HeaderTraceParent = "traceparent"
+
+// RequestIDOpts is the options for RequestIDWithOpts middleware
+type RequestIDOpts struct {
+	// Generator if nil, will use UUIDGenerator
+	Generator RequestIDGenerator
+
+	// Header is the request and response header carrying the request ID. Default to X-Request-ID
+	Header string
+
+	// TrustIncoming will use the incoming request ID header if valid, otherwise a new ID is always generated
+	TrustIncoming bool
+
+	// MaxLength is the max length of valid incoming request ID. Default to DefaultRequestIDMaxLength
+	MaxLength int
+
+	// Validator if nil, will use IsValidRequestID with MaxLength
+	Validator func(id string) bool
+
+	// UseTraceParent will use the trace ID of incoming W3C traceparent header as the request ID
+	// when no valid incoming request ID is used
+	UseTraceParent bool
+}
 
 // RequestID is a middleware to generate request ID and set it to context
 // if in the request header already have request ID in key `X-Request-ID` and `overrideHeader` is true,
 // then the request ID will be override with new generated ID
 // otherwise, both on context and response header will use supplied request header `X-Request-ID`
+// as long as it is valid according to IsValidRequestID, see RequestIDWithOpts for more options
 func RequestID(overrideHeader bool) echo.MiddlewareFunc {
+	return RequestIDWithOpts(&RequestIDOpts{
+		TrustIncoming: !overrideHeader,
+	})
+}
+
+// RequestIDWithOpts is a middleware to generate request ID and set it to context and response header.
+// Invalid incoming request ID will be replaced by a freshly generated ID
+func RequestIDWithOpts(opts *RequestIDOpts) echo.MiddlewareFunc {
+	if opts == nil {
+		opts = &RequestIDOpts{}
+	}
+
+	generator := opts.Generator
+	if generator == nil {
+		generator = UUIDGenerator
+	}
+
+	header := opts.Header
+	if header == "" {
+		header = echo.HeaderXRequestID
+	}
+
+	maxLength := opts.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultRequestIDMaxLength
+	}
+
+	validator := opts.Validator
+	if validator == nil {
+		validator = func(id string) bool {
+			return IsValidRequestID(id, maxLength)
+		}
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			req := c.Request()
 			res := c.Response()
-			rid := req.Header.Get(echo.HeaderXRequestID)
 
-			if overrideHeader {
-				rid = helper.GenerateID()
+			rid := ""
+			if opts.TrustIncoming {
+				if incoming := req.Header.Get(header); validator(incoming) {
+					rid = incoming
+				}
 			}
 
-			ctx := setRequestIDToContext(c.Request().Context(), rid)
-			c.SetRequest(c.Request().WithContext(ctx))
-			res.Header().Set(echo.HeaderXRequestID, rid)
+			if rid == "" && opts.UseTraceParent {
+				rid, _ = ParseTraceParent(req.Header.Get(HeaderTraceParent))
+			}
+
+			if rid == "" {
+				rid = generator()
+			}
+
+			ctx := ContextWithRequestID(req.Context(), rid)
+			c.SetRequest(req.WithContext(ctx))
+			res.Header().Set(header, rid)
 
 			return next(c)
 		}
@@ -40,15 +110,11 @@ func RequestID(overrideHeader bool) echo.MiddlewareFunc {
 
 // GetRequestIDFromCtx is a helper function to get request ID from context
 func GetRequestIDFromCtx(ctx context.Context) string {
-	id, ok := ctx.Value(ReqIDCtxKey).(string)
-	if !ok {
-		return ""
-	}
-
-	return id
+	return requestid.FromContext(ctx)
 }
 
-// can only set request ID from this middleware. Other can only read
-func setRequestIDToContext(ctx context.Context, id string) context.Context {
-	return context.WithValue(ctx, ReqIDCtxKey, id)
+// ContextWithRequestID returns a copy of ctx carrying the request ID. Should only be used to re-hydrate
+// a request ID propagated from other process, e.g. worker task metadata. Inside echo, use RequestID middleware instead
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return requestid.NewContext(ctx, id)
 }
@@ -0,0 +1,117 @@
+package echomiddleware
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid"
+	"github.com/sweet-go/stdlib/helper"
+)
+
+// RequestIDGenerator generates a new request ID
+type RequestIDGenerator func() string
+
+// UUIDGenerator generates random UUID v4 without "-". This is the default generator
+func UUIDGenerator() string {
+	return helper.GenerateID()
+}
+
+// UUIDv7Generator generates time ordered UUID v7 in canonical format
+func UUIDv7Generator() string {
+	var id uuid.UUID
+	if _, err := rand.Read(id[6:]); err != nil {
+		return UUIDGenerator()
+	}
+
+	ms := uint64(time.Now().UnixMilli())
+	id[0] = byte(ms >> 40)
+	id[1] = byte(ms >> 32)
+	id[2] = byte(ms >> 24)
+	id[3] = byte(ms >> 16)
+	id[4] = byte(ms >> 8)
+	id[5] = byte(ms)
+	id[6] = (id[6] & 0x0f) | 0x70
+	id[8] = (id[8] & 0x3f) | 0x80
+
+	return id.String()
+}
+
+// ULIDGenerator generates time ordered ULID
+func ULIDGenerator() string {
+	id, err := ulid.New(ulid.Timestamp(time.Now()), rand.Reader)
+	if err != nil {
+		return UUIDGenerator()
+	}
+
+	return id.String()
+}
+
+const (
+	ksuidEpoch       = 1400000000
+	ksuidPayloadLen  = 16
+	ksuidEncodedLen  = 27
+	base62Characters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// KSUIDGenerator generates time ordered K-Sortable Unique ID: 4 bytes timestamp since KSUID epoch
+// and 16 random bytes, encoded as 27 characters base62
+func KSUIDGenerator() string {
+	raw := make([]byte, 4+ksuidPayloadLen)
+	binary.BigEndian.PutUint32(raw, uint32(time.Now().Unix()-ksuidEpoch))
+	if _, err := rand.Read(raw[4:]); err != nil {
+		return UUIDGenerator()
+	}
+
+	n := new(big.Int).SetBytes(raw)
+	base := big.NewInt(int64(len(base62Characters)))
+	mod := new(big.Int)
+	encoded := make([]byte, ksuidEncodedLen)
+	for i := ksuidEncodedLen - 1; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		encoded[i] = base62Characters[mod.Int64()]
+	}
+
+	return string(encoded)
+}
+
+var requestIDCharset = regexp.MustCompile(`^[A-Za-z0-9\-_.:]+$`)
+
+// DefaultRequestIDMaxLength is the default max length of incoming request ID
+const DefaultRequestIDMaxLength = 128
+
+// IsValidRequestID checks the request ID is not empty, not longer than maxLength
+// and only contains alphanumeric, "-", "_", "." and ":" characters
+func IsValidRequestID(id string, maxLength int) bool {
+	return id != "" && len(id) <= maxLength && requestIDCharset.MatchString(id)
+}
+
+// ParseTraceParent returns the trace ID of W3C traceparent header value.
+// Return false if the value is not a valid traceparent
+func ParseTraceParent(traceparent string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return "", false
+	}
+
+	if parts[0] == "00" && len(parts) != 4 {
+		return "", false
+	}
+
+	for _, part := range parts[:4] {
+		if _, err := hex.DecodeString(part); err != nil || strings.ToLower(part) != part {
+			return "", false
+		}
+	}
+
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", false
+	}
+
+	return parts[1], true
+}
@@ -0,0 +1,58 @@
+package echomiddleware
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoMiddleware_RequestIDGenerator(t *testing.T) {
+	t.Run("uuid", func(t *testing.T) {
+		id := UUIDGenerator()
+		assert.Len(t, id, 32)
+		assert.NotEqual(t, id, UUIDGenerator())
+	})
+
+	t.Run("uuid v7", func(t *testing.T) {
+		id, err := uuid.Parse(UUIDv7Generator())
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), id.Version())
+		assert.Equal(t, uuid.RFC4122, id.Variant())
+	})
+
+	t.Run("ulid", func(t *testing.T) {
+		_, err := ulid.Parse(ULIDGenerator())
+		assert.NoError(t, err)
+	})
+
+	t.Run("ksuid", func(t *testing.T) {
+		id := KSUIDGenerator()
+		assert.Len(t, id, 27)
+		assert.True(t, IsValidRequestID(id, DefaultRequestIDMaxLength))
+		assert.NotEqual(t, id, KSUIDGenerator())
+	})
+}
+
+func TestEchoMiddleware_ParseTraceParent(t *testing.T) {
+	cases := map[string]bool{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":        true,
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future": true,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra":  false,
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":        false,
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01":        false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01":        false,
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01":        false,
+		"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01":        false,
+		"garbage": false,
+	}
+
+	for traceparent, valid := range cases {
+		traceID, ok := ParseTraceParent(traceparent)
+		assert.Equal(t, valid, ok, traceparent)
+		if valid {
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/labstack/echo/v4"
@@ -59,9 +60,73 @@ func TestEchoMiddleware_RequestID(t *testing.T) {
 	})
 }
 
+func TestEchoMiddleware_RequestIDWithOpts(t *testing.T) {
+	serve := func(opts *RequestIDOpts, header http.Header) (ctxID string, rec *httptest.ResponseRecorder) {
+		ec := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		rec = httptest.NewRecorder()
+		h := RequestIDWithOpts(opts)(func(c echo.Context) error {
+			ctxID = GetRequestIDFromCtx(c.Request().Context())
+			return c.NoContent(http.StatusOK)
+		})
+
+		assert.NoError(t, h(ec.NewContext(req, rec)))
+
+		return ctxID, rec
+	}
+
+	t.Run("invalid incoming id replaced", func(t *testing.T) {
+		for _, incoming := range []string{"", "has space", "<script>", strings.Repeat("a", DefaultRequestIDMaxLength+1)} {
+			id, rec := serve(&RequestIDOpts{TrustIncoming: true}, http.Header{echo.HeaderXRequestID: {incoming}})
+
+			assert.NotEqual(t, incoming, id)
+			assert.True(t, IsValidRequestID(id, DefaultRequestIDMaxLength))
+			assert.Equal(t, id, rec.Header().Get(echo.HeaderXRequestID))
+		}
+	})
+
+	t.Run("custom header, generator and max length", func(t *testing.T) {
+		opts := &RequestIDOpts{
+			Generator:     func() string { return "generated" },
+			Header:        "X-Correlation-ID",
+			TrustIncoming: true,
+			MaxLength:     5,
+		}
+
+		id, rec := serve(opts, http.Header{"X-Correlation-Id": {"abc"}})
+		assert.Equal(t, "abc", id)
+		assert.Equal(t, "abc", rec.Header().Get("X-Correlation-ID"))
+
+		id, _ = serve(opts, http.Header{"X-Correlation-Id": {"abcdef"}})
+		assert.Equal(t, "generated", id)
+	})
+
+	t.Run("custom validator", func(t *testing.T) {
+		id, _ := serve(&RequestIDOpts{
+			TrustIncoming: true,
+			Validator:     func(id string) bool { return strings.HasPrefix(id, "req_") },
+		}, http.Header{echo.HeaderXRequestID: {"req_1"}})
+		assert.Equal(t, "req_1", id)
+	})
+
+	t.Run("trace parent", func(t *testing.T) {
+		traceparent := http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}
+
+		id, _ := serve(&RequestIDOpts{UseTraceParent: true}, traceparent)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", id)
+
+		id, _ = serve(&RequestIDOpts{}, traceparent)
+		assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", id)
+	})
+}
+
 func TestEchoMiddleware_GetRequestIDFromContext(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
-		ctx := setRequestIDToContext(context.TODO(), "123")
+		ctx := ContextWithRequestID(context.TODO(), "123")
 
 		id := GetRequestIDFromCtx(ctx)
 		assert.Equal(t, id, "123")
@@ -0,0 +1,139 @@
+package echomiddleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CSPNonceContextKey is the echo context key of the per request CSP nonce
+const CSPNonceContextKey = "github.com/sweet-go/stdlib:echo_middleware:CSPNonce"
+
+// CSPNoncePlaceholder is replaced by the per request nonce in the Content-Security-Policy
+const CSPNoncePlaceholder = "{nonce}"
+
+// DefaultContentSecurityPolicy only allows the same origin resources, and the inline scripts and styles
+// carrying the per request nonce
+const DefaultContentSecurityPolicy = "default-src 'self'; script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'; " +
+	"object-src 'none'; base-uri 'self'; form-action 'self'; frame-ancestors 'none'"
+
+// SecureHeadersOpts is the options for SecureHeaders middleware
+type SecureHeadersOpts struct {
+	// HSTSMaxAge is the Strict-Transport-Security max age in seconds. Default to 1 year.
+	// Only sent on https request, including the one terminated by the proxy setting X-Forwarded-Proto
+	HSTSMaxAge int
+
+	DisableHSTS           bool
+	HSTSExcludeSubdomains bool
+	HSTSPreload           bool
+
+	// ContentSecurityPolicy if empty, will use DefaultContentSecurityPolicy. Every CSPNoncePlaceholder
+	// will be replaced by the per request nonce, retrievable using GetCSPNonce
+	ContentSecurityPolicy string
+
+	// CSPReportOnly sends the policy using Content-Security-Policy-Report-Only header, to try the policy out
+	CSPReportOnly bool
+
+	// ReferrerPolicy default to `strict-origin-when-cross-origin`
+	ReferrerPolicy string
+
+	// FrameOptions default to `DENY`
+	FrameOptions string
+
+	// Skipper if returning true, no security header will be set
+	Skipper func(c echo.Context) bool
+}
+
+// SecureHeaders is a middleware setting the security headers: Strict-Transport-Security, Content-Security-Policy
+// with per request nonce, X-Content-Type-Options, Referrer-Policy and X-Frame-Options
+func SecureHeaders(opts *SecureHeadersOpts) echo.MiddlewareFunc {
+	if opts == nil {
+		opts = &SecureHeadersOpts{}
+	}
+
+	hstsMaxAge := opts.HSTSMaxAge
+	if hstsMaxAge <= 0 {
+		hstsMaxAge = 365 * 24 * 60 * 60
+	}
+
+	hsts := fmt.Sprintf("max-age=%d", hstsMaxAge)
+	if !opts.HSTSExcludeSubdomains {
+		hsts += "; includeSubDomains"
+	}
+
+	if opts.HSTSPreload {
+		hsts += "; preload"
+	}
+
+	csp := opts.ContentSecurityPolicy
+	if csp == "" {
+		csp = DefaultContentSecurityPolicy
+	}
+
+	cspHeader := echo.HeaderContentSecurityPolicy
+	if opts.CSPReportOnly {
+		cspHeader = echo.HeaderContentSecurityPolicyReportOnly
+	}
+
+	referrerPolicy := opts.ReferrerPolicy
+	if referrerPolicy == "" {
+		referrerPolicy = "strict-origin-when-cross-origin"
+	}
+
+	frameOptions := opts.FrameOptions
+	if frameOptions == "" {
+		frameOptions = "DENY"
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+			header.Set(echo.HeaderReferrerPolicy, referrerPolicy)
+			header.Set(echo.HeaderXFrameOptions, frameOptions)
+
+			if !opts.DisableHSTS && c.Scheme() == "https" {
+				header.Set(echo.HeaderStrictTransportSecurity, hsts)
+			}
+
+			policy := csp
+			if strings.Contains(csp, CSPNoncePlaceholder) {
+				nonce, err := generateRandomToken(16)
+				if err != nil {
+					return err
+				}
+
+				c.Set(CSPNonceContextKey, nonce)
+				policy = strings.ReplaceAll(csp, CSPNoncePlaceholder, nonce)
+			}
+
+			header.Set(cspHeader, policy)
+
+			return next(c)
+		}
+	}
+}
+
+// GetCSPNonce returns the per request CSP nonce to be put in the nonce attribute of inline script and style tags.
+// Return empty string if SecureHeaders middleware is not used
+func GetCSPNonce(c echo.Context) string {
+	nonce, _ := c.Get(CSPNonceContextKey).(string)
+	return nonce
+}
+
+// generateRandomToken returns url safe base64 encoded n random bytes
+func generateRandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
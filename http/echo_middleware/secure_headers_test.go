@@ -0,0 +1,77 @@
+package echomiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoMiddleware_SecureHeaders(t *testing.T) {
+	var nonces []string
+
+	newEcho := func(opts *SecureHeadersOpts) *echo.Echo {
+		ec := echo.New()
+		ec.Use(SecureHeaders(opts))
+		ec.GET("/", func(c echo.Context) error {
+			nonces = append(nonces, GetCSPNonce(c))
+			return c.NoContent(http.StatusOK)
+		})
+
+		return ec
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		nonces = nil
+		ec := newEcho(nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderXForwardedProto, "https")
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+		assert.Equal(t, "strict-origin-when-cross-origin", rec.Header().Get(echo.HeaderReferrerPolicy))
+		assert.Equal(t, "DENY", rec.Header().Get(echo.HeaderXFrameOptions))
+		assert.Equal(t, "max-age=31536000; includeSubDomains", rec.Header().Get(echo.HeaderStrictTransportSecurity))
+
+		assert.Len(t, nonces, 1)
+		assert.NotEmpty(t, nonces[0])
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentSecurityPolicy), "script-src 'self' 'nonce-"+nonces[0]+"'")
+		assert.NotContains(t, rec.Header().Get(echo.HeaderContentSecurityPolicy), CSPNoncePlaceholder)
+
+		ec.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Len(t, nonces, 2)
+		assert.NotEqual(t, nonces[0], nonces[1])
+	})
+
+	t.Run("no hsts on plain http", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newEcho(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Empty(t, rec.Header().Get(echo.HeaderStrictTransportSecurity))
+	})
+
+	t.Run("custom policy", func(t *testing.T) {
+		nonces = nil
+		ec := newEcho(&SecureHeadersOpts{
+			ContentSecurityPolicy: "default-src 'none'",
+			CSPReportOnly:         true,
+			HSTSPreload:           true,
+			FrameOptions:          "SAMEORIGIN",
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderXForwardedProto, "https")
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentSecurityPolicy))
+		assert.Equal(t, "default-src 'none'", rec.Header().Get(echo.HeaderContentSecurityPolicyReportOnly))
+		assert.Equal(t, "max-age=31536000; includeSubDomains; preload", rec.Header().Get(echo.HeaderStrictTransportSecurity))
+		assert.Equal(t, "SAMEORIGIN", rec.Header().Get(echo.HeaderXFrameOptions))
+		assert.Equal(t, []string{""}, nonces)
+	})
+}
@@ -0,0 +1,42 @@
+package echomiddleware
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// SecurityOpts is the options for Security middleware bundle
+type SecurityOpts struct {
+	// CORS if nil, no cross origin request is allowed
+	CORS *CORSOpts
+
+	// SecureHeaders if nil, will use the secure default headers
+	SecureHeaders *SecureHeadersOpts
+
+	// CSRF if nil, CSRF protection is disabled. Should be enabled for the cookie authenticated server rendered pages
+	CSRF *CSRFOpts
+}
+
+// Security is a middleware bundle chaining CORS, SecureHeaders and CSRF middlewares in order,
+// so the preflight request is answered before reaching the CSRF protection
+func Security(opts *SecurityOpts) echo.MiddlewareFunc {
+	if opts == nil {
+		opts = &SecurityOpts{}
+	}
+
+	middlewares := []echo.MiddlewareFunc{
+		CORS(opts.CORS),
+		SecureHeaders(opts.SecureHeaders),
+	}
+
+	if opts.CSRF != nil {
+		middlewares = append(middlewares, CSRF(opts.CSRF))
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+
+		return next
+	}
+}
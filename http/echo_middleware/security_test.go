@@ -0,0 +1,54 @@
+package echomiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoMiddleware_Security(t *testing.T) {
+	ec := echo.New()
+	ec.Use(Security(&SecurityOpts{
+		CORS: &CORSOpts{AllowOrigins: []string{"https://admin.example.com"}},
+		CSRF: &CSRFOpts{},
+	}))
+	ec.POST("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	t.Run("preflight is answered before csrf", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set(echo.HeaderOrigin, "https://admin.example.com")
+		req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodPost)
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "https://admin.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+
+	t.Run("unsafe request without csrf token", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+	})
+
+	t.Run("csrf is disabled by default", func(t *testing.T) {
+		ec := echo.New()
+		ec.Use(Security(nil))
+		ec.POST("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusNoContent)
+		})
+
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get(echo.HeaderContentSecurityPolicy))
+	})
+}
@@ -0,0 +1,80 @@
+package echomiddleware
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingOpts is the options for Tracing middleware
+type TracingOpts struct {
+	// TracerProvider if nil, will use the OpenTelemetry global tracer provider
+	TracerProvider trace.TracerProvider
+
+	// Skipper if returning true, no span will be created for the request
+	Skipper func(c echo.Context) bool
+
+	// ServerName is the logical server name recorded as net.host.name. Default to the request host
+	ServerName string
+}
+
+// Tracing is a middleware to create a server span for every request. The W3C trace context found in the request headers
+// is used as the parent, and the span is put to the request context so the downstream calls will be its children.
+// The error returned by the handler is handled using echo.Context.Error to record the final status and is not returned,
+// so it is handled only once. Should be placed after RequestID middleware so the request ID is recorded
+func Tracing(opts *TracingOpts) echo.MiddlewareFunc {
+	if opts == nil {
+		opts = &TracingOpts{}
+	}
+
+	tracer := tracing.Tracer(opts.TracerProvider)
+	propagator := tracing.Propagator()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			ctx := propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := c.Path()
+			spanName := fmt.Sprintf("HTTP %s", req.Method)
+			if route != "" {
+				spanName = fmt.Sprintf("%s %s", req.Method, route)
+			}
+
+			attrs := httpconv.ServerRequest(opts.ServerName, req)
+			if route != "" {
+				attrs = append(attrs, semconv.HTTPRoute(route))
+			}
+
+			if rid := GetRequestIDFromCtx(ctx); rid != "" {
+				attrs = append(attrs, attribute.String("request_id", rid))
+			}
+
+			ctx, span := tracer.Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+			defer span.End()
+
+			c.SetRequest(req.WithContext(ctx))
+
+			if err = next(c); err != nil {
+				span.RecordError(err)
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			span.SetAttributes(semconv.HTTPStatusCode(status))
+			span.SetStatus(httpconv.ServerStatus(status))
+
+			return nil
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package echomiddleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestEchoMiddleware_Tracing(t *testing.T) {
+	tp, exporter := tracing.NewInMemoryTracerProvider("test-service")
+
+	ec := echo.New()
+	handled := 0
+	errorHandler := ec.HTTPErrorHandler
+	ec.HTTPErrorHandler = func(err error, c echo.Context) {
+		handled++
+		errorHandler(err, c)
+	}
+
+	ec.Use(RequestID(true), Tracing(&TracingOpts{
+		TracerProvider: tp,
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == "/healthz"
+		},
+	}))
+	ec.GET("/users/:id", func(c echo.Context) error {
+		assert.True(t, trace.SpanContextFromContext(c.Request().Context()).IsValid())
+		return c.NoContent(http.StatusNoContent)
+	})
+	ec.GET("/fail", func(c echo.Context) error {
+		return errors.New("boom")
+	})
+	ec.GET("/healthz", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		exporter.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, "/users/10", nil)
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "GET /users/:id", spans[0].Name)
+		assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+		assert.False(t, spans[0].Parent.IsValid())
+	})
+
+	t.Run("continue incoming trace", func(t *testing.T) {
+		exporter.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, "/users/10", nil)
+		req.Header.Set(HeaderTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+		assert.True(t, spans[0].Parent.IsRemote())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		exporter.Reset()
+		handled = 0
+
+		req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, 1, handled)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Len(t, spans[0].Events, 1)
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		exporter.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, exporter.GetSpans())
+	})
+}
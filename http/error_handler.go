@@ -0,0 +1,164 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	custerr "github.com/sweet-go/stdlib/error"
+	"github.com/sweet-go/stdlib/helper"
+	echomiddleware "github.com/sweet-go/stdlib/http/echo_middleware"
+)
+
+// HTTPErrorHandlerOpts is the options for NewHTTPErrorHandler
+type HTTPErrorHandlerOpts struct {
+	// ResponseGenerator if not nil, the error response will be signed using this generator
+	ResponseGenerator APIResponseGenerator
+
+	// ExposeCause will put the ErrChain.Cause to StandardResponse.Error.
+	// Should be false on production to avoid leaking internal error to the client
+	ExposeCause bool
+}
+
+// NewHTTPErrorHandler creates echo.HTTPErrorHandler aware of custerr.ErrChain, echo.HTTPError and validation errors.
+// ErrChain.Code will be used as the http status code, fallback to 500 if the code is not a valid http status code.
+// Every error will be logged with the request ID, and 5xx errors are logged on error level to be reported to Sentry
+// by the hook installed by cmd.SetupLogger
+func NewHTTPErrorHandler(opts *HTTPErrorHandlerOpts) echo.HTTPErrorHandler {
+	if opts == nil {
+		opts = &HTTPErrorHandlerOpts{}
+	}
+
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		response, cause := opts.buildErrorResponse(err)
+		logError(c, err, response.Status, cause)
+
+		if c.Request().Method == http.MethodHead {
+			helper.LogIfError(c.NoContent(response.Status))
+			return
+		}
+
+		if opts.ResponseGenerator != nil {
+			helper.LogIfError(opts.ResponseGenerator.GenerateEchoAPIResponse(c, response, nil))
+			return
+		}
+
+		helper.LogIfError(c.JSON(response.Status, response))
+	}
+}
+
+func (o *HTTPErrorHandlerOpts) buildErrorResponse(err error) (*StandardResponse, error) {
+	response := &StandardResponse{
+		Success: false,
+		Message: http.StatusText(http.StatusInternalServerError),
+		Status:  http.StatusInternalServerError,
+	}
+
+	var (
+		errChain         *custerr.ErrChain
+		httpErr          *echo.HTTPError
+		validationErr    *ValidationError
+		validationErrors validator.ValidationErrors
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		response.Status = http.StatusBadRequest
+		response.Message = http.StatusText(http.StatusBadRequest)
+		response.Error = validationErr.Error()
+		response.FieldErrors = validationErr.Fields
+
+		return response, nil
+	case asErrChain(err, &errChain):
+		response.Status = statusFromCode(errChain.Code)
+		response.Message = errChain.Message
+		if errChain.Cause != nil && o.ExposeCause {
+			response.Error = errChain.Cause.Error()
+		}
+
+		return response, errChain.Cause
+	case errors.As(err, &httpErr):
+		response.Status = statusFromCode(httpErr.Code)
+		response.Message = http.StatusText(response.Status)
+		if msg, ok := httpErr.Message.(string); ok {
+			response.Message = msg
+		}
+
+		if httpErr.Internal != nil && o.ExposeCause {
+			response.Error = httpErr.Internal.Error()
+		}
+
+		return response, httpErr.Internal
+	case errors.As(err, &validationErrors):
+		response.Status = http.StatusBadRequest
+		response.Message = http.StatusText(http.StatusBadRequest)
+		response.Error = validationErrors.Error()
+		response.FieldErrors = fieldErrorsFromValidator(validationErrors)
+
+		return response, nil
+	}
+
+	if o.ExposeCause {
+		response.Error = err.Error()
+	}
+
+	return response, err
+}
+
+// asErrChain finds the ErrChain in err chain, either stored as value or pointer
+func asErrChain(err error, target **custerr.ErrChain) bool {
+	if errors.As(err, target) {
+		return true
+	}
+
+	var val custerr.ErrChain
+	if errors.As(err, &val) {
+		*target = &val
+		return true
+	}
+
+	return false
+}
+
+func statusFromCode(code int) int {
+	if code < 100 || code > 599 {
+		return http.StatusInternalServerError
+	}
+
+	return code
+}
+
+func logError(c echo.Context, err error, status int, cause error) {
+	req := c.Request()
+	fields := logrus.Fields{
+		"request_id": echomiddleware.GetRequestIDFromCtx(req.Context()),
+		"method":     req.Method,
+		"path":       c.Path(),
+		"status":     status,
+	}
+
+	var errChain *custerr.ErrChain
+	if asErrChain(err, &errChain) {
+		for k, v := range errChain.Fields {
+			fields[k] = v
+		}
+	}
+
+	if cause != nil {
+		fields["cause"] = cause.Error()
+	}
+
+	entry := logrus.WithFields(fields).WithError(err)
+	if status >= http.StatusInternalServerError {
+		entry.Error("http request failed")
+		return
+	}
+
+	entry.Info("http request rejected")
+}
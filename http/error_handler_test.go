@@ -0,0 +1,141 @@
+package http_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/encryption"
+	custerr "github.com/sweet-go/stdlib/error"
+	"github.com/sweet-go/stdlib/http"
+)
+
+func TestHTTPErrorHandler(t *testing.T) {
+	serve := func(handlerErr error, opts *http.HTTPErrorHandlerOpts, method string) *httptest.ResponseRecorder {
+		ec := echo.New()
+		ec.HTTPErrorHandler = http.NewHTTPErrorHandler(opts)
+		ec.Add(method, "/", func(c echo.Context) error {
+			return handlerErr
+		})
+
+		req := httptest.NewRequest(method, "/", nil)
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) *http.StandardResponse {
+		res := &http.StandardResponse{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+		return res
+	}
+
+	t.Run("err chain pointer", func(t *testing.T) {
+		rec := serve(&custerr.ErrChain{
+			Message: "user not found",
+			Cause:   errors.New("record not found"),
+			Code:    nethttp.StatusNotFound,
+		}, nil, nethttp.MethodGet)
+
+		assert.Equal(t, nethttp.StatusNotFound, rec.Code)
+
+		res := decode(t, rec)
+		assert.False(t, res.Success)
+		assert.Equal(t, "user not found", res.Message)
+		assert.Equal(t, nethttp.StatusNotFound, res.Status)
+		assert.Empty(t, res.Error)
+	})
+
+	t.Run("wrapped err chain value exposing cause", func(t *testing.T) {
+		rec := serve(fmt.Errorf("usecase: %w", custerr.ErrChain{
+			Message: "bad input",
+			Cause:   errors.New("name is empty"),
+			Code:    nethttp.StatusBadRequest,
+		}), &http.HTTPErrorHandlerOpts{ExposeCause: true}, nethttp.MethodGet)
+
+		assert.Equal(t, nethttp.StatusBadRequest, rec.Code)
+
+		res := decode(t, rec)
+		assert.Equal(t, "bad input", res.Message)
+		assert.Equal(t, "name is empty", res.Error)
+	})
+
+	t.Run("err chain invalid code", func(t *testing.T) {
+		rec := serve(&custerr.ErrChain{
+			Message: "something wrong",
+			Code:    12345,
+		}, nil, nethttp.MethodGet)
+
+		assert.Equal(t, nethttp.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("echo http error", func(t *testing.T) {
+		rec := serve(echo.NewHTTPError(nethttp.StatusForbidden, "no access"), nil, nethttp.MethodGet)
+
+		assert.Equal(t, nethttp.StatusForbidden, rec.Code)
+		assert.Equal(t, "no access", decode(t, rec).Message)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		type input struct {
+			Name string `validate:"required"`
+		}
+
+		rec := serve(validator.New().Struct(input{}), nil, nethttp.MethodPost)
+
+		assert.Equal(t, nethttp.StatusBadRequest, rec.Code)
+		assert.NotEmpty(t, decode(t, rec).Error)
+	})
+
+	t.Run("unknown error hides cause", func(t *testing.T) {
+		rec := serve(errors.New("db connection refused"), nil, nethttp.MethodGet)
+
+		assert.Equal(t, nethttp.StatusInternalServerError, rec.Code)
+
+		res := decode(t, rec)
+		assert.Equal(t, nethttp.StatusText(nethttp.StatusInternalServerError), res.Message)
+		assert.Empty(t, res.Error)
+	})
+
+	t.Run("head request", func(t *testing.T) {
+		rec := serve(echo.ErrNotFound, nil, nethttp.MethodHead)
+
+		assert.Equal(t, nethttp.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("signed response", func(t *testing.T) {
+		key, err := encryption.GenerateKey(nil)
+		assert.NoError(t, err)
+
+		rec := serve(&custerr.ErrChain{
+			Message: "conflict",
+			Code:    nethttp.StatusConflict,
+		}, &http.HTTPErrorHandlerOpts{
+			ResponseGenerator: http.NewStandardAPIResponseGenerator(&encryption.SignOpts{
+				Random:  rand.Reader,
+				PrivKey: key,
+				Alg:     crypto.SHA256,
+			}),
+		}, nethttp.MethodGet)
+
+		assert.Equal(t, nethttp.StatusConflict, rec.Code)
+
+		res := &http.StandardResponse{}
+		err = http.DecodeVerifiedAPIResponse(rec.Body.Bytes(), &encryption.VerifyOpts{
+			PublicKey: &key.PublicKey,
+			Alg:       crypto.SHA256,
+		}, res)
+		assert.NoError(t, err)
+		assert.Equal(t, "conflict", res.Message)
+	})
+}
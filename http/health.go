@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// list of health check endpoints registered by Server
+const (
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
+)
+
+// list of health check result
+const (
+	HealthStatusOK      = "ok"
+	HealthStatusDown    = "down"
+	HealthStatusTimeout = "timeout"
+)
+
+// HealthChecker returns non nil error if the dependency is unhealthy. Method value such as worker.HealthStatus.Check
+// can be used directly
+type HealthChecker func(ctx context.Context) error
+
+// Pinger is implemented by *sql.DB. For gorm, use the *sql.DB returned by gorm.DB.DB()
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingChecker creates HealthChecker pinging the database, e.g. postgres
+func PingChecker(db Pinger) HealthChecker {
+	return db.PingContext
+}
+
+// RedisChecker creates HealthChecker pinging the redis server
+func RedisChecker(client redis.UniversalClient) HealthChecker {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// HealthCheckResult is the data of health check endpoint response
+type HealthCheckResult struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// runHealthCheckers runs every checker concurrently bounded by timeout. The checker not finished in time
+// is reported as HealthStatusTimeout without waiting for it. The errors are only logged, since the endpoints
+// are served unauthenticated and the errors may leak the DSN, internal address or credentials
+func runHealthCheckers(ctx context.Context, checkers map[string]HealthChecker, timeout time.Duration) *HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type checkResult struct {
+		name string
+		err  error
+	}
+
+	// buffered, so the checker finishing after the timeout doesn't block forever
+	results := make(chan checkResult, len(checkers))
+	for name, checker := range checkers {
+		go func(name string, checker HealthChecker) {
+			results <- checkResult{name: name, err: checker(ctx)}
+		}(name, checker)
+	}
+
+	result := &HealthCheckResult{
+		Status: HealthStatusOK,
+		Checks: make(map[string]string, len(checkers)),
+	}
+
+	for range checkers {
+		select {
+		case res := <-results:
+			result.Checks[res.name] = HealthStatusOK
+			if res.err != nil {
+				logrus.WithError(res.err).WithField("check", res.name).Error("health check failed")
+				result.Status = HealthStatusDown
+				result.Checks[res.name] = HealthStatusDown
+			}
+		case <-ctx.Done():
+			result.Status = HealthStatusDown
+			for name := range checkers {
+				if _, ok := result.Checks[name]; !ok {
+					logrus.WithField("check", name).Error("health check timed out")
+					result.Checks[name] = HealthStatusTimeout
+				}
+			}
+
+			return result
+		}
+	}
+
+	return result
+}
+
+func healthCheckHandler(checkers map[string]HealthChecker, timeout time.Duration, ready func() bool) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		result := runHealthCheckers(c.Request().Context(), checkers, timeout)
+		if ready != nil && !ready() {
+			result.Status = HealthStatusDown
+		}
+
+		status := http.StatusOK
+		if result.Status != HealthStatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		return c.JSON(status, &StandardResponse{
+			Success: status == http.StatusOK,
+			Message: http.StatusText(status),
+			Status:  status,
+			Data:    result,
+		})
+	}
+}
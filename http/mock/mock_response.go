@@ -5,6 +5,7 @@
 package http_mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -51,6 +52,21 @@ func (mr *MockAPIResponseGeneratorMockRecorder) GenerateAPIResponse(arg0, arg1 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateAPIResponse", reflect.TypeOf((*MockAPIResponseGenerator)(nil).GenerateAPIResponse), arg0, arg1)
 }
 
+// GenerateAPIResponseWithContext mocks base method.
+func (m *MockAPIResponseGenerator) GenerateAPIResponseWithContext(arg0 context.Context, arg1 *http.StandardResponse, arg2 *encryption.SignOpts) (*http.APIResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateAPIResponseWithContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*http.APIResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateAPIResponseWithContext indicates an expected call of GenerateAPIResponseWithContext.
+func (mr *MockAPIResponseGeneratorMockRecorder) GenerateAPIResponseWithContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateAPIResponseWithContext", reflect.TypeOf((*MockAPIResponseGenerator)(nil).GenerateAPIResponseWithContext), arg0, arg1, arg2)
+}
+
 // GenerateEchoAPIResponse mocks base method.
 func (m *MockAPIResponseGenerator) GenerateEchoAPIResponse(arg0 echo.Context, arg1 *http.StandardResponse, arg2 *encryption.SignOpts) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// list of content types supported by the APIResponseGenerator content negotiation
+const (
+	MIMEApplicationProblemJSON = "application/problem+json"
+	MIMEApplicationMsgpack     = "application/msgpack"
+	MIMEApplicationCBOR        = "application/cbor"
+)
+
+// list of headers carrying the signature when the response is not sent as APIResponse JSON envelope
+const (
+	// HeaderSignature contains base64 encoded signature of the response body
+	HeaderSignature = "X-Signature"
+
+	// HeaderSignatureMetadata contains the compact JSON of SignatureMetadata covered by HeaderSignature
+	HeaderSignatureMetadata = "X-Signature-Metadata"
+)
+
+// ResponseEncoder encodes the response body for a specific content type
+type ResponseEncoder interface {
+	// ContentType returns the media type produced by the encoder
+	ContentType() string
+
+	// Encode encodes v into bytes to be sent as response body
+	Encode(v any) ([]byte, error)
+}
+
+// ProblemDetails is the RFC 7807 problem details object.
+// RequestID and FieldErrors are extension members
+type ProblemDetails struct {
+	Type        string       `json:"type"`
+	Title       string       `json:"title"`
+	Status      int          `json:"status"`
+	Detail      string       `json:"detail,omitempty"`
+	Instance    string       `json:"instance,omitempty"`
+	RequestID   string       `json:"request_id,omitempty"`
+	FieldErrors []FieldError `json:"field_errors,omitempty"`
+}
+
+// NewProblemDetails creates RFC 7807 problem details based on the error StandardResponse
+func NewProblemDetails(response *StandardResponse, instance, requestID string) *ProblemDetails {
+	title := response.Message
+	if title == "" {
+		title = http.StatusText(response.Status)
+	}
+
+	return &ProblemDetails{
+		Type:        "about:blank",
+		Title:       title,
+		Status:      response.Status,
+		Detail:      response.Error,
+		Instance:    instance,
+		RequestID:   requestID,
+		FieldErrors: response.FieldErrors,
+	}
+}
+
+type problemJSONEncoder struct{}
+
+func (problemJSONEncoder) ContentType() string {
+	return MIMEApplicationProblemJSON
+}
+
+func (problemJSONEncoder) Encode(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+type msgpackEncoder struct{}
+
+// NewMsgpackEncoder creates ResponseEncoder producing MessagePack body. Struct fields are named using the json tag
+func NewMsgpackEncoder() ResponseEncoder {
+	return msgpackEncoder{}
+}
+
+func (msgpackEncoder) ContentType() string {
+	return MIMEApplicationMsgpack
+}
+
+func (msgpackEncoder) Encode(v any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := msgpack.NewEncoder(buf)
+	enc.SetCustomStructTag("json")
+	enc.SetOmitEmpty(true)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+type cborEncoder struct {
+	mode cbor.EncMode
+}
+
+// NewCBOREncoder creates ResponseEncoder producing CBOR body using the core deterministic encoding.
+// Struct fields are named using the json tag
+func NewCBOREncoder() ResponseEncoder {
+	mode, _ := cbor.CoreDetEncOptions().EncMode()
+
+	return &cborEncoder{
+		mode: mode,
+	}
+}
+
+func (ce *cborEncoder) ContentType() string {
+	return MIMEApplicationCBOR
+}
+
+func (ce *cborEncoder) Encode(v any) ([]byte, error) {
+	return ce.mode.Marshal(v)
+}
+
+type qualityValue struct {
+	value   string
+	quality float64
+}
+
+// parseQualityValues parses header values weighted by quality such as Accept and Accept-Language,
+// then sorts them by quality while keeping the order of appearance for equal quality
+func parseQualityValues(header string) []qualityValue {
+	var res []qualityValue
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+		if value == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			k, v, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(k) != "q" {
+				continue
+			}
+
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		res = append(res, qualityValue{
+			value:   value,
+			quality: quality,
+		})
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].quality > res[j].quality
+	})
+
+	return res
+}
+
+// negotiateEncoder returns the best encoder based on the Accept header.
+// nil means the response should be sent as the default APIResponse JSON envelope
+func negotiateEncoder(accept string, isError bool, encoders []ResponseEncoder) ResponseEncoder {
+	for _, accepted := range parseQualityValues(accept) {
+		switch accepted.value {
+		case "*/*", "application/*", "application/json":
+			return nil
+		case MIMEApplicationProblemJSON:
+			if isError {
+				return problemJSONEncoder{}
+			}
+
+			continue
+		}
+
+		for _, encoder := range encoders {
+			if encoder.ContentType() == accepted.value {
+				return encoder
+			}
+		}
+	}
+
+	return nil
+}
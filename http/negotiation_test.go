@@ -0,0 +1,159 @@
+package http_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/encryption"
+	"github.com/sweet-go/stdlib/http"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestGenerateEchoAPIResponse_ContentNegotiation(t *testing.T) {
+	key, err := encryption.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	signer := http.NewAPIResponseGenerator(&http.APIResponseGeneratorOpts{
+		DefaultSignOpts: &encryption.SignOpts{
+			Random:  rand.Reader,
+			PrivKey: key,
+			Alg:     crypto.SHA256,
+		},
+		MetadataOpts: &http.SignatureMetadataOpts{KeyID: "key-1"},
+		Encoders:     []http.ResponseEncoder{http.NewMsgpackEncoder(), http.NewCBOREncoder()},
+	})
+
+	verifyOpts := &http.APIResponseVerifyOpts{
+		VerifyOpts: &encryption.VerifyOpts{
+			PublicKey: &key.PublicKey,
+			Alg:       crypto.SHA256,
+		},
+	}
+
+	success := &http.StandardResponse{
+		Success: true,
+		Message: "Success",
+		Status:  nethttp.StatusOK,
+		Data:    map[string]string{"message": "Hello World"},
+	}
+
+	failure := &http.StandardResponse{
+		Success: false,
+		Message: "user not found",
+		Status:  nethttp.StatusNotFound,
+		Error:   "no user with given id",
+	}
+
+	serve := func(response *http.StandardResponse, accept string) *httptest.ResponseRecorder {
+		ec := echo.New()
+		req := httptest.NewRequest(nethttp.MethodGet, "/users/1", nil)
+		req.Header.Set(echo.HeaderAccept, accept)
+		rec := httptest.NewRecorder()
+
+		err := signer.GenerateEchoAPIResponse(ec.NewContext(req, rec), response, nil)
+		assert.NoError(t, err)
+
+		return rec
+	}
+
+	t.Run("default json envelope", func(t *testing.T) {
+		for _, accept := range []string{"", "*/*", "application/json", "text/html", "application/problem+json"} {
+			rec := serve(success, accept)
+
+			assert.Equal(t, nethttp.StatusOK, rec.Code)
+			assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+			assert.Empty(t, rec.Header().Get(http.HeaderSignature))
+
+			_, err := http.VerifyAPIResponseWithOpts(rec.Body.Bytes(), verifyOpts)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("problem details for error", func(t *testing.T) {
+		rec := serve(failure, "application/json;q=0.5, application/problem+json")
+
+		assert.Equal(t, nethttp.StatusNotFound, rec.Code)
+		assert.Equal(t, http.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+		verified, err := http.VerifyEncodedResponse(rec.Body.Bytes(), rec.Header(), verifyOpts)
+		assert.NoError(t, err)
+		assert.Equal(t, "key-1", verified.Metadata.KeyID)
+
+		problem := &http.ProblemDetails{}
+		assert.NoError(t, json.Unmarshal(verified.Response, problem))
+		assert.Equal(t, "about:blank", problem.Type)
+		assert.Equal(t, "user not found", problem.Title)
+		assert.Equal(t, nethttp.StatusNotFound, problem.Status)
+		assert.Equal(t, "no user with given id", problem.Detail)
+		assert.Equal(t, "/users/1", problem.Instance)
+	})
+
+	t.Run("msgpack", func(t *testing.T) {
+		rec := serve(success, "application/msgpack")
+
+		assert.Equal(t, http.MIMEApplicationMsgpack, rec.Header().Get(echo.HeaderContentType))
+
+		verified, err := http.VerifyEncodedResponse(rec.Body.Bytes(), rec.Header(), verifyOpts)
+		assert.NoError(t, err)
+
+		res := map[string]any{}
+		assert.NoError(t, msgpack.Unmarshal(verified.Response, &res))
+		assert.Equal(t, "Success", res["message"])
+		assert.NotContains(t, res, "error")
+	})
+
+	t.Run("cbor preferred by quality", func(t *testing.T) {
+		rec := serve(success, "application/msgpack;q=0.4, application/cbor;q=0.9")
+
+		assert.Equal(t, http.MIMEApplicationCBOR, rec.Header().Get(echo.HeaderContentType))
+
+		verified, err := http.VerifyEncodedResponse(rec.Body.Bytes(), rec.Header(), verifyOpts)
+		assert.NoError(t, err)
+
+		res := map[string]any{}
+		assert.NoError(t, cbor.Unmarshal(verified.Response, &res))
+		assert.Equal(t, "Success", res["message"])
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		rec := serve(success, "application/cbor")
+
+		body := rec.Body.Bytes()
+		body[len(body)-1] ^= 0xff
+
+		_, err := http.VerifyEncodedResponse(body, rec.Header(), verifyOpts)
+		assert.ErrorIs(t, err, http.ErrInvalidSignature)
+	})
+
+	t.Run("verifying client", func(t *testing.T) {
+		ec := echo.New()
+		ec.GET("/", func(c echo.Context) error {
+			return signer.GenerateEchoAPIResponse(c, success, nil)
+		})
+
+		srv := httptest.NewServer(ec)
+		defer srv.Close()
+
+		client := http.NewVerifyingClient(srv.Client(), verifyOpts)
+		for _, accept := range []string{"application/json", "application/msgpack"} {
+			req, err := nethttp.NewRequest(nethttp.MethodGet, srv.URL, nil)
+			assert.NoError(t, err)
+			req.Header.Set(echo.HeaderAccept, accept)
+
+			resp, err := client.Do(req)
+			assert.NoError(t, err)
+
+			_, err = io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.NoError(t, resp.Body.Close())
+		}
+	})
+}
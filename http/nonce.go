@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sweet-go/stdlib/cacher"
+)
+
+type inMemoryNonceChecker struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	now    func() time.Time
+}
+
+// NewInMemoryNonceChecker creates NonceChecker remembering the nonces in memory. The expired nonces are forgotten
+// on every check. Use NewCacherNonceChecker when the responses are verified by more than one instance
+func NewInMemoryNonceChecker() NonceChecker {
+	return &inMemoryNonceChecker{
+		nonces: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+func (c *inMemoryNonceChecker) CheckNonce(_ context.Context, nonce string, expiresAt time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for n, exp := range c.nonces {
+		if !exp.IsZero() && now.After(exp) {
+			delete(c.nonces, n)
+		}
+	}
+
+	if _, ok := c.nonces[nonce]; ok {
+		return ErrReplayedAPIResponse
+	}
+
+	if !expiresAt.IsZero() && !expiresAt.After(now) {
+		// already rejected by the freshness check, unless the clocks disagree
+		expiresAt = now.Add(time.Second)
+	}
+
+	c.nonces[nonce] = expiresAt
+
+	return nil
+}
+
+type cacherNonceChecker struct {
+	cacher    cacher.Cacher
+	keyPrefix string
+}
+
+// NewCacherNonceChecker creates NonceChecker remembering the nonces using the cacher, shared by every instance.
+// If keyPrefix is empty, will use `api_response:nonce:`
+func NewCacherNonceChecker(cache cacher.Cacher, keyPrefix string) NonceChecker {
+	if keyPrefix == "" {
+		keyPrefix = "api_response:nonce:"
+	}
+
+	return &cacherNonceChecker{
+		cacher:    cache,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (c *cacherNonceChecker) CheckNonce(ctx context.Context, nonce string, expiresAt time.Time) error {
+	var ttl time.Duration
+	if !expiresAt.IsZero() {
+		ttl = time.Until(expiresAt)
+		if ttl <= 0 {
+			// already rejected by the freshness check, unless the clocks disagree
+			ttl = time.Second
+		}
+	}
+
+	fresh, err := c.cacher.SetNX(ctx, c.keyPrefix+nonce, "1", ttl)
+	if err != nil {
+		return err
+	}
+
+	if !fresh {
+		return ErrReplayedAPIResponse
+	}
+
+	return nil
+}
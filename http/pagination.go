@@ -0,0 +1,222 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// list of query params used for pagination
+const (
+	QueryParamPage     = "page"
+	QueryParamPageSize = "page_size"
+	QueryParamCursor   = "cursor"
+)
+
+// list of pagination limits applied by ParsePaginationParams
+const (
+	// DefaultMaxPageSize caps page_size when maxPageSize is not positive
+	DefaultMaxPageSize = 100
+
+	// MaxPage caps page, so the offset stays sane
+	MaxPage = 1_000_000
+)
+
+// Pagination is the pagination metadata for list endpoints.
+// Offset based pagination will populate Total, Page and TotalPages, which are always present even when zero,
+// while cursor based pagination will populate NextCursor and PrevCursor
+type Pagination struct {
+	Total      *int64 `json:"total,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	TotalPages *int   `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+}
+
+// PaginationLinks is the navigation links for list endpoints
+type PaginationLinks struct {
+	Self  string `json:"self,omitempty"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// PaginationParams is the pagination params requested by the client
+type PaginationParams struct {
+	Page     int
+	PageSize int
+	Cursor   string
+}
+
+// Offset returns the offset for offset based pagination query. Never negative, even if the params overflow
+func (pp *PaginationParams) Offset() int {
+	if pp.Page <= 1 || pp.PageSize <= 0 {
+		return 0
+	}
+
+	if pp.Page-1 > math.MaxInt/pp.PageSize {
+		return math.MaxInt
+	}
+
+	return (pp.Page - 1) * pp.PageSize
+}
+
+// ParsePaginationParams reads page, page_size and cursor query params from echo context.
+// Invalid or missing page will default to 1 and is capped to MaxPage, while invalid or missing page_size
+// will default to defaultPageSize and is capped to maxPageSize, or DefaultMaxPageSize if maxPageSize is not positive
+func ParsePaginationParams(c echo.Context, defaultPageSize, maxPageSize int) *PaginationParams {
+	params := &PaginationParams{
+		Page:     1,
+		PageSize: defaultPageSize,
+		Cursor:   c.QueryParam(QueryParamCursor),
+	}
+
+	if page, err := strconv.Atoi(c.QueryParam(QueryParamPage)); err == nil && page > 0 {
+		params.Page = page
+	}
+
+	if params.Page > MaxPage {
+		params.Page = MaxPage
+	}
+
+	if size, err := strconv.Atoi(c.QueryParam(QueryParamPageSize)); err == nil && size > 0 {
+		params.PageSize = size
+	}
+
+	if maxPageSize <= 0 {
+		maxPageSize = DefaultMaxPageSize
+	}
+
+	if params.PageSize > maxPageSize {
+		params.PageSize = maxPageSize
+	}
+
+	return params
+}
+
+// NewPaginatedResponse creates a successful StandardResponse for list endpoints.
+// nil items will be rendered as empty array instead of omitted
+func NewPaginatedResponse[T any](items []T, pagination *Pagination, links *PaginationLinks) *StandardResponse {
+	if items == nil {
+		items = []T{}
+	}
+
+	return &StandardResponse{
+		Success:    true,
+		Message:    http.StatusText(http.StatusOK),
+		Status:     http.StatusOK,
+		Data:       items,
+		Pagination: pagination,
+		Links:      links,
+	}
+}
+
+// NewOffsetPaginatedResponse creates a paginated StandardResponse using offset based pagination.
+// If requestURL is not nil, will also generate the navigation links based on it
+func NewOffsetPaginatedResponse[T any](items []T, params *PaginationParams, total int64, requestURL *url.URL) *StandardResponse {
+	totalPages := 0
+	if params.PageSize > 0 {
+		totalPages = int((total + int64(params.PageSize) - 1) / int64(params.PageSize))
+	}
+
+	pagination := &Pagination{
+		Total:      &total,
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		TotalPages: &totalPages,
+		HasNext:    params.Page < totalPages,
+		HasPrev:    params.Page > 1,
+	}
+
+	var links *PaginationLinks
+	if requestURL != nil {
+		pageLink := func(page int) string {
+			return buildPaginationLink(requestURL, map[string]string{
+				QueryParamPage:     strconv.Itoa(page),
+				QueryParamPageSize: strconv.Itoa(params.PageSize),
+			})
+		}
+
+		links = &PaginationLinks{
+			Self:  pageLink(params.Page),
+			First: pageLink(1),
+		}
+
+		if totalPages > 0 {
+			links.Last = pageLink(totalPages)
+		}
+
+		if pagination.HasPrev {
+			links.Prev = pageLink(params.Page - 1)
+		}
+
+		if pagination.HasNext {
+			links.Next = pageLink(params.Page + 1)
+		}
+	}
+
+	return NewPaginatedResponse(items, pagination, links)
+}
+
+// NewCursorPaginatedResponse creates a paginated StandardResponse using cursor based pagination.
+// Empty nextCursor or prevCursor means there is no next or previous page.
+// If requestURL is not nil, will also generate the navigation links based on it
+func NewCursorPaginatedResponse[T any](items []T, pageSize int, nextCursor, prevCursor string, requestURL *url.URL) *StandardResponse {
+	pagination := &Pagination{
+		PageSize:   pageSize,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		HasNext:    nextCursor != "",
+		HasPrev:    prevCursor != "",
+	}
+
+	var links *PaginationLinks
+	if requestURL != nil {
+		cursorLink := func(cursor string) string {
+			return buildPaginationLink(requestURL, map[string]string{
+				QueryParamCursor:   cursor,
+				QueryParamPageSize: strconv.Itoa(pageSize),
+			})
+		}
+
+		links = &PaginationLinks{
+			Self:  requestURL.String(),
+			First: cursorLink(""),
+		}
+
+		if pagination.HasPrev {
+			links.Prev = cursorLink(prevCursor)
+		}
+
+		if pagination.HasNext {
+			links.Next = cursorLink(nextCursor)
+		}
+	}
+
+	return NewPaginatedResponse(items, pagination, links)
+}
+
+// buildPaginationLink copies the request url and replace the query params. Empty value will remove the query param
+func buildPaginationLink(requestURL *url.URL, params map[string]string) string {
+	u := *requestURL
+	query := u.Query()
+	for k, v := range params {
+		if v == "" {
+			query.Del(k)
+			continue
+		}
+
+		query.Set(k, v)
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
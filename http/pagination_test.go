@@ -0,0 +1,133 @@
+package http_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/json"
+	"math"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/encryption"
+	"github.com/sweet-go/stdlib/http"
+)
+
+func TestParsePaginationParams(t *testing.T) {
+	ec := echo.New()
+
+	t.Run("default", func(t *testing.T) {
+		req := httptest.NewRequest(nethttp.MethodGet, "/?page=abc&page_size=-1", nil)
+		params := http.ParsePaginationParams(ec.NewContext(req, httptest.NewRecorder()), 20, 100)
+
+		assert.Equal(t, 1, params.Page)
+		assert.Equal(t, 20, params.PageSize)
+		assert.Equal(t, 0, params.Offset())
+	})
+
+	t.Run("capped page size", func(t *testing.T) {
+		req := httptest.NewRequest(nethttp.MethodGet, "/?page=3&page_size=500&cursor=abc", nil)
+		params := http.ParsePaginationParams(ec.NewContext(req, httptest.NewRecorder()), 20, 100)
+
+		assert.Equal(t, 3, params.Page)
+		assert.Equal(t, 100, params.PageSize)
+		assert.Equal(t, "abc", params.Cursor)
+		assert.Equal(t, 200, params.Offset())
+	})
+
+	t.Run("capped page", func(t *testing.T) {
+		req := httptest.NewRequest(nethttp.MethodGet, "/?page=9223372036854775807&page_size=1000", nil)
+		params := http.ParsePaginationParams(ec.NewContext(req, httptest.NewRecorder()), 20, 0)
+
+		assert.Equal(t, http.MaxPage, params.Page)
+		assert.Equal(t, http.DefaultMaxPageSize, params.PageSize)
+		assert.Equal(t, (http.MaxPage-1)*http.DefaultMaxPageSize, params.Offset())
+	})
+
+	t.Run("offset never overflows", func(t *testing.T) {
+		params := &http.PaginationParams{Page: math.MaxInt, PageSize: 100}
+		assert.Equal(t, math.MaxInt, params.Offset())
+	})
+}
+
+func TestNewOffsetPaginatedResponse(t *testing.T) {
+	requestURL, err := url.Parse("https://api.test/users?page=2&page_size=10&sort=name")
+	assert.NoError(t, err)
+
+	t.Run("middle page", func(t *testing.T) {
+		resp := http.NewOffsetPaginatedResponse([]string{"a", "b"}, &http.PaginationParams{Page: 2, PageSize: 10}, 35, requestURL)
+
+		assert.True(t, resp.Success)
+		assert.Equal(t, nethttp.StatusOK, resp.Status)
+		assert.Equal(t, int64(35), *resp.Pagination.Total)
+		assert.Equal(t, 4, *resp.Pagination.TotalPages)
+		assert.True(t, resp.Pagination.HasNext)
+		assert.True(t, resp.Pagination.HasPrev)
+		assert.Equal(t, "https://api.test/users?page=3&page_size=10&sort=name", resp.Links.Next)
+		assert.Equal(t, "https://api.test/users?page=1&page_size=10&sort=name", resp.Links.Prev)
+		assert.Equal(t, "https://api.test/users?page=1&page_size=10&sort=name", resp.Links.First)
+		assert.Equal(t, "https://api.test/users?page=4&page_size=10&sort=name", resp.Links.Last)
+	})
+
+	t.Run("empty items without links", func(t *testing.T) {
+		resp := http.NewOffsetPaginatedResponse[string](nil, &http.PaginationParams{Page: 1, PageSize: 10}, 0, nil)
+
+		assert.False(t, resp.Pagination.HasNext)
+		assert.False(t, resp.Pagination.HasPrev)
+		assert.Nil(t, resp.Links)
+
+		b, err := json.Marshal(resp)
+		assert.NoError(t, err)
+		assert.Contains(t, string(b), `"data":[]`)
+		assert.Contains(t, string(b), `"total":0`)
+		assert.Contains(t, string(b), `"total_pages":0`)
+	})
+}
+
+func TestNewCursorPaginatedResponse(t *testing.T) {
+	requestURL, err := url.Parse("https://api.test/feeds?cursor=c1&page_size=5")
+	assert.NoError(t, err)
+
+	resp := http.NewCursorPaginatedResponse([]int{1, 2, 3}, 5, "c2", "", requestURL)
+
+	assert.Equal(t, "c2", resp.Pagination.NextCursor)
+	assert.True(t, resp.Pagination.HasNext)
+	assert.False(t, resp.Pagination.HasPrev)
+	assert.Equal(t, "https://api.test/feeds?cursor=c2&page_size=5", resp.Links.Next)
+	assert.Equal(t, "https://api.test/feeds?page_size=5", resp.Links.First)
+	assert.Empty(t, resp.Links.Prev)
+}
+
+func TestPaginatedResponseSignature(t *testing.T) {
+	key, err := encryption.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	signer := http.NewStandardAPIResponseGenerator(&encryption.SignOpts{
+		Random:  rand.Reader,
+		PrivKey: key,
+		Alg:     crypto.SHA256,
+	})
+
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	resp := http.NewOffsetPaginatedResponse([]user{{Name: "a"}}, &http.PaginationParams{Page: 1, PageSize: 1}, 2, &url.URL{Path: "/users"})
+	signed, err := signer.GenerateAPIResponse(resp, nil)
+	assert.NoError(t, err)
+
+	body, err := json.Marshal(signed)
+	assert.NoError(t, err)
+
+	res := &http.StandardResponse{}
+	err = http.DecodeVerifiedAPIResponse(body, &encryption.VerifyOpts{
+		PublicKey: &key.PublicKey,
+		Alg:       crypto.SHA256,
+	}, res)
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Pagination, res.Pagination)
+	assert.Equal(t, resp.Links, res.Links)
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+
+	"github.com/getsentry/raven-go"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/sweet-go/stdlib/helper"
+	echomiddleware "github.com/sweet-go/stdlib/http/echo_middleware"
+)
+
+// DefaultRecoverStackSize is the default max bytes of the captured panic stack
+const DefaultRecoverStackSize = 8 << 10
+
+// RecoverOpts is the options for Recover middleware
+type RecoverOpts struct {
+	// Logger if nil, will use logrus standard logger, which has the Sentry hook installed by cmd.SetupLogger
+	Logger *logrus.Logger
+
+	// ResponseGenerator if not nil, the 500 response will be signed using this generator
+	ResponseGenerator APIResponseGenerator
+
+	// StackSize is the max bytes of the captured stack. Default to DefaultRecoverStackSize
+	StackSize int
+
+	// RedactedHeaders if nil, will use echomiddleware.DefaultRedactedHeaders
+	RedactedHeaders []string
+
+	// RedactedFields are the query parameters which value will be redacted.
+	// If nil, will use echomiddleware.DefaultRedactedFields
+	RedactedFields []string
+
+	// UserIDExtractor if nil, will use echomiddleware.UserIDFromJWT
+	UserIDExtractor func(c echo.Context) string
+}
+
+// Recover is a middleware to recover from panics anywhere in the chain. The panic is logged on error level,
+// so it is reported to Sentry by the hook installed by cmd.SetupLogger, alongside the stack, request ID, route,
+// user ID and the sanitized request. Then the standard 500 StandardResponse is returned.
+// Should be placed after RequestID and AccessLog middlewares so the request ID is reported and the 500 is logged
+func Recover(opts *RecoverOpts) echo.MiddlewareFunc {
+	if opts == nil {
+		opts = &RecoverOpts{}
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+
+	stackSize := opts.StackSize
+	if stackSize <= 0 {
+		stackSize = DefaultRecoverStackSize
+	}
+
+	redactedHeaders := opts.RedactedHeaders
+	if redactedHeaders == nil {
+		redactedHeaders = echomiddleware.DefaultRedactedHeaders
+	}
+
+	redactedFields := opts.RedactedFields
+	if redactedFields == nil {
+		redactedFields = echomiddleware.DefaultRedactedFields
+	}
+
+	userIDExtractor := opts.UserIDExtractor
+	if userIDExtractor == nil {
+		userIDExtractor = echomiddleware.UserIDFromJWT
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+
+				// http.ErrAbortHandler is used to abort the response on purpose, must not be suppressed
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				err, ok := r.(error)
+				if !ok {
+					err = fmt.Errorf("%v", r)
+				}
+
+				stack := make([]byte, stackSize)
+				stack = stack[:runtime.Stack(stack, false)]
+
+				req := c.Request()
+				rid := echomiddleware.GetRequestIDFromCtx(req.Context())
+				route := c.Path()
+
+				fields := logrus.Fields{
+					"request_id":   rid,
+					"route":        route,
+					"method":       req.Method,
+					"stack":        string(stack),
+					"http_request": sanitizedSentryRequest(req, redactedHeaders, redactedFields),
+					"tags": raven.Tags{
+						{Key: "request_id", Value: rid},
+						{Key: "route", Value: route},
+					},
+				}
+
+				if userID := userIDExtractor(c); userID != "" {
+					fields["user_id"] = userID
+				}
+
+				logger.WithFields(fields).WithError(err).Error("panic recovered")
+
+				writeRecoveredResponse(c, opts.ResponseGenerator)
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+func writeRecoveredResponse(c echo.Context, generator APIResponseGenerator) {
+	if c.Response().Committed {
+		return
+	}
+
+	response := &StandardResponse{
+		Success: false,
+		Message: http.StatusText(http.StatusInternalServerError),
+		Status:  http.StatusInternalServerError,
+	}
+
+	switch {
+	case c.Request().Method == http.MethodHead:
+		helper.LogIfError(c.NoContent(response.Status))
+	case generator != nil:
+		helper.LogIfError(generator.GenerateEchoAPIResponse(c, response, nil))
+	default:
+		helper.LogIfError(c.JSON(response.Status, response))
+	}
+}
+
+// sanitizedSentryRequest builds the Sentry request interface without cookies, body and the sensitive headers and query
+func sanitizedSentryRequest(req *http.Request, redactedHeaders, redactedFields []string) *raven.Http {
+	headers := echomiddleware.RedactHeaders(req.Header, redactedHeaders)
+	delete(headers, echo.HeaderCookie)
+
+	query := req.URL.Query()
+	for k := range query {
+		for _, f := range redactedFields {
+			if strings.EqualFold(k, f) {
+				query.Set(k, echomiddleware.RedactedValue)
+			}
+		}
+	}
+
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	u := url.URL{Scheme: scheme, Host: req.Host, Path: req.URL.Path}
+
+	return &raven.Http{
+		URL:     u.String(),
+		Method:  req.Method,
+		Query:   query.Encode(),
+		Headers: headers,
+	}
+}
@@ -0,0 +1,83 @@
+package http_test
+
+import (
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getsentry/raven-go"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/http"
+	echomiddleware "github.com/sweet-go/stdlib/http/echo_middleware"
+)
+
+func TestRecover(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	ec := echo.New()
+	ec.Use(echomiddleware.RequestID(true), http.Recover(&http.RecoverOpts{Logger: logger}))
+	ec.GET("/users/:id", func(c echo.Context) error {
+		c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": "user-1"}})
+		panic("boom")
+	})
+	ec.GET("/error", func(c echo.Context) error {
+		panic(errors.New("typed error"))
+	})
+	ec.GET("/abort", func(c echo.Context) error {
+		panic(nethttp.ErrAbortHandler)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		hook.Reset()
+
+		req := httptest.NewRequest(nethttp.MethodGet, "/users/10?token=secret&page=1", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer abc")
+		req.Header.Set(echo.HeaderCookie, "session=abc")
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		assert.Equal(t, nethttp.StatusInternalServerError, rec.Code)
+
+		resp := &http.StandardResponse{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+		assert.False(t, resp.Success)
+		assert.Equal(t, nethttp.StatusInternalServerError, resp.Status)
+
+		entry := hook.LastEntry()
+		assert.Equal(t, logrus.ErrorLevel, entry.Level)
+		assert.EqualError(t, entry.Data[logrus.ErrorKey].(error), "boom")
+		assert.Equal(t, rec.Header().Get(echo.HeaderXRequestID), entry.Data["request_id"])
+		assert.Equal(t, "/users/:id", entry.Data["route"])
+		assert.Equal(t, "user-1", entry.Data["user_id"])
+		assert.Contains(t, entry.Data["stack"], "TestRecover")
+
+		sentryReq := entry.Data["http_request"].(*raven.Http)
+		assert.Equal(t, "http://example.com/users/10", sentryReq.URL)
+		assert.Equal(t, "page=1&token=%5BREDACTED%5D", sentryReq.Query)
+		assert.Equal(t, echomiddleware.RedactedValue, sentryReq.Headers[echo.HeaderAuthorization])
+		assert.NotContains(t, sentryReq.Headers, echo.HeaderCookie)
+	})
+
+	t.Run("error value", func(t *testing.T) {
+		hook.Reset()
+
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, httptest.NewRequest(nethttp.MethodGet, "/error", nil))
+
+		assert.Equal(t, nethttp.StatusInternalServerError, rec.Code)
+		assert.EqualError(t, hook.LastEntry().Data[logrus.ErrorKey].(error), "typed error")
+		assert.NotContains(t, hook.LastEntry().Data, "user_id")
+	})
+
+	t.Run("abort handler is not recovered", func(t *testing.T) {
+		assert.PanicsWithValue(t, nethttp.ErrAbortHandler, func() {
+			ec.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(nethttp.MethodGet, "/abort", nil))
+		})
+	})
+}
@@ -2,32 +2,76 @@
 package http
 
 import (
+	"context"
+	"crypto"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/sweet-go/stdlib/encryption"
+	"github.com/sweet-go/stdlib/helper"
+	echomiddleware "github.com/sweet-go/stdlib/http/echo_middleware"
 )
 
-// StandardResponse is a standard response for all API
+// StandardResponse is a standard response for all API.
+// Pagination and Links are only populated for list endpoints, see NewPaginatedResponse.
+// FieldErrors is only populated when the request fails validation, see Validator
 type StandardResponse struct {
-	Success bool   `json:"success,omitempty"`
-	Message string `json:"message,omitempty"`
-	Status  int    `json:"status,omitempty"`
-	Data    any    `json:"data,omitempty"`
-	Error   string `json:"error,omitempty"`
+	Success     bool             `json:"success,omitempty"`
+	Message     string           `json:"message,omitempty"`
+	Status      int              `json:"status,omitempty"`
+	Data        any              `json:"data,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	FieldErrors []FieldError     `json:"field_errors,omitempty"`
+	Pagination  *Pagination      `json:"pagination,omitempty"`
+	Links       *PaginationLinks `json:"links,omitempty"`
 }
 
-// APIResponse is a standard response for all API
+// APIResponse is a standard response for all API.
+// When generated by APIResponseGenerator, Response will hold the exact signed bytes of the
+// StandardResponse as json.RawMessage, so the bytes sent to the client are the bytes being signed.
+// Metadata is only present when the generator is created using NewSignedAPIResponseGenerator
+// and is also covered by the signature
 type APIResponse struct {
-	Response  any    `json:"response"`
-	Signature string `json:"signature"`
+	Response  any                `json:"response"`
+	Metadata  *SignatureMetadata `json:"metadata,omitempty"`
+	Signature string             `json:"signature"`
+}
+
+// SignatureMetadata is the signed metadata of APIResponse used by the client to
+// check the freshness of the response and to choose the key to verify the signature
+type SignatureMetadata struct {
+	// IssuedAt unix timestamp in seconds
+	IssuedAt int64 `json:"iat"`
+
+	// ExpiresAt unix timestamp in seconds. Zero means the response never expires
+	ExpiresAt int64  `json:"exp,omitempty"`
+	Nonce     string `json:"nonce"`
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// SignatureMetadataOpts is the options to generate SignatureMetadata
+type SignatureMetadataOpts struct {
+	KeyID string
+
+	// TTL is used to set SignatureMetadata.ExpiresAt. Zero means the response never expires
+	TTL time.Duration
+
+	// Now if nil, will use time.Now
+	Now func() time.Time
 }
 
 // APIResponseGenerator is an interface containing functionalities to generate standard API response
 type APIResponseGenerator interface {
 	GenerateAPIResponse(response *StandardResponse, opts *encryption.SignOpts) (*APIResponse, error)
 
+	// GenerateAPIResponseWithContext is the same as GenerateAPIResponse but will also put the request ID
+	// found in ctx to the signature metadata
+	GenerateAPIResponseWithContext(ctx context.Context, response *StandardResponse, opts *encryption.SignOpts) (*APIResponse, error)
+
 	// GenerateEchoAPIResponse is a function to generate API response with signature and send it to echo context also act as a wrapper for GenerateAPIResponse
 	// will use http status code the same as response.Status
 	GenerateEchoAPIResponse(c echo.Context, response *StandardResponse, opts *encryption.SignOpts) error
@@ -36,49 +80,204 @@ type APIResponseGenerator interface {
 // APIResponseGenerator is a struct containing functionalities to generate API response with signature
 type apiResponseGenerator struct {
 	defaultEncryptionOpts *encryption.SignOpts
+	metadataOpts          *SignatureMetadataOpts
+	encoders              []ResponseEncoder
+}
+
+// APIResponseGeneratorOpts is the options for NewAPIResponseGenerator
+type APIResponseGeneratorOpts struct {
+	// DefaultSignOpts is required
+	DefaultSignOpts *encryption.SignOpts
+
+	// MetadataOpts if not nil, will put signed SignatureMetadata to every generated response
+	MetadataOpts *SignatureMetadataOpts
+
+	// Encoders are the additional encodings negotiated by GenerateEchoAPIResponse, e.g. NewMsgpackEncoder
+	Encoders []ResponseEncoder
 }
 
 // NewStandardAPIResponseGenerator is a constructor for APIResponseGenerator
 func NewStandardAPIResponseGenerator(defaultEncryptionOpts *encryption.SignOpts) APIResponseGenerator {
+	return NewAPIResponseGenerator(&APIResponseGeneratorOpts{
+		DefaultSignOpts: defaultEncryptionOpts,
+	})
+}
+
+// NewSignedAPIResponseGenerator is a constructor for APIResponseGenerator which will also put signed SignatureMetadata
+// to every generated APIResponse. If metadataOpts is nil, will use the zero value
+func NewSignedAPIResponseGenerator(defaultEncryptionOpts *encryption.SignOpts, metadataOpts *SignatureMetadataOpts) APIResponseGenerator {
+	if metadataOpts == nil {
+		metadataOpts = &SignatureMetadataOpts{}
+	}
+
+	return NewAPIResponseGenerator(&APIResponseGeneratorOpts{
+		DefaultSignOpts: defaultEncryptionOpts,
+		MetadataOpts:    metadataOpts,
+	})
+}
+
+// NewAPIResponseGenerator is a constructor for APIResponseGenerator with full options
+func NewAPIResponseGenerator(opts *APIResponseGeneratorOpts) APIResponseGenerator {
 	return &apiResponseGenerator{
-		defaultEncryptionOpts: defaultEncryptionOpts,
+		defaultEncryptionOpts: opts.DefaultSignOpts,
+		metadataOpts:          opts.MetadataOpts,
+		encoders:              opts.Encoders,
 	}
 }
 
 // GenerateAPIResponse is a function to generate API response with signature.
 // If opts is nil, it will use defaultEncryptionOpts
 func (arg *apiResponseGenerator) GenerateAPIResponse(response *StandardResponse, opts *encryption.SignOpts) (*APIResponse, error) {
-	if opts == nil {
-		opts = arg.defaultEncryptionOpts
-	}
+	return arg.GenerateAPIResponseWithContext(context.Background(), response, opts)
+}
 
+func (arg *apiResponseGenerator) GenerateAPIResponseWithContext(ctx context.Context, response *StandardResponse, opts *encryption.SignOpts) (*APIResponse, error) {
 	respBytes, err := json.Marshal(response)
 	if err != nil {
 		return nil, err
 	}
 
-	signature, err := encryption.SignToBase64(respBytes, opts)
+	metadata, signature, err := arg.sign(ctx, respBytes, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	return &APIResponse{
-		Response:  response,
+		Response:  json.RawMessage(respBytes),
+		Metadata:  metadata,
 		Signature: signature,
 	}, nil
 }
 
+// GenerateEchoAPIResponse negotiates the response encoding based on Accept header. The APIResponse JSON envelope
+// is used by default, while RFC 7807 problem details (only for error response) and the registered encoders are
+// sent as is, with the signature of the exact body bytes put on HeaderSignature and HeaderSignatureMetadata
 func (arg *apiResponseGenerator) GenerateEchoAPIResponse(c echo.Context, response *StandardResponse, opts *encryption.SignOpts) error {
-	resp, err := arg.GenerateAPIResponse(response, opts)
+	req := c.Request()
+	isError := !response.Success && response.Status >= http.StatusBadRequest
+
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+
+	encoder := negotiateEncoder(req.Header.Get(echo.HeaderAccept), isError, arg.encoders)
+	if encoder == nil {
+		resp, err := arg.GenerateAPIResponseWithContext(req.Context(), response, opts)
+		if err != nil {
+			return generatorFailureResponse(c)
+		}
+
+		return c.JSON(response.Status, resp)
+	}
+
+	var payload any = response
+	if encoder.ContentType() == MIMEApplicationProblemJSON {
+		payload = NewProblemDetails(response, req.URL.RequestURI(), echomiddleware.GetRequestIDFromCtx(req.Context()))
+	}
+
+	body, err := encoder.Encode(payload)
+	if err != nil {
+		return generatorFailureResponse(c)
+	}
+
+	metadata, signature, err := arg.sign(req.Context(), body, opts)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, &StandardResponse{
-			Success: false,
-			Message: "server failed to generate API response",
-			Status:  http.StatusInternalServerError,
-			Data:    nil,
-			Error:   "api response generator for specific framework is experiencing error",
-		})
+		return generatorFailureResponse(c)
 	}
 
-	return c.JSON(response.Status, resp)
+	if metadata != nil {
+		metadataBytes, err := json.Marshal(metadata)
+		if err != nil {
+			return generatorFailureResponse(c)
+		}
+
+		c.Response().Header().Set(HeaderSignatureMetadata, string(metadataBytes))
+	}
+
+	c.Response().Header().Set(HeaderSignature, signature)
+
+	return c.Blob(response.Status, encoder.ContentType(), body)
+}
+
+// sign signs the payload alongside the generated signature metadata if enabled.
+// If opts is nil, it will use defaultEncryptionOpts
+func (arg *apiResponseGenerator) sign(ctx context.Context, payload []byte, opts *encryption.SignOpts) (*SignatureMetadata, string, error) {
+	if opts == nil {
+		opts = arg.defaultEncryptionOpts
+	}
+
+	signingInput := payload
+	metadata := arg.generateSignatureMetadata(ctx, opts)
+	if metadata != nil {
+		metadataBytes, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, "", err
+		}
+
+		signingInput = buildSigningInput(payload, metadataBytes)
+	}
+
+	signature, err := encryption.SignToBase64(signingInput, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return metadata, signature, nil
+}
+
+func generatorFailureResponse(c echo.Context) error {
+	return c.JSON(http.StatusInternalServerError, &StandardResponse{
+		Success: false,
+		Message: "server failed to generate API response",
+		Status:  http.StatusInternalServerError,
+		Data:    nil,
+		Error:   "api response generator for specific framework is experiencing error",
+	})
+}
+
+func (arg *apiResponseGenerator) generateSignatureMetadata(ctx context.Context, opts *encryption.SignOpts) *SignatureMetadata {
+	if arg.metadataOpts == nil {
+		return nil
+	}
+
+	now := time.Now
+	if arg.metadataOpts.Now != nil {
+		now = arg.metadataOpts.Now
+	}
+
+	issuedAt := now()
+	metadata := &SignatureMetadata{
+		IssuedAt:  issuedAt.Unix(),
+		Nonce:     helper.GenerateID(),
+		KeyID:     arg.metadataOpts.KeyID,
+		Algorithm: SignatureAlgorithm(opts.Alg),
+		RequestID: echomiddleware.GetRequestIDFromCtx(ctx),
+	}
+
+	if arg.metadataOpts.TTL > 0 {
+		metadata.ExpiresAt = issuedAt.Add(arg.metadataOpts.TTL).Unix()
+	}
+
+	return metadata
+}
+
+// SignatureAlgorithm returns the JWA name of RSA-PSS signature algorithm based on the hash used
+func SignatureAlgorithm(alg crypto.Hash) string {
+	switch alg {
+	case crypto.SHA256:
+		return "PS256"
+	case crypto.SHA384:
+		return "PS384"
+	case crypto.SHA512:
+		return "PS512"
+	default:
+		return "PSS-" + alg.String()
+	}
+}
+
+// buildSigningInput joins the response and metadata bytes using "." similar to JWS signing input
+func buildSigningInput(response, metadata []byte) []byte {
+	input := make([]byte, 0, len(response)+len(metadata)+1)
+	input = append(input, response...)
+	input = append(input, '.')
+
+	return append(input, metadata...)
 }
@@ -0,0 +1,247 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	echomiddleware "github.com/sweet-go/stdlib/http/echo_middleware"
+	"github.com/sweet-go/stdlib/worker"
+)
+
+// list of default server options
+const (
+	DefaultServerAddress         = ":8080"
+	DefaultHealthCheckTimeout    = 2 * time.Second
+	DefaultServerShutdownTimeout = 15 * time.Second
+)
+
+// Server is the http server bootstrap
+type Server interface {
+	// Echo returns the underlying echo instance to register the routes and additional middlewares
+	Echo() *echo.Echo
+
+	// Run starts the server and blocks until ctx is done or the shutdown signal is received,
+	// then gracefully shuts down the server. Returns nil if the server is shut down gracefully
+	Run(ctx context.Context) error
+
+	// Shutdown marks the server as not ready, waits for DrainDelay, stops accepting new requests
+	// and waits for the in flight requests to complete, then stops the workers and runs the shutdown hooks
+	Shutdown(ctx context.Context) error
+}
+
+// ServerOpts is the options for NewServer
+type ServerOpts struct {
+	// Address default to DefaultServerAddress
+	Address string
+
+	// Echo if nil, will create a new echo instance
+	Echo *echo.Echo
+
+	// DisableDefaultMiddlewares will skip registering the default RequestID, AccessLog and Recover middlewares
+	DisableDefaultMiddlewares bool
+
+	// AccessLog if nil, will log every request except the health check endpoints
+	AccessLog *echomiddleware.AccessLogOpts
+
+	// Recover is the options for the default Recover middleware
+	Recover *RecoverOpts
+
+	// ErrorHandler if nil, will use NewHTTPErrorHandler without any options, unless KeepErrorHandler is set
+	ErrorHandler echo.HTTPErrorHandler
+
+	// KeepErrorHandler keeps the error handler already set on Echo when ErrorHandler is nil
+	KeepErrorHandler bool
+
+	// LivenessCheckers are run by /healthz. Should only check the process itself,
+	// since failing liveness probe will restart the process
+	LivenessCheckers map[string]HealthChecker
+
+	// ReadinessCheckers are run by /readyz, e.g. PingChecker and RedisChecker
+	ReadinessCheckers map[string]HealthChecker
+
+	// HealthCheckTimeout default to DefaultHealthCheckTimeout
+	HealthCheckTimeout time.Duration
+
+	// ShutdownTimeout is the max duration to drain the in flight requests when the shutdown signal is received.
+	// Default to DefaultServerShutdownTimeout
+	ShutdownTimeout time.Duration
+
+	// DrainDelay is the duration between /readyz starts failing and the server stops accepting new requests,
+	// giving the load balancer time to stop routing traffic to this instance
+	DrainDelay time.Duration
+
+	// Workers are stopped after the http server is shut down
+	Workers []worker.Server
+
+	// ShutdownHooks are run in order after the workers are stopped, e.g. closing database connection
+	ShutdownHooks []func(ctx context.Context) error
+
+	// Signals default to SIGINT and SIGTERM
+	Signals []os.Signal
+}
+
+type server struct {
+	echo  *echo.Echo
+	opts  *ServerOpts
+	ready atomic.Bool
+}
+
+// NewServer creates http server bootstrap wiring the RequestID, AccessLog and Recover middlewares,
+// the error handler, and the /healthz and /readyz endpoints
+func NewServer(opts *ServerOpts) Server {
+	if opts == nil {
+		opts = &ServerOpts{}
+	}
+
+	ec := opts.Echo
+	if ec == nil {
+		ec = echo.New()
+	}
+
+	ec.HideBanner = true
+	ec.HidePort = true
+
+	switch {
+	case opts.ErrorHandler != nil:
+		ec.HTTPErrorHandler = opts.ErrorHandler
+	case !opts.KeepErrorHandler:
+		ec.HTTPErrorHandler = NewHTTPErrorHandler(nil)
+	}
+
+	if !opts.DisableDefaultMiddlewares {
+		accessLogOpts := opts.AccessLog
+		if accessLogOpts == nil {
+			accessLogOpts = &echomiddleware.AccessLogOpts{
+				Skipper: isHealthCheckRequest,
+			}
+		}
+
+		ec.Use(echomiddleware.RequestID(false), echomiddleware.AccessLog(accessLogOpts), Recover(opts.Recover))
+	}
+
+	healthCheckTimeout := opts.HealthCheckTimeout
+	if healthCheckTimeout <= 0 {
+		healthCheckTimeout = DefaultHealthCheckTimeout
+	}
+
+	s := &server{
+		echo: ec,
+		opts: opts,
+	}
+
+	ec.GET(HealthzPath, healthCheckHandler(opts.LivenessCheckers, healthCheckTimeout, nil))
+	ec.GET(ReadyzPath, healthCheckHandler(opts.ReadinessCheckers, healthCheckTimeout, s.ready.Load))
+
+	return s
+}
+
+func (s *server) Echo() *echo.Echo {
+	return s.echo
+}
+
+func (s *server) Run(ctx context.Context) error {
+	signals := s.opts.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, signals...)
+	defer stop()
+
+	address := s.opts.Address
+	if address == "" {
+		address = DefaultServerAddress
+	}
+
+	// bind the listener first, so the server is only ready once it accepts the connections
+	if s.echo.Listener == nil {
+		network := s.echo.ListenerNetwork
+		if network == "" {
+			network = "tcp"
+		}
+
+		listener, err := net.Listen(network, address)
+		if err != nil {
+			logrus.Error("failed to listen http server: ", err)
+			return err
+		}
+
+		s.echo.Listener = listener
+	}
+
+	errch := make(chan error, 1)
+	go func() {
+		logrus.Info("starting http server on ", s.echo.Listener.Addr())
+		if err := s.echo.Start(address); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errch <- err
+		}
+	}()
+
+	s.ready.Store(true)
+
+	select {
+	case err := <-errch:
+		s.ready.Store(false)
+		logrus.Error("http server stopped unexpectedly: ", err)
+		return err
+	case <-ctx.Done():
+		logrus.Info("shutdown signal received")
+	}
+
+	shutdownTimeout := s.opts.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultServerShutdownTimeout
+	}
+
+	// the parent ctx is already done, so the shutdown deadline must not derive from it
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout+s.opts.DrainDelay)
+	defer cancel()
+
+	return s.Shutdown(shutdownCtx)
+}
+
+func (s *server) Shutdown(ctx context.Context) error {
+	s.ready.Store(false)
+
+	if s.opts.DrainDelay > 0 {
+		logrus.Info("draining http server for ", s.opts.DrainDelay)
+		select {
+		case <-time.After(s.opts.DrainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	logrus.Info("stopping http server...")
+	err := s.echo.Shutdown(ctx)
+	if err != nil {
+		logrus.Error("failed to gracefully stop http server: ", err)
+	}
+
+	for _, w := range s.opts.Workers {
+		w.Stop()
+	}
+
+	for _, hook := range s.opts.ShutdownHooks {
+		if hookErr := hook(ctx); hookErr != nil {
+			logrus.Error("shutdown hook failed: ", hookErr)
+			err = errors.Join(err, hookErr)
+		}
+	}
+
+	logrus.Info("http server stopped.")
+
+	return err
+}
+
+func isHealthCheckRequest(c echo.Context) bool {
+	return c.Path() == HealthzPath || c.Path() == ReadyzPath
+}
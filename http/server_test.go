@@ -0,0 +1,198 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/http"
+	"github.com/sweet-go/stdlib/worker"
+	worker_mock "github.com/sweet-go/stdlib/worker/mock"
+)
+
+func TestServer_HealthCheck(t *testing.T) {
+	redisErr := errors.New("redis is down")
+	srv := http.NewServer(&http.ServerOpts{
+		LivenessCheckers: map[string]http.HealthChecker{
+			"process": func(ctx context.Context) error { return nil },
+		},
+		ReadinessCheckers: map[string]http.HealthChecker{
+			"postgres": func(ctx context.Context) error { return nil },
+			"redis":    func(ctx context.Context) error { return redisErr },
+			"stuck": func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			},
+		},
+		HealthCheckTimeout: 50 * time.Millisecond,
+	})
+
+	serve := func(target string) (*httptest.ResponseRecorder, *http.HealthCheckResult) {
+		rec := httptest.NewRecorder()
+		srv.Echo().ServeHTTP(rec, httptest.NewRequest(nethttp.MethodGet, target, nil))
+
+		resp := &struct {
+			Data *http.HealthCheckResult `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+
+		return rec, resp.Data
+	}
+
+	t.Run("liveness", func(t *testing.T) {
+		rec, result := serve(http.HealthzPath)
+
+		assert.Equal(t, nethttp.StatusOK, rec.Code)
+		assert.Equal(t, http.HealthStatusOK, result.Status)
+		assert.Equal(t, map[string]string{"process": http.HealthStatusOK}, result.Checks)
+		assert.NotEmpty(t, rec.Header().Get(echo.HeaderXRequestID))
+	})
+
+	t.Run("readiness", func(t *testing.T) {
+		start := time.Now()
+		rec, result := serve(http.ReadyzPath)
+		assert.Less(t, time.Since(start), 500*time.Millisecond, "must not wait for the stuck checker")
+
+		assert.Equal(t, nethttp.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, http.HealthStatusDown, result.Status)
+		assert.NotContains(t, rec.Body.String(), redisErr.Error())
+		assert.Equal(t, map[string]string{
+			"postgres": http.HealthStatusOK,
+			"redis":    http.HealthStatusDown,
+			"stuck":    http.HealthStatusTimeout,
+		}, result.Checks)
+	})
+
+	t.Run("recover from panic", func(t *testing.T) {
+		srv.Echo().GET("/panic", func(c echo.Context) error {
+			panic("boom")
+		})
+
+		rec := httptest.NewRecorder()
+		srv.Echo().ServeHTTP(rec, httptest.NewRequest(nethttp.MethodGet, "/panic", nil))
+
+		assert.Equal(t, nethttp.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestServer_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	w := worker_mock.NewMockServer(ctrl)
+
+	var hookCalled bool
+	srv := http.NewServer(&http.ServerOpts{
+		Address:    "127.0.0.1:0",
+		Workers:    []worker.Server{w},
+		DrainDelay: 10 * time.Millisecond,
+		ShutdownHooks: []func(ctx context.Context) error{
+			func(ctx context.Context) error {
+				hookCalled = true
+				return nil
+			},
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan error)
+	go func() {
+		done <- srv.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return srv.Echo().ListenerAddr() != nil
+	}, time.Second, 10*time.Millisecond)
+
+	resp, err := nethttp.Get("http://" + srv.Echo().ListenerAddr().String() + http.ReadyzPath)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, nethttp.StatusOK, resp.StatusCode)
+
+	w.EXPECT().Stop()
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server is not stopped")
+	}
+
+	assert.True(t, hookCalled)
+
+	rec := httptest.NewRecorder()
+	srv.Echo().ServeHTTP(rec, httptest.NewRequest(nethttp.MethodGet, http.ReadyzPath, nil))
+	assert.Equal(t, nethttp.StatusServiceUnavailable, rec.Code)
+}
+
+func TestServer_ErrorHandler(t *testing.T) {
+	serve := func(srv http.Server) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Echo().ServeHTTP(rec, httptest.NewRequest(nethttp.MethodGet, "/unknown", nil))
+		return rec
+	}
+
+	t.Run("keep error handler of given echo", func(t *testing.T) {
+		ec := echo.New()
+		ec.HTTPErrorHandler = func(err error, c echo.Context) {
+			_ = c.NoContent(nethttp.StatusTeapot)
+		}
+
+		rec := serve(http.NewServer(&http.ServerOpts{Echo: ec, KeepErrorHandler: true}))
+		assert.Equal(t, nethttp.StatusTeapot, rec.Code)
+	})
+
+	t.Run("replace echo default error handler", func(t *testing.T) {
+		def := httptest.NewRecorder()
+		echo.New().ServeHTTP(def, httptest.NewRequest(nethttp.MethodGet, "/unknown", nil))
+
+		rec := serve(http.NewServer(&http.ServerOpts{Echo: echo.New()}))
+		assert.Equal(t, nethttp.StatusNotFound, rec.Code)
+		assert.NotEqual(t, def.Body.String(), rec.Body.String())
+	})
+
+	t.Run("error handler option wins", func(t *testing.T) {
+		ec := echo.New()
+		ec.HTTPErrorHandler = func(err error, c echo.Context) {
+			_ = c.NoContent(nethttp.StatusTeapot)
+		}
+
+		rec := serve(http.NewServer(&http.ServerOpts{
+			Echo:             ec,
+			KeepErrorHandler: true,
+			ErrorHandler: func(err error, c echo.Context) {
+				_ = c.NoContent(nethttp.StatusConflict)
+			},
+		}))
+		assert.Equal(t, nethttp.StatusConflict, rec.Code)
+	})
+}
+
+func TestServer_RunListenError(t *testing.T) {
+	srv := httptest.NewServer(nethttp.NotFoundHandler())
+	defer srv.Close()
+
+	server := http.NewServer(&http.ServerOpts{Address: srv.Listener.Addr().String()})
+
+	done := make(chan error)
+	go func() {
+		done <- server.Run(context.TODO())
+	}()
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server must fail to listen")
+	}
+
+	rec := httptest.NewRecorder()
+	server.Echo().ServeHTTP(rec, httptest.NewRequest(nethttp.MethodGet, http.ReadyzPath, nil))
+	assert.Equal(t, nethttp.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/sweet-go/stdlib/http/echo_middleware"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDTransport is a http.RoundTripper forwarding the request ID found in the request context
+// as X-Request-ID header, so the downstream services can log using the same request ID
+type RequestIDTransport struct {
+	// Base if nil, will use http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// RoundTrip sets X-Request-ID header if not already set, then executes the request using Base
+func (rt *RequestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := rt.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	rid := echomiddleware.GetRequestIDFromCtx(req.Context())
+	if rid == "" || req.Header.Get(echo.HeaderXRequestID) != "" {
+		return base.RoundTrip(req)
+	}
+
+	// RoundTripper must not modify the original request
+	clone := req.Clone(req.Context())
+	clone.Header.Set(echo.HeaderXRequestID, rid)
+
+	return base.RoundTrip(clone)
+}
+
+// NewRequestIDClient creates a copy of client which transport will forward the request ID.
+// If client is nil, will use http.DefaultClient
+func NewRequestIDClient(client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	c := *client
+	c.Transport = &RequestIDTransport{
+		Base: client.Transport,
+	}
+
+	return &c
+}
+
+// TracingTransport is a http.RoundTripper creating a client span for every request
+// and propagating the W3C trace context to the downstream services
+type TracingTransport struct {
+	// Base if nil, will use http.DefaultTransport
+	Base http.RoundTripper
+
+	// TracerProvider if nil, will use the OpenTelemetry global tracer provider
+	TracerProvider trace.TracerProvider
+}
+
+// RoundTrip starts client span as the child of the span found in the request context, injects the trace context
+// headers, then executes the request using Base
+func (rt *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := rt.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx, span := tracing.Tracer(rt.TracerProvider).Start(req.Context(), fmt.Sprintf("HTTP %s", req.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(httpconv.ClientRequest(req)...),
+	)
+	defer span.End()
+
+	// RoundTripper must not modify the original request
+	clone := req.Clone(ctx)
+	tracing.Propagator().Inject(ctx, propagation.HeaderCarrier(clone.Header))
+
+	res, err := base.RoundTrip(clone)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(httpconv.ClientResponse(res)...)
+	span.SetStatus(httpconv.ClientStatus(res.StatusCode))
+
+	return res, nil
+}
+
+// NewTracingClient creates a copy of client which transport will trace the requests.
+// If client is nil, will use http.DefaultClient. If tp is nil, will use the OpenTelemetry global tracer provider
+func NewTracingClient(client *http.Client, tp trace.TracerProvider) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	c := *client
+	c.Transport = &TracingTransport{
+		Base:           client.Transport,
+		TracerProvider: tp,
+	}
+
+	return &c
+}
@@ -0,0 +1,93 @@
+package http_test
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/http"
+	echomiddleware "github.com/sweet-go/stdlib/http/echo_middleware"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequestIDClient(t *testing.T) {
+	var received string
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		received = r.Header.Get(echo.HeaderXRequestID)
+		w.WriteHeader(nethttp.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client := http.NewRequestIDClient(srv.Client())
+
+	t.Run("forward request id", func(t *testing.T) {
+		ctx := echomiddleware.ContextWithRequestID(context.TODO(), "request-id")
+		req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, srv.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, "request-id", received)
+		assert.Empty(t, req.Header.Get(echo.HeaderXRequestID))
+	})
+
+	t.Run("keep existing header", func(t *testing.T) {
+		ctx := echomiddleware.ContextWithRequestID(context.TODO(), "request-id")
+		req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, srv.URL, nil)
+		assert.NoError(t, err)
+		req.Header.Set(echo.HeaderXRequestID, "explicit-id")
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, "explicit-id", received)
+	})
+
+	t.Run("no request id", func(t *testing.T) {
+		resp, err := client.Get(srv.URL)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+
+		assert.Empty(t, received)
+	})
+}
+
+func TestTracingClient(t *testing.T) {
+	tp, exporter := tracing.NewInMemoryTracerProvider("test-service")
+
+	var received string
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		received = r.Header.Get(echomiddleware.HeaderTraceParent)
+		w.WriteHeader(nethttp.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	client := http.NewTracingClient(srv.Client(), tp)
+
+	ctx, parent := tracing.Tracer(tp).Start(context.TODO(), "parent")
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, srv.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	parent.End()
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "HTTP GET", spans[0].Name)
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+
+	assert.Contains(t, received, spans[0].SpanContext.SpanID().String())
+	assert.Empty(t, req.Header.Get(echomiddleware.HeaderTraceParent))
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+	"github.com/labstack/echo/v4"
+)
+
+// Locale is the language used to render validation messages
+type Locale string
+
+// list of supported locales
+const (
+	LocaleEnglish    Locale = "en"
+	LocaleIndonesian Locale = "id"
+)
+
+// FieldError is a single field validation failure to be rendered in StandardResponse.FieldErrors
+type FieldError struct {
+	// Field is the field name taken from json tag, fallback to the struct field name
+	Field string `json:"field"`
+
+	// Code is the failing validation tag, e.g. required, email, min
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by Validator when the struct fails validation
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error returns all field messages joined by "; "
+func (ve *ValidationError) Error() string {
+	messages := make([]string, 0, len(ve.Fields))
+	for _, f := range ve.Fields {
+		messages = append(messages, f.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Validator validates struct based on `validate` struct tags and translates the failures
+// into FieldError. Satisfy echo.Validator so it can be used as echo.Echo.Validator
+type Validator struct {
+	validate      *validator.Validate
+	translator    *ut.UniversalTranslator
+	defaultLocale Locale
+}
+
+// NewValidator creates a new Validator with English and Indonesian messages registered.
+// defaultLocale is used by Validate and when the request locale is not supported
+func NewValidator(defaultLocale Locale) (*Validator, error) {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+
+		if name == "" {
+			return field.Name
+		}
+
+		return name
+	})
+
+	translator := ut.New(en.New(), en.New(), id.New())
+
+	enTrans, _ := translator.GetTranslator(string(LocaleEnglish))
+	if err := en_translations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		return nil, err
+	}
+
+	idTrans, _ := translator.GetTranslator(string(LocaleIndonesian))
+	if err := id_translations.RegisterDefaultTranslations(validate, idTrans); err != nil {
+		return nil, err
+	}
+
+	return &Validator{
+		validate:      validate,
+		translator:    translator,
+		defaultLocale: defaultLocale,
+	}, nil
+}
+
+// Engine returns the underlying validator to register custom validations
+func (v *Validator) Engine() *validator.Validate {
+	return v.validate
+}
+
+// RegisterMessageTemplate overrides or adds the message template of a validation tag for the locale.
+// Use {0} as the field name placeholder and {1} as the validation param placeholder,
+// e.g. "{0} must be at least {1} characters"
+func (v *Validator) RegisterMessageTemplate(locale Locale, tag, template string) error {
+	trans, found := v.translator.GetTranslator(string(locale))
+	if !found {
+		return errors.New("validation error: unsupported locale " + string(locale))
+	}
+
+	return v.validate.RegisterTranslation(tag, trans, func(ut ut.Translator) error {
+		return ut.Add(tag, template, true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		msg, err := ut.T(fe.Tag(), fe.Field(), fe.Param())
+		if err != nil {
+			return fe.Error()
+		}
+
+		return msg
+	})
+}
+
+// Validate validates the struct using the default locale. Return *ValidationError if the struct is invalid
+func (v *Validator) Validate(i any) error {
+	return v.ValidateWithLocale(i, v.defaultLocale)
+}
+
+// ValidateWithLocale validates the struct and translate the failures using the supplied locale.
+// Return *ValidationError if the struct is invalid
+func (v *Validator) ValidateWithLocale(i any, locale Locale) error {
+	err := v.validate.Struct(i)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	trans, found := v.translator.GetTranslator(string(locale))
+	if !found {
+		trans, _ = v.translator.GetTranslator(string(v.defaultLocale))
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Code:    fe.Tag(),
+			Message: fe.Translate(trans),
+		})
+	}
+
+	return &ValidationError{
+		Fields: fields,
+	}
+}
+
+// BindAndValidate binds the request into dest using echo binder then validates it
+// using the locale found on Accept-Language header
+func (v *Validator) BindAndValidate(c echo.Context, dest any) error {
+	if err := c.Bind(dest); err != nil {
+		return err
+	}
+
+	return v.ValidateWithLocale(dest, LocaleFromRequest(c.Request(), v.defaultLocale))
+}
+
+// LocaleFromRequest returns the first supported locale found on Accept-Language header, otherwise return fallback
+func LocaleFromRequest(req *http.Request, fallback Locale) Locale {
+	for _, accepted := range parseQualityValues(req.Header.Get("Accept-Language")) {
+		lang, _, _ := strings.Cut(accepted.value, "-")
+		switch Locale(lang) {
+		case LocaleEnglish, LocaleIndonesian:
+			return Locale(lang)
+		}
+	}
+
+	return fallback
+}
+
+func fieldErrorsFromValidator(validationErrors validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Code:    fe.Tag(),
+			Message: fe.Error(),
+		})
+	}
+
+	return fields
+}
@@ -0,0 +1,131 @@
+package http_test
+
+import (
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/http"
+)
+
+type registerInput struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"min=8"`
+}
+
+func TestValidator(t *testing.T) {
+	v, err := http.NewValidator(http.LocaleEnglish)
+	assert.NoError(t, err)
+
+	t.Run("ok", func(t *testing.T) {
+		err := v.Validate(&registerInput{
+			Name:     "name",
+			Email:    "user@mail.test",
+			Password: "password",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("field errors in english", func(t *testing.T) {
+		err := v.Validate(&registerInput{
+			Email:    "not email",
+			Password: "short",
+		})
+
+		var validationErr *http.ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, []http.FieldError{
+			{Field: "name", Code: "required", Message: "name is a required field"},
+			{Field: "email", Code: "email", Message: "email must be a valid email address"},
+			{Field: "password", Code: "min", Message: "password must be at least 8 characters in length"},
+		}, validationErr.Fields)
+	})
+
+	t.Run("field errors in indonesian", func(t *testing.T) {
+		err := v.ValidateWithLocale(&registerInput{Email: "user@mail.test", Password: "password"}, http.LocaleIndonesian)
+
+		var validationErr *http.ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Len(t, validationErr.Fields, 1)
+		assert.Equal(t, "name wajib diisi", validationErr.Fields[0].Message)
+	})
+
+	t.Run("custom message template", func(t *testing.T) {
+		v, err := http.NewValidator(http.LocaleIndonesian)
+		assert.NoError(t, err)
+
+		err = v.RegisterMessageTemplate(http.LocaleIndonesian, "required", "{0} tidak boleh kosong")
+		assert.NoError(t, err)
+
+		err = v.Validate(&registerInput{Email: "user@mail.test", Password: "password"})
+		assert.EqualError(t, err, "name tidak boleh kosong")
+
+		err = v.RegisterMessageTemplate(http.Locale("fr"), "required", "{0} est requis")
+		assert.Error(t, err)
+	})
+}
+
+func TestLocaleFromRequest(t *testing.T) {
+	req := httptest.NewRequest(nethttp.MethodGet, "/", nil)
+	assert.Equal(t, http.LocaleEnglish, http.LocaleFromRequest(req, http.LocaleEnglish))
+
+	req.Header.Set("Accept-Language", "fr-CH, id-ID;q=0.9, en;q=0.8")
+	assert.Equal(t, http.LocaleIndonesian, http.LocaleFromRequest(req, http.LocaleEnglish))
+
+	req.Header.Set("Accept-Language", "id;q=0.5, en-US")
+	assert.Equal(t, http.LocaleEnglish, http.LocaleFromRequest(req, http.LocaleIndonesian))
+}
+
+func TestValidator_BindAndValidate(t *testing.T) {
+	v, err := http.NewValidator(http.LocaleEnglish)
+	assert.NoError(t, err)
+
+	ec := echo.New()
+	ec.Validator = v
+	ec.HTTPErrorHandler = http.NewHTTPErrorHandler(nil)
+	ec.POST("/register", func(c echo.Context) error {
+		input := &registerInput{}
+		if err := v.BindAndValidate(c, input); err != nil {
+			return err
+		}
+
+		return c.NoContent(nethttp.StatusCreated)
+	})
+
+	serve := func(body, lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(nethttp.MethodPost, "/register", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		ec.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		rec := serve(`{"name":"name","email":"user@mail.test","password":"password"}`, "")
+		assert.Equal(t, nethttp.StatusCreated, rec.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		rec := serve(`{"name":`, "")
+		assert.Equal(t, nethttp.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("validation failed", func(t *testing.T) {
+		rec := serve(`{"email":"user@mail.test","password":"password"}`, "id")
+		assert.Equal(t, nethttp.StatusBadRequest, rec.Code)
+
+		res := &http.StandardResponse{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+		assert.Equal(t, []http.FieldError{
+			{Field: "name", Code: "required", Message: "name wajib diisi"},
+		}, res.FieldErrors)
+	})
+}
@@ -0,0 +1,381 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sweet-go/stdlib/encryption"
+)
+
+var (
+	// ErrMalformedAPIResponse is returned when the response body is not a valid APIResponse envelope
+	ErrMalformedAPIResponse = errors.New("api response error: malformed signed response")
+
+	// ErrInvalidSignature is returned when the APIResponse signature doesn't match the signed response
+	ErrInvalidSignature = errors.New("api response error: invalid signature")
+
+	// ErrMissingSignatureMetadata is returned when the signature metadata is required but not found
+	ErrMissingSignatureMetadata = errors.New("api response error: missing signature metadata")
+
+	// ErrStaleAPIResponse is returned when the signed response is expired, too old or issued in the future
+	ErrStaleAPIResponse = errors.New("api response error: stale signed response")
+
+	// ErrUnknownKeyID is returned when no verification key is found for the signature metadata key ID
+	ErrUnknownKeyID = errors.New("api response error: unknown key id")
+
+	// ErrSignatureAlgorithmMismatch is returned when the signature metadata algorithm is not the one of the verification key
+	ErrSignatureAlgorithmMismatch = errors.New("api response error: signature algorithm mismatch")
+
+	// ErrReplayedAPIResponse is returned when the signature metadata nonce is already seen by the NonceChecker
+	ErrReplayedAPIResponse = errors.New("api response error: replayed signed response")
+
+	// ErrMissingVerifyOpts is returned when the verification options are not supplied
+	ErrMissingVerifyOpts = errors.New("api response error: missing verify options")
+)
+
+// NonceChecker rejects the replayed signed responses by their signature metadata nonce
+type NonceChecker interface {
+	// CheckNonce returns ErrReplayedAPIResponse if the nonce is already seen, otherwise remembers it until expiresAt.
+	// Zero expiresAt means the nonce must be remembered forever
+	CheckNonce(ctx context.Context, nonce string, expiresAt time.Time) error
+}
+
+// APIResponseVerifyOpts is the options to verify the APIResponse
+type APIResponseVerifyOpts struct {
+	// VerifyOpts is required if Keys is empty.
+	// If Keys is not empty, will be used when the signature metadata has no key ID
+	VerifyOpts *encryption.VerifyOpts
+
+	// Keys verification options by the SignatureMetadata.KeyID
+	Keys map[string]*encryption.VerifyOpts
+
+	// AllowMissingMetadata accepts responses without signature metadata, e.g. generated by NewStandardAPIResponseGenerator.
+	// Such responses are not checked for freshness nor replay, so they verify forever
+	AllowMissingMetadata bool
+
+	// NonceChecker enables rejecting the replayed responses when not nil. The nonce is remembered until
+	// the response expires, or is older than MaxAge when the response never expires
+	NonceChecker NonceChecker
+
+	// MaxAge rejects responses issued more than MaxAge ago. Zero means only ExpiresAt will be checked
+	MaxAge time.Duration
+
+	// ClockSkew is the tolerance applied when comparing the metadata timestamps
+	ClockSkew time.Duration
+
+	// Now if nil, will use time.Now
+	Now func() time.Time
+}
+
+// VerifiedAPIResponse is the result of successful APIResponse verification
+type VerifiedAPIResponse struct {
+	// Response is the verified StandardResponse bytes to be unmarshalled by the caller
+	Response json.RawMessage
+
+	// Metadata will be nil if the response has no signature metadata
+	Metadata *SignatureMetadata
+}
+
+type rawAPIResponse struct {
+	Response  json.RawMessage `json:"response"`
+	Metadata  json.RawMessage `json:"metadata"`
+	Signature string          `json:"signature"`
+}
+
+// ExtractSignedAPIResponse extracts the exact signed bytes and the decoded signature from raw APIResponse body.
+// The signed bytes are compacted, so indentation added by the server (e.g. echo pretty print) doesn't affect the signature
+func ExtractSignedAPIResponse(body []byte) (signed []byte, signature []byte, err error) {
+	signed, _, _, signature, err = parseAPIResponse(body)
+	return
+}
+
+// VerifyAPIResponse verifies the raw APIResponse body generated by APIResponseGenerator.
+// Returning the verified StandardResponse bytes to be unmarshalled by the caller.
+// The response without signature metadata is accepted, so the captured response can be replayed.
+// Use VerifyAPIResponseWithOpts to require the signature metadata and enforce its freshness
+func VerifyAPIResponse(body []byte, opts *encryption.VerifyOpts) (json.RawMessage, error) {
+	verified, err := VerifyAPIResponseWithOpts(body, &APIResponseVerifyOpts{
+		VerifyOpts:           opts,
+		AllowMissingMetadata: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return verified.Response, nil
+}
+
+// VerifyAPIResponseWithOpts verifies the raw APIResponse body including the signature metadata freshness.
+// The response without signature metadata is rejected unless AllowMissingMetadata is set
+func VerifyAPIResponseWithOpts(body []byte, opts *APIResponseVerifyOpts) (*VerifiedAPIResponse, error) {
+	return verifyAPIResponse(context.Background(), body, opts)
+}
+
+func verifyAPIResponse(ctx context.Context, body []byte, opts *APIResponseVerifyOpts) (*VerifiedAPIResponse, error) {
+	if opts == nil {
+		return nil, ErrMissingVerifyOpts
+	}
+
+	signed, response, metadata, signature, err := parseAPIResponse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := opts.verify(ctx, signed, signature, metadata); err != nil {
+		return nil, err
+	}
+
+	return &VerifiedAPIResponse{
+		Response: response,
+		Metadata: metadata,
+	}, nil
+}
+
+// VerifyEncodedResponse verifies the response body sent as is by GenerateEchoAPIResponse, e.g. RFC 7807 problem details
+// or the registered encoders, using the signature found on HeaderSignature and HeaderSignatureMetadata.
+// The returned VerifiedAPIResponse.Response is the exact body bytes to be decoded using the response content type
+func VerifyEncodedResponse(body []byte, header http.Header, opts *APIResponseVerifyOpts) (*VerifiedAPIResponse, error) {
+	return verifyEncodedResponse(context.Background(), body, header, opts)
+}
+
+func verifyEncodedResponse(ctx context.Context, body []byte, header http.Header, opts *APIResponseVerifyOpts) (*VerifiedAPIResponse, error) {
+	if opts == nil {
+		return nil, ErrMissingVerifyOpts
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(header.Get(HeaderSignature))
+	if err != nil || len(signature) == 0 {
+		return nil, ErrMalformedAPIResponse
+	}
+
+	signed := body
+	var metadata *SignatureMetadata
+	if rawMetadata := header.Get(HeaderSignatureMetadata); rawMetadata != "" {
+		metadata = &SignatureMetadata{}
+		if err := json.Unmarshal([]byte(rawMetadata), metadata); err != nil {
+			return nil, ErrMalformedAPIResponse
+		}
+
+		signed = buildSigningInput(body, []byte(rawMetadata))
+	}
+
+	if err := opts.verify(ctx, signed, signature, metadata); err != nil {
+		return nil, err
+	}
+
+	return &VerifiedAPIResponse{
+		Response: body,
+		Metadata: metadata,
+	}, nil
+}
+
+// DecodeVerifiedAPIResponse wrapper for VerifyAPIResponse then unmarshal the verified StandardResponse into dest
+func DecodeVerifiedAPIResponse(body []byte, opts *encryption.VerifyOpts, dest any) error {
+	signed, err := VerifyAPIResponse(body, opts)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(signed, dest)
+}
+
+// VerifyingTransport is a http.RoundTripper rejecting every response which signature is invalid
+type VerifyingTransport struct {
+	// Base if nil, will use http.DefaultTransport
+	Base http.RoundTripper
+	Opts *APIResponseVerifyOpts
+}
+
+// RoundTrip executes the request using Base and verifies the response body.
+// Responses having HeaderSignature are verified using VerifyEncodedResponse, otherwise using VerifyAPIResponseWithOpts.
+// The response body can still be read by the caller after verification
+func (vt *VerifyingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := vt.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Header.Get(HeaderSignature) != "" {
+		_, err = verifyEncodedResponse(req.Context(), body, resp.Header, vt.Opts)
+	} else {
+		_, err = verifyAPIResponse(req.Context(), body, vt.Opts)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+// NewVerifyingClient creates a copy of client which transport will reject responses with bad signatures
+// or failing the freshness check. If client is nil, will use http.DefaultClient
+func NewVerifyingClient(client *http.Client, opts *APIResponseVerifyOpts) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	c := *client
+	c.Transport = &VerifyingTransport{
+		Base: client.Transport,
+		Opts: opts,
+	}
+
+	return &c
+}
+
+// verify checks the signature, then the signature metadata algorithm, freshness and nonce
+func (avo *APIResponseVerifyOpts) verify(ctx context.Context, signed, signature []byte, metadata *SignatureMetadata) error {
+	if metadata == nil && !avo.AllowMissingMetadata {
+		return ErrMissingSignatureMetadata
+	}
+
+	verifyOpts, err := avo.resolveKey(metadata)
+	if err != nil {
+		return err
+	}
+
+	if metadata != nil && metadata.Algorithm != SignatureAlgorithm(verifyOpts.Alg) {
+		return ErrSignatureAlgorithmMismatch
+	}
+
+	if err := encryption.Verify(signed, signature, verifyOpts); err != nil {
+		return ErrInvalidSignature
+	}
+
+	if err := avo.checkFreshness(metadata); err != nil {
+		return err
+	}
+
+	return avo.checkNonce(ctx, metadata)
+}
+
+func (avo *APIResponseVerifyOpts) resolveKey(metadata *SignatureMetadata) (*encryption.VerifyOpts, error) {
+	if metadata == nil || metadata.KeyID == "" || len(avo.Keys) == 0 {
+		if avo.VerifyOpts == nil {
+			return nil, ErrUnknownKeyID
+		}
+
+		return avo.VerifyOpts, nil
+	}
+
+	opts, ok := avo.Keys[metadata.KeyID]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	return opts, nil
+}
+
+func (avo *APIResponseVerifyOpts) checkFreshness(metadata *SignatureMetadata) error {
+	if metadata == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if avo.Now != nil {
+		now = avo.Now()
+	}
+
+	issuedAt := time.Unix(metadata.IssuedAt, 0)
+	if issuedAt.After(now.Add(avo.ClockSkew)) {
+		return ErrStaleAPIResponse
+	}
+
+	if metadata.ExpiresAt > 0 && now.Add(-avo.ClockSkew).After(time.Unix(metadata.ExpiresAt, 0)) {
+		return ErrStaleAPIResponse
+	}
+
+	if avo.MaxAge > 0 && now.Add(-avo.ClockSkew).After(issuedAt.Add(avo.MaxAge)) {
+		return ErrStaleAPIResponse
+	}
+
+	return nil
+}
+
+func (avo *APIResponseVerifyOpts) checkNonce(ctx context.Context, metadata *SignatureMetadata) error {
+	if metadata == nil || avo.NonceChecker == nil {
+		return nil
+	}
+
+	if metadata.Nonce == "" {
+		return ErrMalformedAPIResponse
+	}
+
+	var expiresAt time.Time
+	switch {
+	case metadata.ExpiresAt > 0:
+		expiresAt = time.Unix(metadata.ExpiresAt, 0).Add(avo.ClockSkew)
+	case avo.MaxAge > 0:
+		expiresAt = time.Unix(metadata.IssuedAt, 0).Add(avo.MaxAge + avo.ClockSkew)
+	}
+
+	return avo.NonceChecker.CheckNonce(ctx, metadata.Nonce, expiresAt)
+}
+
+// parseAPIResponse returns the signing input, the compacted response, the decoded metadata and signature
+func parseAPIResponse(body []byte) (signed, response json.RawMessage, metadata *SignatureMetadata, signature []byte, err error) {
+	raw := &rawAPIResponse{}
+	if err := json.Unmarshal(body, raw); err != nil {
+		return nil, nil, nil, nil, ErrMalformedAPIResponse
+	}
+
+	if len(raw.Response) == 0 || raw.Signature == "" {
+		return nil, nil, nil, nil, ErrMalformedAPIResponse
+	}
+
+	response, err = compactJSON(raw.Response)
+	if err != nil {
+		return nil, nil, nil, nil, ErrMalformedAPIResponse
+	}
+
+	signed = response
+	if len(raw.Metadata) > 0 && !bytes.Equal(raw.Metadata, []byte("null")) {
+		compacted, err := compactJSON(raw.Metadata)
+		if err != nil {
+			return nil, nil, nil, nil, ErrMalformedAPIResponse
+		}
+
+		metadata = &SignatureMetadata{}
+		if err := json.Unmarshal(compacted, metadata); err != nil {
+			return nil, nil, nil, nil, ErrMalformedAPIResponse
+		}
+
+		signed = buildSigningInput(response, compacted)
+	}
+
+	signature, err = base64.StdEncoding.DecodeString(raw.Signature)
+	if err != nil {
+		return nil, nil, nil, nil, ErrMalformedAPIResponse
+	}
+
+	return signed, response, metadata, signature, nil
+}
+
+func compactJSON(raw json.RawMessage) (json.RawMessage, error) {
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, raw); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
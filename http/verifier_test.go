@@ -0,0 +1,328 @@
+package http_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/cacher"
+	"github.com/sweet-go/stdlib/encryption"
+	"github.com/sweet-go/stdlib/http"
+	echomiddleware "github.com/sweet-go/stdlib/http/echo_middleware"
+)
+
+func TestVerifyAPIResponse(t *testing.T) {
+	key, err := encryption.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	signer := http.NewStandardAPIResponseGenerator(&encryption.SignOpts{
+		Random:  rand.Reader,
+		PrivKey: key,
+		Alg:     crypto.SHA256,
+	})
+
+	verifyOpts := &encryption.VerifyOpts{
+		PublicKey: &key.PublicKey,
+		Alg:       crypto.SHA256,
+	}
+
+	response := &http.StandardResponse{
+		Success: true,
+		Message: "Success <html> & friends",
+		Status:  nethttp.StatusOK,
+		Data:    map[string]any{"b": 1.5, "a": []string{"x", "y"}},
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		resp, err := signer.GenerateAPIResponse(response, nil)
+		assert.NoError(t, err)
+
+		body, err := json.Marshal(resp)
+		assert.NoError(t, err)
+
+		res := &http.StandardResponse{}
+		err = http.DecodeVerifiedAPIResponse(body, verifyOpts, res)
+		assert.NoError(t, err)
+		assert.Equal(t, response.Message, res.Message)
+		assert.Equal(t, response.Status, res.Status)
+	})
+
+	t.Run("ok - indented body", func(t *testing.T) {
+		resp, err := signer.GenerateAPIResponse(response, nil)
+		assert.NoError(t, err)
+
+		body, err := json.MarshalIndent(resp, "", "  ")
+		assert.NoError(t, err)
+
+		_, err = http.VerifyAPIResponse(body, verifyOpts)
+		assert.NoError(t, err)
+	})
+
+	t.Run("tampered response", func(t *testing.T) {
+		resp, err := signer.GenerateAPIResponse(response, nil)
+		assert.NoError(t, err)
+
+		body, err := json.Marshal(resp)
+		assert.NoError(t, err)
+
+		tampered := strings.Replace(string(body), `"status":200`, `"status":201`, 1)
+		_, err = http.VerifyAPIResponse([]byte(tampered), verifyOpts)
+		assert.ErrorIs(t, err, http.ErrInvalidSignature)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		_, err := http.VerifyAPIResponse([]byte(`{"success":true}`), verifyOpts)
+		assert.ErrorIs(t, err, http.ErrMalformedAPIResponse)
+
+		_, err = http.VerifyAPIResponse([]byte(`not json`), verifyOpts)
+		assert.ErrorIs(t, err, http.ErrMalformedAPIResponse)
+
+		_, err = http.VerifyAPIResponse([]byte(`{"response":{},"signature":"%%%"}`), verifyOpts)
+		assert.ErrorIs(t, err, http.ErrMalformedAPIResponse)
+	})
+}
+
+func TestVerifyingClient(t *testing.T) {
+	key, err := encryption.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	otherKey, err := encryption.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	signer := http.NewStandardAPIResponseGenerator(&encryption.SignOpts{
+		Random:  rand.Reader,
+		PrivKey: key,
+		Alg:     crypto.SHA256,
+	})
+
+	ec := echo.New()
+	ec.GET("/", func(c echo.Context) error {
+		return signer.GenerateEchoAPIResponse(c, &http.StandardResponse{
+			Success: true,
+			Message: "Success",
+			Status:  nethttp.StatusOK,
+			Data:    map[string]string{"message": "Hello World"},
+		}, nil)
+	})
+
+	srv := httptest.NewServer(ec)
+	defer srv.Close()
+
+	t.Run("ok", func(t *testing.T) {
+		client := http.NewVerifyingClient(srv.Client(), &http.APIResponseVerifyOpts{
+			VerifyOpts: &encryption.VerifyOpts{
+				PublicKey: &key.PublicKey,
+				Alg:       crypto.SHA256,
+			},
+			AllowMissingMetadata: true,
+		})
+
+		resp, err := client.Get(srv.URL + "/?pretty")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), "Hello World")
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		client := http.NewVerifyingClient(srv.Client(), &http.APIResponseVerifyOpts{
+			VerifyOpts: &encryption.VerifyOpts{
+				PublicKey: &otherKey.PublicKey,
+				Alg:       crypto.SHA256,
+			},
+			AllowMissingMetadata: true,
+		})
+
+		_, err := client.Get(srv.URL)
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, http.ErrInvalidSignature))
+	})
+}
+
+func TestVerifyAPIResponseWithOpts(t *testing.T) {
+	key, err := encryption.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	signOpts := &encryption.SignOpts{
+		Random:  rand.Reader,
+		PrivKey: key,
+		Alg:     crypto.SHA512,
+	}
+
+	verifyOpts := &encryption.VerifyOpts{
+		PublicKey: &key.PublicKey,
+		Alg:       crypto.SHA512,
+	}
+
+	issuedAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	signer := http.NewSignedAPIResponseGenerator(signOpts, &http.SignatureMetadataOpts{
+		KeyID: "key-1",
+		TTL:   time.Minute,
+		Now:   func() time.Time { return issuedAt },
+	})
+
+	response := &http.StandardResponse{
+		Success: true,
+		Message: "Success",
+		Status:  nethttp.StatusOK,
+	}
+
+	generate := func(t *testing.T) []byte {
+		ctx := context.WithValue(context.TODO(), echomiddleware.ReqIDCtxKey, "request-id")
+		resp, err := signer.GenerateAPIResponseWithContext(ctx, response, nil)
+		assert.NoError(t, err)
+
+		body, err := json.Marshal(resp)
+		assert.NoError(t, err)
+
+		return body
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		res, err := http.VerifyAPIResponseWithOpts(generate(t), &http.APIResponseVerifyOpts{
+			Keys: map[string]*encryption.VerifyOpts{"key-1": verifyOpts},
+			Now:  func() time.Time { return issuedAt.Add(30 * time.Second) },
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "key-1", res.Metadata.KeyID)
+		assert.Equal(t, "PS512", res.Metadata.Algorithm)
+		assert.Equal(t, "request-id", res.Metadata.RequestID)
+		assert.Equal(t, issuedAt.Add(time.Minute).Unix(), res.Metadata.ExpiresAt)
+		assert.NotEmpty(t, res.Metadata.Nonce)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, err := http.VerifyAPIResponseWithOpts(generate(t), &http.APIResponseVerifyOpts{
+			VerifyOpts: verifyOpts,
+			Now:        func() time.Time { return issuedAt.Add(2 * time.Minute) },
+		})
+		assert.ErrorIs(t, err, http.ErrStaleAPIResponse)
+	})
+
+	t.Run("expired but within clock skew", func(t *testing.T) {
+		_, err := http.VerifyAPIResponseWithOpts(generate(t), &http.APIResponseVerifyOpts{
+			VerifyOpts: verifyOpts,
+			ClockSkew:  2 * time.Minute,
+			Now:        func() time.Time { return issuedAt.Add(2 * time.Minute) },
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("exceeding max age", func(t *testing.T) {
+		_, err := http.VerifyAPIResponseWithOpts(generate(t), &http.APIResponseVerifyOpts{
+			VerifyOpts: verifyOpts,
+			MaxAge:     10 * time.Second,
+			Now:        func() time.Time { return issuedAt.Add(30 * time.Second) },
+		})
+		assert.ErrorIs(t, err, http.ErrStaleAPIResponse)
+	})
+
+	t.Run("issued in the future", func(t *testing.T) {
+		_, err := http.VerifyAPIResponseWithOpts(generate(t), &http.APIResponseVerifyOpts{
+			VerifyOpts: verifyOpts,
+			Now:        func() time.Time { return issuedAt.Add(-time.Hour) },
+		})
+		assert.ErrorIs(t, err, http.ErrStaleAPIResponse)
+	})
+
+	t.Run("unknown key id", func(t *testing.T) {
+		_, err := http.VerifyAPIResponseWithOpts(generate(t), &http.APIResponseVerifyOpts{
+			Keys: map[string]*encryption.VerifyOpts{"key-2": verifyOpts},
+			Now:  func() time.Time { return issuedAt },
+		})
+		assert.ErrorIs(t, err, http.ErrUnknownKeyID)
+	})
+
+	t.Run("tampered metadata", func(t *testing.T) {
+		body := generate(t)
+		tampered := strings.Replace(string(body), fmt.Sprintf(`"exp":%d`, issuedAt.Add(time.Minute).Unix()), fmt.Sprintf(`"exp":%d`, issuedAt.Add(time.Hour).Unix()), 1)
+		assert.NotEqual(t, string(body), tampered)
+
+		_, err := http.VerifyAPIResponseWithOpts([]byte(tampered), &http.APIResponseVerifyOpts{
+			VerifyOpts: verifyOpts,
+			Now:        func() time.Time { return issuedAt.Add(2 * time.Minute) },
+		})
+		assert.ErrorIs(t, err, http.ErrInvalidSignature)
+	})
+
+	t.Run("metadata required", func(t *testing.T) {
+		resp, err := http.NewStandardAPIResponseGenerator(signOpts).GenerateAPIResponse(response, nil)
+		assert.NoError(t, err)
+
+		body, err := json.Marshal(resp)
+		assert.NoError(t, err)
+
+		_, err = http.VerifyAPIResponseWithOpts(body, &http.APIResponseVerifyOpts{
+			VerifyOpts: verifyOpts,
+		})
+		assert.ErrorIs(t, err, http.ErrMissingSignatureMetadata)
+
+		_, err = http.VerifyAPIResponseWithOpts(body, &http.APIResponseVerifyOpts{
+			VerifyOpts:           verifyOpts,
+			AllowMissingMetadata: true,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("algorithm mismatch", func(t *testing.T) {
+		_, err := http.VerifyAPIResponseWithOpts(generate(t), &http.APIResponseVerifyOpts{
+			VerifyOpts: &encryption.VerifyOpts{PublicKey: &key.PublicKey, Alg: crypto.SHA256},
+			Now:        func() time.Time { return issuedAt },
+		})
+		assert.ErrorIs(t, err, http.ErrSignatureAlgorithmMismatch)
+	})
+
+	t.Run("replayed", func(t *testing.T) {
+		mr, err := miniredis.Run()
+		assert.NoError(t, err)
+		defer mr.Close()
+
+		checkers := map[string]http.NonceChecker{
+			"in memory": http.NewInMemoryNonceChecker(),
+			"cacher":    http.NewCacherNonceChecker(cacher.NewCacher(redis.NewClient(&redis.Options{Addr: mr.Addr()})), ""),
+		}
+
+		for name, checker := range checkers {
+			t.Run(name, func(t *testing.T) {
+				opts := &http.APIResponseVerifyOpts{
+					VerifyOpts:   verifyOpts,
+					NonceChecker: checker,
+					Now:          func() time.Time { return issuedAt },
+				}
+
+				body := generate(t)
+				_, err := http.VerifyAPIResponseWithOpts(body, opts)
+				assert.NoError(t, err)
+
+				_, err = http.VerifyAPIResponseWithOpts(body, opts)
+				assert.ErrorIs(t, err, http.ErrReplayedAPIResponse)
+
+				_, err = http.VerifyAPIResponseWithOpts(generate(t), opts)
+				assert.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("nil opts", func(t *testing.T) {
+		_, err := http.VerifyAPIResponseWithOpts(generate(t), nil)
+		assert.ErrorIs(t, err, http.ErrMissingVerifyOpts)
+
+		_, err = http.VerifyEncodedResponse([]byte(`{}`), nethttp.Header{}, nil)
+		assert.ErrorIs(t, err, http.ErrMissingVerifyOpts)
+	})
+}
@@ -0,0 +1,133 @@
+// Package circuitbreaker is the consecutive failures circuit breaker shared by the resilient http client
+// and the mail utility
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// list of default Opts
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenTimeout      = 30 * time.Second
+)
+
+// Opts is the options for New
+type Opts struct {
+	// FailureThreshold is the consecutive failures opening the circuit. Default to DefaultFailureThreshold
+	FailureThreshold int
+
+	// OpenTimeout is how long the circuit stays open before a single probe is allowed. Default to DefaultOpenTimeout
+	OpenTimeout time.Duration
+}
+
+// Breaker tracks the consecutive failures per key, e.g. the host or the mail client. A nil Breaker allows everything
+type Breaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// New creates the Breaker. If opts is nil, will use the default Opts
+func New(opts *Opts) *Breaker {
+	if opts == nil {
+		opts = &Opts{}
+	}
+
+	b := &Breaker{
+		threshold:   opts.FailureThreshold,
+		openTimeout: opts.OpenTimeout,
+		circuits:    make(map[string]*circuit),
+	}
+
+	if b.threshold <= 0 {
+		b.threshold = DefaultFailureThreshold
+	}
+
+	if b.openTimeout <= 0 {
+		b.openTimeout = DefaultOpenTimeout
+	}
+
+	return b
+}
+
+// Allow reports whether the key can be used. After the open timeout,
+// only a single probe is allowed until its outcome is reported
+func (b *Breaker) Allow(key string) bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok || c.failures < b.threshold {
+		return true
+	}
+
+	if c.probing || time.Since(c.openedAt) < b.openTimeout {
+		return false
+	}
+
+	c.probing = true
+
+	return true
+}
+
+// Success closes the circuit of the key
+func (b *Breaker) Success(key string) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.circuits, key)
+}
+
+// Failure counts the failure of the key, opening its circuit when the threshold is reached
+func (b *Breaker) Failure(key string) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+
+	c.failures++
+	c.probing = false
+	if c.failures >= b.threshold {
+		c.openedAt = time.Now()
+	}
+}
+
+// Release gives up the probe without outcome, so the next call can probe the key
+func (b *Breaker) Release(key string) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.circuits[key]; ok {
+		c.probing = false
+	}
+}
@@ -0,0 +1,63 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	t.Run("open after threshold then probe", func(t *testing.T) {
+		b := New(&Opts{FailureThreshold: 2, OpenTimeout: 20 * time.Millisecond})
+
+		b.Failure("a")
+		assert.True(t, b.Allow("a"))
+
+		b.Failure("a")
+		assert.False(t, b.Allow("a"))
+		assert.True(t, b.Allow("b"))
+
+		time.Sleep(30 * time.Millisecond)
+		assert.True(t, b.Allow("a"))
+		assert.False(t, b.Allow("a"), "only a single probe is allowed")
+
+		b.Release("a")
+		assert.True(t, b.Allow("a"))
+
+		b.Success("a")
+		assert.True(t, b.Allow("a"))
+		assert.True(t, b.Allow("a"))
+	})
+
+	t.Run("failed probe reopens", func(t *testing.T) {
+		b := New(&Opts{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+
+		b.Failure("a")
+		time.Sleep(30 * time.Millisecond)
+		assert.True(t, b.Allow("a"))
+
+		b.Failure("a")
+		assert.False(t, b.Allow("a"))
+	})
+
+	t.Run("nil breaker allows everything", func(t *testing.T) {
+		var b *Breaker
+		b.Failure("a")
+		b.Release("a")
+		b.Success("a")
+		assert.True(t, b.Allow("a"))
+	})
+
+	t.Run("default opts", func(t *testing.T) {
+		b := New(nil)
+		for i := 0; i < DefaultFailureThreshold-1; i++ {
+			b.Failure("a")
+		}
+
+		assert.True(t, b.Allow("a"))
+
+		b.Failure("a")
+		assert.False(t, b.Allow("a"))
+	})
+}
@@ -0,0 +1,218 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/sweet-go/stdlib/requestid"
+)
+
+// DefaultCatcherSenderEmail is the sender of the mails captured by File and InMemory clients when not configured
+const DefaultCatcherSenderEmail = "noreply@localhost"
+
+// CapturedMail is the mail captured by the local development clients, File and InMemory
+type CapturedMail struct {
+	// Key identifies the mail in the store
+	Key       string
+	MessageID string
+	SentAt    time.Time
+
+	// Mail is the sent mail. When read from the .eml file, only the fields written to the MIME message are filled
+	Mail *Mail
+
+	// Raw is the MIME message as sent using SMTP
+	Raw []byte
+}
+
+// CapturedMailStore is the store of the captured mails, browsed by NewViewer
+type CapturedMailStore interface {
+	// List returns the captured mails, the latest first
+	List() ([]*CapturedMail, error)
+
+	// Get returns the captured mail by the key. Returning ErrCapturedMailNotFound if no such mail
+	Get(key string) (*CapturedMail, error)
+}
+
+// captureMail validates the mail and builds the MIME message sent by the resolved sender.
+// The captured mail is a copy, so modifying the given mail afterwards does not change it
+func captureMail(m *Mail, serverSenderEmail string) (*CapturedMail, error) {
+	if err := m.Validate(0); err != nil {
+		return nil, err
+	}
+
+	if serverSenderEmail == "" {
+		serverSenderEmail = DefaultCatcherSenderEmail
+	}
+
+	sender, err := m.sender(serverSenderEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	messageID, raw, err := BuildMIMEMessage(m, sender)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CapturedMail{
+		MessageID: messageID,
+		SentAt:    time.Now(),
+		Mail:      m.clone(),
+		Raw:       raw,
+	}, nil
+}
+
+// parseCapturedMail parses the MIME message built by BuildMIMEMessage back to the captured mail
+func parseCapturedMail(key string, raw []byte) (*CapturedMail, error) {
+	msg, err := netmail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		return nil, err
+	}
+
+	messageID := msg.Header.Get("Message-Id")
+	id, _, _ := strings.Cut(strings.Trim(messageID, "<>"), "@")
+
+	m := &Mail{ID: id, Subject: subject}
+	if from := parseAddressHeader(msg.Header, "From"); len(from) > 0 {
+		m.From = &from[0]
+	}
+
+	if replyTo := parseAddressHeader(msg.Header, "Reply-To"); len(replyTo) > 0 {
+		m.ReplyTo = &replyTo[0]
+	}
+
+	m.To = parseAddressHeader(msg.Header, "To")
+	m.Cc = parseAddressHeader(msg.Header, "Cc")
+
+	for k := range msg.Header {
+		switch k {
+		case "From", "To", "Cc", "Reply-To", "Subject", "Date", "Message-Id", "Mime-Version",
+			"Content-Type", "Content-Transfer-Encoding":
+			continue
+		}
+
+		v, err := dec.DecodeHeader(msg.Header.Get(k))
+		if err != nil {
+			return nil, err
+		}
+
+		if k == textproto.CanonicalMIMEHeaderKey(requestid.Header) {
+			m.RequestID = v
+			continue
+		}
+
+		if m.CustomHeaders == nil {
+			m.CustomHeaders = map[string]string{}
+		}
+
+		m.CustomHeaders[k] = v
+	}
+
+	if err := parseMIMEPart(m, textproto.MIMEHeader(msg.Header), msg.Body); err != nil {
+		return nil, err
+	}
+
+	// the mail sent without Date header is shown with zero time
+	sentAt, _ := msg.Header.Date()
+
+	return &CapturedMail{
+		Key:       key,
+		MessageID: messageID,
+		SentAt:    sentAt,
+		Mail:      m.clone(),
+		Raw:       raw,
+	}, nil
+}
+
+func parseAddressHeader(header netmail.Header, key string) []GenericReceipient {
+	addresses, err := header.AddressList(key)
+	if err != nil {
+		return nil
+	}
+
+	res := make([]GenericReceipient, 0, len(addresses))
+	for _, a := range addresses {
+		res = append(res, GenericReceipient{Name: a.Name, Email: a.Address})
+	}
+
+	return res
+}
+
+// parseMIMEPart walks the multipart tree, filling the contents, attachments and inline images of the mail
+func parseMIMEPart(m *Mail, header textproto.MIMEHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			// NextPart decodes the quoted-printable parts
+			p, err := r.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if err := parseMIMEPart(m, p.Header, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	if disposition == "attachment" || disposition == "inline" {
+		filename := dispositionParams["filename"]
+		if filename == "" {
+			filename = params["name"]
+		}
+
+		attachment := Attachment{Filename: filename, ContentType: mediaType, Content: content}
+		if disposition == "inline" && header.Get("Content-Id") != "" {
+			m.InlineImages = append(m.InlineImages, attachment)
+			return nil
+		}
+
+		m.Attachments = append(m.Attachments, attachment)
+		return nil
+	}
+
+	switch mediaType {
+	case "text/html":
+		m.HTMLContent = string(content)
+	default:
+		m.TextContent = string(content)
+	}
+
+	return nil
+}
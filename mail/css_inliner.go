@@ -0,0 +1,371 @@
+package mail
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/aymerick/douceur/css"
+	"github.com/aymerick/douceur/parser"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// InlineCSS moves the rules of the style elements to the style attribute of the matching elements,
+// since many email clients strip the style elements. Only the type, class, ID and universal selectors
+// combined by descendant and child combinators are inlined. The other rules, e.g. media queries and pseudo classes,
+// are kept in a single style element. The existing style attribute takes precedence unless the rule is !important
+func InlineCSS(content string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+
+	var styles []*html.Node
+	walkElements(doc, func(n *html.Node) {
+		if n.DataAtom == atom.Style {
+			styles = append(styles, n)
+		}
+	})
+
+	if len(styles) == 0 {
+		return content, nil
+	}
+
+	var rules []*inlineRule
+	var remaining []string
+	for _, style := range styles {
+		var text strings.Builder
+		for c := style.FirstChild; c != nil; c = c.NextSibling {
+			text.WriteString(c.Data)
+		}
+
+		stylesheet, err := parser.Parse(text.String())
+		if err != nil {
+			return "", err
+		}
+
+		for _, rule := range stylesheet.Rules {
+			if rule.Kind != css.QualifiedRule {
+				remaining = append(remaining, rule.String())
+				continue
+			}
+
+			for _, s := range rule.Selectors {
+				sel, ok := parseSelector(s)
+				if !ok {
+					remaining = append(remaining, (&css.Rule{Kind: css.QualifiedRule, Prelude: s, Selectors: []string{s}, Declarations: rule.Declarations}).String())
+					continue
+				}
+
+				rules = append(rules, &inlineRule{
+					selector:     sel,
+					specificity:  sel.specificity(),
+					declarations: rule.Declarations,
+				})
+			}
+		}
+	}
+
+	// stable sort keeps the source order of the rules with the same specificity
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].specificity.less(rules[j].specificity)
+	})
+
+	walkElements(doc, func(n *html.Node) {
+		inlineStyle(n, rules)
+	})
+
+	for i, style := range styles {
+		if i == 0 && len(remaining) > 0 {
+			for c := style.FirstChild; c != nil; c = style.FirstChild {
+				style.RemoveChild(c)
+			}
+
+			style.AppendChild(&html.Node{Type: html.TextNode, Data: strings.Join(remaining, "\n")})
+			continue
+		}
+
+		style.Parent.RemoveChild(style)
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+type inlineRule struct {
+	selector     *selector
+	specificity  specificity
+	declarations []*css.Declaration
+}
+
+func inlineStyle(n *html.Node, rules []*inlineRule) {
+	switch n.DataAtom {
+	case atom.Html, atom.Head, atom.Style, atom.Script, atom.Title, atom.Meta, atom.Link:
+		return
+	}
+
+	var properties []string
+	values := map[string]*css.Declaration{}
+	apply := func(d *css.Declaration) {
+		current, ok := values[d.Property]
+		if !ok {
+			properties = append(properties, d.Property)
+		}
+
+		if ok && current.Important && !d.Important {
+			return
+		}
+
+		values[d.Property] = d
+	}
+
+	for _, r := range rules {
+		if !r.selector.match(n) {
+			continue
+		}
+
+		for _, d := range r.declarations {
+			apply(d)
+		}
+	}
+
+	if len(properties) == 0 {
+		return
+	}
+
+	styleIdx := -1
+	for i, a := range n.Attr {
+		if a.Key == "style" {
+			styleIdx = i
+			// the parser drops the value of the last declaration without trailing semicolon
+			declarations, err := parser.ParseDeclarations(strings.TrimRight(strings.TrimSpace(a.Val), ";") + ";")
+			if err != nil {
+				return
+			}
+
+			for _, d := range declarations {
+				apply(d)
+			}
+		}
+	}
+
+	style := make([]string, 0, len(properties))
+	for _, p := range properties {
+		style = append(style, p+": "+values[p].Value)
+	}
+
+	attr := html.Attribute{Key: "style", Val: strings.Join(style, "; ")}
+	if styleIdx >= 0 {
+		n.Attr[styleIdx] = attr
+		return
+	}
+
+	n.Attr = append(n.Attr, attr)
+}
+
+func walkElements(n *html.Node, fn func(n *html.Node)) {
+	for c := n.FirstChild; c != nil; {
+		// the next sibling is taken first, so fn can remove c
+		next := c.NextSibling
+		if c.Type == html.ElementNode {
+			fn(c)
+		}
+
+		walkElements(c, fn)
+		c = next
+	}
+}
+
+// specificity is the CSS selector specificity, counting the IDs, classes and types
+type specificity [3]int
+
+func (s specificity) less(o specificity) bool {
+	for i := range s {
+		if s[i] != o[i] {
+			return s[i] < o[i]
+		}
+	}
+
+	return false
+}
+
+// selector is the list of compound selectors from the outermost, each combined with the previous by combinator
+type selector struct {
+	compounds []compoundSelector
+}
+
+type compoundSelector struct {
+	tag     string
+	id      string
+	classes []string
+
+	// child is true when combined with the previous compound by the child combinator,
+	// otherwise by the descendant combinator
+	child bool
+}
+
+// parseSelector parses the supported subset of the selector, returning false if the selector is not supported
+func parseSelector(s string) (*selector, bool) {
+	s = strings.ReplaceAll(s, ">", " > ")
+
+	sel := &selector{}
+	child := false
+	for _, part := range strings.Fields(s) {
+		if part == ">" {
+			if child || len(sel.compounds) == 0 {
+				return nil, false
+			}
+
+			child = true
+			continue
+		}
+
+		compound, ok := parseCompoundSelector(part)
+		if !ok {
+			return nil, false
+		}
+
+		compound.child = child
+		child = false
+		sel.compounds = append(sel.compounds, compound)
+	}
+
+	if child || len(sel.compounds) == 0 {
+		return nil, false
+	}
+
+	return sel, true
+}
+
+func parseCompoundSelector(s string) (compoundSelector, bool) {
+	if strings.ContainsAny(s, ":[]+~()\\\"'") {
+		return compoundSelector{}, false
+	}
+
+	var c compoundSelector
+	tagEnd := strings.IndexAny(s, ".#")
+	if tagEnd < 0 {
+		tagEnd = len(s)
+	}
+
+	c.tag = strings.ToLower(s[:tagEnd])
+	if c.tag == "*" {
+		c.tag = ""
+	}
+
+	rest := s[tagEnd:]
+	for rest != "" {
+		prefix := rest[0]
+		rest = rest[1:]
+
+		end := strings.IndexAny(rest, ".#")
+		if end < 0 {
+			end = len(rest)
+		}
+
+		name := rest[:end]
+		rest = rest[end:]
+		if name == "" {
+			return compoundSelector{}, false
+		}
+
+		if prefix == '#' {
+			if c.id != "" {
+				return compoundSelector{}, false
+			}
+
+			c.id = name
+			continue
+		}
+
+		c.classes = append(c.classes, name)
+	}
+
+	return c, true
+}
+
+func (s *selector) specificity() specificity {
+	var res specificity
+	for _, c := range s.compounds {
+		if c.id != "" {
+			res[0]++
+		}
+
+		res[1] += len(c.classes)
+		if c.tag != "" {
+			res[2]++
+		}
+	}
+
+	return res
+}
+
+func (s *selector) match(n *html.Node) bool {
+	return s.matchFrom(n, len(s.compounds)-1)
+}
+
+// matchFrom reports whether n matches the compound i, and its ancestors match the preceding compounds
+func (s *selector) matchFrom(n *html.Node, i int) bool {
+	c := s.compounds[i]
+	if !c.match(n) {
+		return false
+	}
+
+	if i == 0 {
+		return true
+	}
+
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if s.matchFrom(p, i-1) {
+			return true
+		}
+
+		if c.child {
+			return false
+		}
+	}
+
+	return false
+}
+
+func (c *compoundSelector) match(n *html.Node) bool {
+	if c.tag != "" && c.tag != n.Data {
+		return false
+	}
+
+	var id string
+	var classes []string
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "id":
+			id = a.Val
+		case "class":
+			classes = strings.Fields(a.Val)
+		}
+	}
+
+	if c.id != "" && c.id != id {
+		return false
+	}
+
+	for _, want := range c.classes {
+		found := false
+		for _, class := range classes {
+			if class == want {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,44 @@
+package mail_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+func TestInlineCSS(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		content := `<html><head><style>
+p { color: red; margin: 0 }
+.note { color: blue }
+#footer p { font-size: 12px }
+div > span.badge, td { font-weight: bold !important }
+a:hover { color: green }
+@media (max-width: 600px) { p { margin: 8px } }
+</style></head><body>
+<p class="note" style="margin: 4px">Note</p>
+<p>Plain</p>
+<div id="footer"><p>Footer</p><div><span class="badge" style="font-weight: normal;">New</span></div></div>
+<span class="badge">Outside</span>
+</body></html>`
+
+		res, err := mail.InlineCSS(content)
+		assert.NoError(t, err)
+
+		assert.Contains(t, res, `<p class="note" style="color: blue; margin: 4px">Note</p>`)
+		assert.Contains(t, res, `<p style="color: red; margin: 0">Plain</p>`)
+		assert.Contains(t, res, `<p style="color: red; margin: 0; font-size: 12px">Footer</p>`)
+		assert.Contains(t, res, `<span class="badge" style="font-weight: bold">New</span>`)
+		assert.Contains(t, res, `<span class="badge">Outside</span>`)
+		assert.Contains(t, res, "a:hover")
+		assert.Contains(t, res, "@media (max-width: 600px)")
+		assert.NotContains(t, res, ".note {")
+	})
+
+	t.Run("no style element", func(t *testing.T) {
+		res, err := mail.InlineCSS("<p>Hello</p>")
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>Hello</p>", res)
+	})
+}
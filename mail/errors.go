@@ -1,6 +1,9 @@
 package mail
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrMailgunNotActivated is returned when mailgun is not activated by configuration
@@ -8,4 +11,61 @@ var (
 
 	// ErrSendInBlueNotActivated is returned when sendinblue is not activated by configuration
 	ErrSendInBlueNotActivated = errors.New("sendinblue is not activated by configuration")
+
+	// ErrSMTPNotActivated is returned when smtp is not activated by configuration
+	ErrSMTPNotActivated = errors.New("smtp is not activated by configuration")
+
+	// ErrSMTPStartTLSNotSupported is returned when the server doesn't support STARTTLS required by SMTPTLSModeStartTLS
+	ErrSMTPStartTLSNotSupported = errors.New("smtp server doesn't support STARTTLS")
+)
+
+var (
+	// ErrTemplateNotFound is returned when no template variant is found by the name and locale
+	ErrTemplateNotFound = errors.New("mail template not found")
+
+	// ErrInvalidTemplate is returned when the template is missing the required block or refers unknown layout
+	ErrInvalidTemplate = errors.New("invalid mail template")
+)
+
+var (
+	// ErrMailNoRecipient is returned when the mail has no recipient
+	ErrMailNoRecipient = errors.New("mail has no recipient")
+
+	// ErrInvalidAttachment is returned when the attachment or inline image has no filename or content
+	ErrInvalidAttachment = errors.New("mail attachment must have filename and content")
+
+	// ErrMailTooLarge is returned when the mail size exceeds the client max message size
+	ErrMailTooLarge = errors.New("mail exceeds the max message size")
+
+	// ErrInvalidMailHeader is returned when the custom header name or value is not allowed in the MIME message
+	ErrInvalidMailHeader = errors.New("invalid mail header")
+)
+
+// ErrCapturedMailNotFound is returned when no captured mail is found by the key
+var ErrCapturedMailNotFound = errors.New("captured mail not found")
+
+var (
+	// ErrMailDeliveryUnknown is returned when the client fails with ambiguous error, so the mail may have been sent.
+	// The next client is not tried to avoid sending the duplicate
+	ErrMailDeliveryUnknown = errors.New("mail may have been sent, not sent using the next client to avoid duplicate")
+
+	// ErrMailCircuitOpen is reported when the client is skipped since its circuit breaker is open
+	ErrMailCircuitOpen = errors.New("mail client circuit breaker is open")
+
+	// ErrMailRateLimited is reported when the client is skipped since it is rate limited
+	ErrMailRateLimited = errors.New("mail client is rate limited")
+)
+
+// ErrInvalidWebhookConfig is returned when the webhook handler is created without the required options
+var ErrInvalidWebhookConfig = errors.New("invalid mail webhook config")
+
+var (
+	// ErrInvalidRecipient is returned by RecipientValidator when the recipient must not be sent to
+	ErrInvalidRecipient = errors.New("invalid mail recipient")
+
+	// ErrRecipientSuppressed is reported when the recipient is found in the suppression list
+	ErrRecipientSuppressed = errors.New("mail recipient is suppressed")
+
+	// ErrAllRecipientsDropped is returned when every recipient is invalid or suppressed, wrapping ErrMailNoRecipient
+	ErrAllRecipientsDropped = fmt.Errorf("%w: all recipients are invalid or suppressed", ErrMailNoRecipient)
 )
@@ -0,0 +1,201 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"sync"
+	"time"
+
+	mailgun "github.com/mailgun/mailgun-go/v4"
+	"github.com/sweet-go/stdlib/internal/circuitbreaker"
+	"golang.org/x/time/rate"
+)
+
+// ErrorClass is the class of the client error, deciding whether the mail is sent using the next client
+type ErrorClass string
+
+// list of ErrorClass
+const (
+	// ErrorClassRetryable is the error of the client, the next client may succeed
+	ErrorClassRetryable ErrorClass = "retryable"
+
+	// ErrorClassPermanent is the error of the mail itself, e.g. invalid recipient, so every client would fail
+	ErrorClassPermanent ErrorClass = "permanent"
+
+	// ErrorClassAmbiguous is the error after the mail may have been accepted, e.g. timeout waiting for the response.
+	// Sending using the next client may deliver the duplicate
+	ErrorClassAmbiguous ErrorClass = "ambiguous"
+)
+
+// ProviderResponseError is the error of the provider API response, e.g. SendInBlue
+type ProviderResponseError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *ProviderResponseError) Error() string {
+	return fmt.Sprintf("provider responded %d: %s", e.StatusCode, e.Err)
+}
+
+func (e *ProviderResponseError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorClassifier classifies the error returned by Client.SendEmail
+type ErrorClassifier func(err error) ErrorClass
+
+// ClassifyError is the default ErrorClassifier. The invalid mail, the SMTP reply 550, 551 and 553,
+// and the provider response 400 are permanent. The timeout after connected is ambiguous.
+// ErrMailTooLarge is retryable, since every client has its own max message size.
+// The other errors, including the client not activated, are retryable
+func ClassifyError(err error) ErrorClass {
+	switch {
+	case errors.Is(err, ErrMailNoRecipient), errors.Is(err, ErrInvalidAttachment), errors.Is(err, ErrInvalidMailHeader):
+		return ErrorClassPermanent
+	case errors.Is(err, ErrMailTooLarge):
+		return ErrorClassRetryable
+	}
+
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		switch reply.Code {
+		case 550, 551, 553:
+			return ErrorClassPermanent
+		default:
+			return ErrorClassRetryable
+		}
+	}
+
+	var mailgunErr *mailgun.UnexpectedResponseError
+	if errors.As(err, &mailgunErr) {
+		return classifyStatus(mailgunErr.Actual)
+	}
+
+	var providerErr *ProviderResponseError
+	if errors.As(err, &providerErr) {
+		return classifyStatus(providerErr.StatusCode)
+	}
+
+	// the mail is never sent when failing to connect
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return ErrorClassRetryable
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassAmbiguous
+	}
+
+	return ErrorClassRetryable
+}
+
+func classifyStatus(status int) ErrorClass {
+	if status == 400 {
+		return ErrorClassPermanent
+	}
+
+	return ErrorClassRetryable
+}
+
+// Strategy decides the order of the clients tried for every mail. Must be safe for concurrent use
+type Strategy interface {
+	// Order returns the clients in the order to try, without modifying clients
+	Order(clients []Client) []Client
+}
+
+type priorityStrategy struct{}
+
+// NewPriorityStrategy creates the strategy trying the clients in the registered order. This is the default strategy
+func NewPriorityStrategy() Strategy {
+	return priorityStrategy{}
+}
+
+func (priorityStrategy) Order(clients []Client) []Client {
+	return clients
+}
+
+type weightedRoundRobinStrategy struct {
+	weights map[ClientSignature]int
+
+	mu      sync.Mutex
+	current map[ClientSignature]int
+}
+
+// NewWeightedRoundRobinStrategy creates the strategy spreading the mails to the clients proportional to the weights,
+// using the smooth weighted round-robin. The other clients are tried in the registered order when the chosen client fails.
+// The client without positive weight is only used as failover
+func NewWeightedRoundRobinStrategy(weights map[ClientSignature]int) Strategy {
+	return &weightedRoundRobinStrategy{
+		weights: weights,
+		current: make(map[ClientSignature]int),
+	}
+}
+
+func (s *weightedRoundRobinStrategy) Order(clients []Client) []Client {
+	s.mu.Lock()
+	chosen, total := -1, 0
+	var chosenName ClientSignature
+	for i, c := range clients {
+		name := c.GetClientName()
+		weight := s.weights[name]
+		if weight <= 0 {
+			continue
+		}
+
+		total += weight
+		s.current[name] += weight
+		if chosen < 0 || s.current[name] > s.current[chosenName] {
+			chosen, chosenName = i, name
+		}
+	}
+
+	if chosen >= 0 {
+		s.current[chosenName] -= total
+	}
+	s.mu.Unlock()
+
+	if chosen <= 0 {
+		return clients
+	}
+
+	res := make([]Client, 0, len(clients))
+	res = append(res, clients[chosen])
+	res = append(res, clients[:chosen]...)
+
+	return append(res, clients[chosen+1:]...)
+}
+
+// CircuitBreakerOpts is the options for the per client circuit breaker of Utility.
+// Only the retryable or ambiguous errors count as failures
+type CircuitBreakerOpts = circuitbreaker.Opts
+
+// RateLimit is the max mails sent using the client per period, e.g. 300 mails per minute
+type RateLimit struct {
+	Limit int
+	Per   time.Duration
+
+	// Burst is the max mails sent at once. Default to Limit
+	Burst int
+}
+
+func newRateLimiters(limits map[ClientSignature]RateLimit) map[ClientSignature]*rate.Limiter {
+	limiters := make(map[ClientSignature]*rate.Limiter, len(limits))
+	for client, l := range limits {
+		if l.Limit <= 0 || l.Per <= 0 {
+			continue
+		}
+
+		burst := l.Burst
+		if burst <= 0 {
+			burst = l.Limit
+		}
+
+		limiters[client] = rate.NewLimiter(rate.Every(l.Per/time.Duration(l.Limit)), burst)
+	}
+
+	return limiters
+}
@@ -0,0 +1,274 @@
+package mail_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	mailgun "github.com/mailgun/mailgun-go/v4"
+	"github.com/sendinblue/APIv3-go-library/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+// stubClient returns the errors in order, then succeeds
+type stubClient struct {
+	name mail.ClientSignature
+
+	mu    sync.Mutex
+	errs  []error
+	calls int
+}
+
+func (c *stubClient) SendEmail(_ context.Context, _ *mail.Mail) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return "", err
+	}
+
+	return "metadata " + string(c.name), nil
+}
+
+func (c *stubClient) GetClientName() mail.ClientSignature {
+	return c.name
+}
+
+func TestClassifyError(t *testing.T) {
+	sibSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer sibSrv.Close()
+
+	sibClient := lib.NewAPIClient(&lib.Configuration{BasePath: sibSrv.URL, DefaultHeader: map[string]string{}})
+	sib := &mail.SendInBlue{}
+	sib.Set(sibClient, &lib.SendSmtpEmailSender{}, true)
+	_, sibErr := sib.SendEmail(context.TODO(), &mail.Mail{To: []mail.GenericReceipient{{Email: "tom@example.com"}}})
+	assert.Error(t, sibErr)
+
+	tests := []struct {
+		name string
+		err  error
+		want mail.ErrorClass
+	}{
+		{"invalid mail", mail.ErrMailNoRecipient, mail.ErrorClassPermanent},
+		{"invalid header", fmt.Errorf("%w: x", mail.ErrInvalidMailHeader), mail.ErrorClassPermanent},
+		{"too large", fmt.Errorf("%w: 1 bytes", mail.ErrMailTooLarge), mail.ErrorClassRetryable},
+		{"not activated", mail.ErrMailgunNotActivated, mail.ErrorClassRetryable},
+		{"smtp mailbox unavailable", &textproto.Error{Code: 550, Msg: "no such user"}, mail.ErrorClassPermanent},
+		{"smtp service unavailable", &textproto.Error{Code: 421, Msg: "try again later"}, mail.ErrorClassRetryable},
+		{"mailgun bad request", &mailgun.UnexpectedResponseError{Actual: http.StatusBadRequest}, mail.ErrorClassPermanent},
+		{"mailgun server error", &mailgun.UnexpectedResponseError{Actual: http.StatusBadGateway}, mail.ErrorClassRetryable},
+		{"sendinblue bad request", sibErr, mail.ErrorClassPermanent},
+		{"dial timeout", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, mail.ErrorClassRetryable},
+		{"read timeout", &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, mail.ErrorClassAmbiguous},
+		{"deadline exceeded", fmt.Errorf("send: %w", context.DeadlineExceeded), mail.ErrorClassAmbiguous},
+		{"unknown", errors.New("unknown"), mail.ErrorClassRetryable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mail.ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestMailUtility_Failover(t *testing.T) {
+	ctx := context.TODO()
+	m := &mail.Mail{To: []mail.GenericReceipient{{Email: "tom@example.com"}}}
+
+	t.Run("ok - attempts reported", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{mail.ErrMailgunNotActivated}}
+		b := &stubClient{name: "b"}
+
+		res, err := mail.NewUtility(a, b).SendEmailWithResult(ctx, m)
+		assert.NoError(t, err)
+		assert.Equal(t, "metadata b", res.Metadata)
+		assert.Equal(t, mail.ClientSignature("b"), res.Client)
+		assert.Len(t, res.Attempts, 2)
+		assert.Equal(t, mail.ClientSignature("a"), res.Attempts[0].Client)
+		assert.ErrorIs(t, res.Attempts[0].Err, mail.ErrMailgunNotActivated)
+		assert.Equal(t, mail.ErrorClassRetryable, res.Attempts[0].Class)
+		assert.NoError(t, res.Attempts[1].Err)
+		assert.Equal(t, "metadata b", res.Attempts[1].Metadata)
+	})
+
+	t.Run("err - permanent error is not retried", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{&textproto.Error{Code: 550, Msg: "no such user"}}}
+		b := &stubClient{name: "b"}
+
+		res, err := mail.NewUtility(a, b).SendEmailWithResult(ctx, m)
+		assert.Error(t, err)
+		assert.Len(t, res.Attempts, 1)
+		assert.Equal(t, mail.ErrorClassPermanent, res.Attempts[0].Class)
+		assert.Equal(t, 0, b.calls)
+	})
+
+	t.Run("err - ambiguous error is not retried", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{context.DeadlineExceeded}}
+		b := &stubClient{name: "b"}
+
+		_, _, err := mail.NewUtility(a, b).SendEmail(ctx, m)
+		assert.ErrorIs(t, err, mail.ErrMailDeliveryUnknown)
+		assert.Equal(t, 0, b.calls)
+	})
+
+	t.Run("ok - failover on ambiguous error", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{context.DeadlineExceeded}}
+		b := &stubClient{name: "b"}
+
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{FailoverOnAmbiguousError: true}, a, b)
+		_, signature, err := utility.SendEmail(ctx, m)
+		assert.NoError(t, err)
+		assert.Equal(t, mail.ClientSignature("b"), signature)
+	})
+
+	t.Run("ok - custom error classifier", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{mail.ErrMailNoRecipient}}
+		b := &stubClient{name: "b"}
+
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{
+			ErrorClassifier: func(err error) mail.ErrorClass {
+				return mail.ErrorClassRetryable
+			},
+		}, a, b)
+		_, signature, err := utility.SendEmail(ctx, m)
+		assert.NoError(t, err)
+		assert.Equal(t, mail.ClientSignature("b"), signature)
+	})
+
+	t.Run("ok - weighted round-robin", func(t *testing.T) {
+		a := &stubClient{name: "a"}
+		b := &stubClient{name: "b"}
+		c := &stubClient{name: "c"}
+
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{
+			Strategy: mail.NewWeightedRoundRobinStrategy(map[mail.ClientSignature]int{"a": 3, "b": 1}),
+		}, a, b, c)
+
+		var signatures []mail.ClientSignature
+		for i := 0; i < 8; i++ {
+			_, signature, err := utility.SendEmail(ctx, m)
+			assert.NoError(t, err)
+			signatures = append(signatures, signature)
+		}
+
+		assert.Equal(t, []mail.ClientSignature{"a", "a", "b", "a", "a", "a", "b", "a"}, signatures)
+		assert.Equal(t, 0, c.calls)
+	})
+
+	t.Run("ok - weighted round-robin failover", func(t *testing.T) {
+		a := &stubClient{name: "a"}
+		b := &stubClient{name: "b", errs: []error{mail.ErrSendInBlueNotActivated}}
+
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{
+			Strategy: mail.NewWeightedRoundRobinStrategy(map[mail.ClientSignature]int{"b": 1}),
+		}, a, b)
+
+		res, err := utility.SendEmailWithResult(ctx, m)
+		assert.NoError(t, err)
+		assert.Equal(t, mail.ClientSignature("b"), res.Attempts[0].Client)
+		assert.Equal(t, mail.ClientSignature("a"), res.Client)
+	})
+
+	t.Run("ok - circuit breaker", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{mail.ErrMailgunNotActivated, mail.ErrMailgunNotActivated}}
+		b := &stubClient{name: "b"}
+
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{
+			CircuitBreaker: &mail.CircuitBreakerOpts{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond},
+		}, a, b)
+
+		for i := 0; i < 2; i++ {
+			_, signature, err := utility.SendEmail(ctx, m)
+			assert.NoError(t, err)
+			assert.Equal(t, mail.ClientSignature("b"), signature)
+		}
+
+		res, err := utility.SendEmailWithResult(ctx, m)
+		assert.NoError(t, err)
+		assert.True(t, res.Attempts[0].Skipped)
+		assert.ErrorIs(t, res.Attempts[0].Err, mail.ErrMailCircuitOpen)
+		assert.Equal(t, mail.ClientSignature("b"), res.Client)
+		assert.Equal(t, 2, a.calls)
+
+		time.Sleep(60 * time.Millisecond)
+
+		_, signature, err := utility.SendEmail(ctx, m)
+		assert.NoError(t, err)
+		assert.Equal(t, mail.ClientSignature("a"), signature)
+		assert.Equal(t, 3, a.calls)
+	})
+
+	t.Run("ok - permanent error doesn't open the circuit", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{mail.ErrMailNoRecipient, mail.ErrMailNoRecipient}}
+
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{
+			CircuitBreaker: &mail.CircuitBreakerOpts{FailureThreshold: 1},
+		}, a)
+
+		for i := 0; i < 2; i++ {
+			_, _, err := utility.SendEmail(ctx, m)
+			assert.ErrorIs(t, err, mail.ErrMailNoRecipient)
+		}
+
+		assert.Equal(t, 2, a.calls)
+	})
+
+	t.Run("ok - rate limit", func(t *testing.T) {
+		a := &stubClient{name: "a"}
+		b := &stubClient{name: "b"}
+
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{
+			RateLimits: map[mail.ClientSignature]mail.RateLimit{"a": {Limit: 1, Per: time.Hour}},
+		}, a, b)
+
+		_, signature, err := utility.SendEmail(ctx, m)
+		assert.NoError(t, err)
+		assert.Equal(t, mail.ClientSignature("a"), signature)
+
+		res, err := utility.SendEmailWithResult(ctx, m)
+		assert.NoError(t, err)
+		assert.True(t, res.Attempts[0].Skipped)
+		assert.ErrorIs(t, res.Attempts[0].Err, mail.ErrMailRateLimited)
+		assert.Equal(t, mail.ClientSignature("b"), res.Client)
+	})
+
+	t.Run("err - all clients skipped", func(t *testing.T) {
+		a := &stubClient{name: "a"}
+
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{
+			RateLimits: map[mail.ClientSignature]mail.RateLimit{"a": {Limit: 1, Per: time.Hour}},
+		}, a)
+
+		_, _, err := utility.SendEmail(ctx, m)
+		assert.NoError(t, err)
+
+		_, _, err = utility.SendEmail(ctx, m)
+		assert.ErrorIs(t, err, mail.ErrMailRateLimited)
+		assert.Equal(t, 1, a.calls)
+	})
+
+	t.Run("err - cancelled", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		a := &stubClient{name: "a"}
+		res, err := mail.NewUtility(a).SendEmailWithResult(cctx, m)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, res.Attempts)
+		assert.Equal(t, 0, a.calls)
+	})
+}
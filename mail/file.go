@@ -0,0 +1,154 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileSignature file client signature
+const FileSignature ClientSignature = "file"
+
+// FileExtension is the extension of the mails written by File client
+const FileExtension = ".eml"
+
+// FileConfig configuration for file client
+type FileConfig struct {
+	// Dir is required. Created on the first SendEmail if not exists
+	Dir string
+
+	// ServerSenderEmail is the sender used when Mail.From is nil. Default to DefaultCatcherSenderEmail
+	ServerSenderEmail string
+}
+
+// File is the client writing every mail as .eml file to the directory instead of sending it,
+// to be used in local development. The files can be opened by any mail client or browsed using NewViewer
+type File struct {
+	dir               string
+	serverSenderEmail string
+}
+
+// NewFileClient create new file client
+func NewFileClient(config FileConfig) *File {
+	return &File{
+		dir:               config.Dir,
+		serverSenderEmail: config.ServerSenderEmail,
+	}
+}
+
+// SendEmail writes the mail as .eml file, returning the Message-ID. Returning error if the mail is invalid
+func (f *File) SendEmail(_ context.Context, mail *Mail) (string, error) {
+	captured, err := captureMail(mail, f.serverSenderEmail)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return "", err
+	}
+
+	// the file is written under temporary name, so the viewer never reads the partial mail
+	tmp, err := os.CreateTemp(f.dir, ".mail-*")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(captured.Raw); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	key := fileMailKey(captured.SentAt, captured.MessageID)
+	if err := os.Rename(tmp.Name(), filepath.Join(f.dir, key+FileExtension)); err != nil {
+		return "", err
+	}
+
+	return captured.MessageID, nil
+}
+
+// GetClientName returning client name signature
+func (f *File) GetClientName() ClientSignature {
+	return FileSignature
+}
+
+// List returns the mails in the directory, the latest first. Missing directory is not an error
+func (f *File) List() ([]*CapturedMail, error) {
+	entries, err := os.ReadDir(f.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != FileExtension {
+			continue
+		}
+
+		keys = append(keys, strings.TrimSuffix(entry.Name(), FileExtension))
+	}
+
+	// the keys are prefixed by the sent time
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+
+	res := make([]*CapturedMail, 0, len(keys))
+	for _, key := range keys {
+		captured, err := f.Get(key)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, captured)
+	}
+
+	return res, nil
+}
+
+// Get returns the mail by the file name without extension
+func (f *File) Get(key string) (*CapturedMail, error) {
+	if key == "" || key != filepath.Base(key) || strings.HasPrefix(key, ".") {
+		return nil, ErrCapturedMailNotFound
+	}
+
+	raw, err := os.ReadFile(filepath.Join(f.dir, key+FileExtension))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrCapturedMailNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCapturedMail(key, raw)
+}
+
+// fileMailKey returns the file name sortable by the sent time, followed by the Message-ID local part
+func fileMailKey(sentAt time.Time, messageID string) string {
+	id, _, _ := strings.Cut(strings.Trim(messageID, "<>"), "@")
+	id = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, id)
+
+	return sentAt.UTC().Format("20060102T150405.000000000") + "-" + id
+}
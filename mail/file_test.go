@@ -0,0 +1,101 @@
+package mail_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+func TestFile(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("ok - write and read back", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "mails")
+		client := mail.NewFileClient(mail.FileConfig{Dir: dir})
+		assert.Equal(t, mail.FileSignature, client.GetClientName())
+
+		mails, err := client.List()
+		assert.NoError(t, err)
+		assert.Empty(t, mails)
+
+		m := &mail.Mail{
+			ID:            "first/mail",
+			To:            []mail.GenericReceipient{{Name: "Tom", Email: "tom@example.com"}},
+			Cc:            []mail.GenericReceipient{{Email: "cc@example.com"}},
+			ReplyTo:       &mail.GenericReceipient{Email: "support@example.com"},
+			Subject:       "Tagihan bulan ini ✓",
+			HTMLContent:   `<p>Halo Tom, tagihan bulan ini sudah tersedia</p><img src="cid:logo.png">`,
+			TextContent:   "Halo Tom, tagihan bulan ini sudah tersedia",
+			Attachments:   []mail.Attachment{{Filename: "tagihan.pdf", ContentType: "application/pdf", Content: []byte("pdf")}},
+			InlineImages:  []mail.Attachment{{Filename: "logo.png", ContentType: "image/png", Content: []byte("png")}},
+			CustomHeaders: map[string]string{"X-Invoice-Id": "1"},
+			RequestID:     "request-id",
+		}
+
+		messageID, err := client.SendEmail(ctx, m)
+		assert.NoError(t, err)
+		assert.Equal(t, "<first/mail@localhost>", messageID)
+
+		_, err = client.SendEmail(ctx, &mail.Mail{
+			From:    &mail.GenericReceipient{Email: "billing@example.com"},
+			To:      []mail.GenericReceipient{{Email: "jerry@example.com"}},
+			Subject: "second",
+		})
+		assert.NoError(t, err)
+
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+		for _, e := range entries {
+			assert.Equal(t, mail.FileExtension, filepath.Ext(e.Name()))
+		}
+
+		mails, err = client.List()
+		assert.NoError(t, err)
+		assert.Len(t, mails, 2)
+		assert.Equal(t, "second", mails[0].Mail.Subject)
+		assert.Equal(t, &mail.GenericReceipient{Email: "billing@example.com"}, mails[0].Mail.From)
+
+		captured, err := client.Get(mails[1].Key)
+		assert.NoError(t, err)
+		assert.Equal(t, messageID, captured.MessageID)
+		assert.False(t, captured.SentAt.IsZero())
+		assert.Equal(t, "first/mail", captured.Mail.ID)
+		assert.Equal(t, &mail.GenericReceipient{Email: mail.DefaultCatcherSenderEmail}, captured.Mail.From)
+		assert.Equal(t, m.To, captured.Mail.To)
+		assert.Equal(t, m.Cc, captured.Mail.Cc)
+		assert.Equal(t, m.ReplyTo, captured.Mail.ReplyTo)
+		assert.Equal(t, m.Subject, captured.Mail.Subject)
+		assert.Equal(t, m.HTMLContent, captured.Mail.HTMLContent)
+		assert.Equal(t, m.TextContent, captured.Mail.TextContent)
+		assert.Equal(t, m.Attachments, captured.Mail.Attachments)
+		assert.Equal(t, m.InlineImages, captured.Mail.InlineImages)
+		assert.Equal(t, m.CustomHeaders, captured.Mail.CustomHeaders)
+		assert.Equal(t, m.RequestID, captured.Mail.RequestID)
+	})
+
+	t.Run("err - invalid mail", func(t *testing.T) {
+		dir := t.TempDir()
+		client := mail.NewFileClient(mail.FileConfig{Dir: dir})
+
+		_, err := client.SendEmail(ctx, &mail.Mail{Subject: "no recipient"})
+		assert.ErrorIs(t, err, mail.ErrMailNoRecipient)
+
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("err - not found", func(t *testing.T) {
+		client := mail.NewFileClient(mail.FileConfig{Dir: t.TempDir()})
+
+		for _, key := range []string{"missing", "../secret", ".mail-1", ""} {
+			_, err := client.Get(key)
+			assert.ErrorIs(t, err, mail.ErrCapturedMailNotFound, key)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package mail
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLToText converts the HTML mail content to the plain text alternative. The head, style and script elements are dropped,
+// the block elements are separated by blank lines, the list items are prefixed by `- `, and the link target is written
+// after the link text when they differ
+func HTMLToText(content string) string {
+	z := html.NewTokenizer(strings.NewReader(content))
+	b := &textBuilder{}
+
+	skip := 0
+	var links []link
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return b.String()
+
+		case html.TextToken:
+			if skip == 0 {
+				b.writeText(string(z.Text()))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			attrs := map[string]string{}
+			for hasAttr {
+				var k, v []byte
+				k, v, hasAttr = z.TagAttr()
+				attrs[string(k)] = string(v)
+			}
+
+			switch tag := atom.Lookup(name); tag {
+			case atom.Head, atom.Style, atom.Script, atom.Title:
+				if tt == html.StartTagToken {
+					skip++
+				}
+			case atom.Br:
+				b.newline(1)
+			case atom.Li:
+				b.newline(1)
+				b.write("- ")
+			case atom.Td, atom.Th:
+				b.space()
+			case atom.Hr:
+				b.newline(2)
+				b.write("---")
+				b.newline(2)
+			case atom.Img:
+				b.writeText(attrs["alt"])
+			case atom.A:
+				if tt == html.StartTagToken {
+					links = append(links, link{href: attrs["href"], start: b.len()})
+				}
+			default:
+				if isBlockElement(tag) {
+					b.newline(2)
+				}
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch tag := atom.Lookup(name); tag {
+			case atom.Head, atom.Style, atom.Script, atom.Title:
+				if skip > 0 {
+					skip--
+				}
+			case atom.Tr:
+				b.newline(1)
+			case atom.A:
+				if len(links) == 0 {
+					continue
+				}
+
+				l := links[len(links)-1]
+				links = links[:len(links)-1]
+				b.writeLinkTarget(l)
+			default:
+				if isBlockElement(tag) {
+					b.newline(2)
+				}
+			}
+		}
+	}
+}
+
+func isBlockElement(tag atom.Atom) bool {
+	switch tag {
+	case atom.P, atom.Div, atom.Table, atom.Ul, atom.Ol, atom.Blockquote, atom.Pre, atom.Section, atom.Article,
+		atom.Header, atom.Footer, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		return true
+	}
+
+	return false
+}
+
+type link struct {
+	href  string
+	start int
+}
+
+// textBuilder collapses the whitespaces like the browser does, and limits the consecutive new lines
+type textBuilder struct {
+	sb       strings.Builder
+	newlines int
+	pending  bool
+}
+
+func (b *textBuilder) len() int {
+	return b.sb.Len()
+}
+
+func (b *textBuilder) write(s string) {
+	if b.pending && b.newlines == 0 && b.sb.Len() > 0 {
+		b.sb.WriteByte(' ')
+	}
+
+	b.pending = false
+	b.newlines = 0
+	b.sb.WriteString(s)
+}
+
+func (b *textBuilder) writeText(s string) {
+	for i, word := range strings.FieldsFunc(s, unicode.IsSpace) {
+		if i == 0 && len(s) > 0 && unicode.IsSpace(rune(s[0])) {
+			b.space()
+		}
+
+		if i > 0 {
+			b.space()
+		}
+
+		b.write(word)
+	}
+
+	if len(s) > 0 && unicode.IsSpace(rune(s[len(s)-1])) {
+		b.space()
+	}
+}
+
+func (b *textBuilder) space() {
+	b.pending = true
+}
+
+func (b *textBuilder) newline(n int) {
+	b.pending = false
+	if b.sb.Len() == 0 {
+		return
+	}
+
+	for b.newlines < n {
+		b.sb.WriteByte('\n')
+		b.newlines++
+	}
+}
+
+func (b *textBuilder) writeLinkTarget(l link) {
+	if l.href == "" || strings.HasPrefix(l.href, "#") {
+		return
+	}
+
+	text := strings.TrimSpace(b.sb.String()[l.start:])
+	target := strings.TrimPrefix(l.href, "mailto:")
+	if text == target || text == l.href {
+		return
+	}
+
+	if text == "" {
+		b.write(target)
+		return
+	}
+
+	b.space()
+	b.write("(" + target + ")")
+}
+
+func (b *textBuilder) String() string {
+	return strings.TrimSpace(b.sb.String())
+}
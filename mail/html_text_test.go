@@ -0,0 +1,35 @@
+package mail_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+func TestHTMLToText(t *testing.T) {
+	content := `<html><head><title>Ignored</title><style>p { color: red; }</style></head>
+<body>
+	<h1>Order   confirmed</h1>
+	<p>Hi <b>Tom</b>,<br>your order is on the way.</p>
+	<ul>
+		<li>Item A</li>
+		<li>Item B</li>
+	</ul>
+	<table><tr><td>Total</td><td>Rp 10.000</td></tr></table>
+	<p><a href="https://example.com/orders/1">Track order</a> or mail <a href="mailto:cs@example.com">cs@example.com</a></p>
+	<img src="logo.png" alt="Sweet Go">
+	<script>alert("ignored")</script>
+</body></html>`
+
+	expected := "Order confirmed\n\n" +
+		"Hi Tom,\nyour order is on the way.\n\n" +
+		"- Item A\n- Item B\n\n" +
+		"Total Rp 10.000\n\n" +
+		"Track order (https://example.com/orders/1) or mail cs@example.com\n\n" +
+		"Sweet Go"
+
+	assert.Equal(t, expected, mail.HTMLToText(content))
+	assert.Equal(t, "Tom & Jerry", mail.HTMLToText("Tom &amp; Jerry"))
+	assert.Empty(t, mail.HTMLToText(""))
+}
@@ -0,0 +1,146 @@
+package mail
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// InMemorySignature in memory client signature
+const InMemorySignature ClientSignature = "in memory"
+
+// InMemory is the client recording the mails instead of sending them, to assert the sent mails in the tests
+// or to be browsed using NewViewer in local development. Safe for concurrent use
+type InMemory struct {
+	mu    sync.RWMutex
+	mails []*CapturedMail
+	seq   int
+}
+
+// NewInMemoryClient create new in memory client. The sender is DefaultCatcherSenderEmail unless Mail.From is set
+func NewInMemoryClient() *InMemory {
+	return &InMemory{}
+}
+
+// SendEmail records a copy of the mail, returning the Message-ID. Returning error if the mail is invalid
+func (c *InMemory) SendEmail(_ context.Context, mail *Mail) (string, error) {
+	captured, err := captureMail(mail, "")
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	captured.Key = strconv.Itoa(c.seq)
+	c.mails = append(c.mails, captured)
+
+	return captured.MessageID, nil
+}
+
+// GetClientName returning client name signature
+func (c *InMemory) GetClientName() ClientSignature {
+	return InMemorySignature
+}
+
+// List returns the recorded mails, the latest first
+func (c *InMemory) List() ([]*CapturedMail, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	res := make([]*CapturedMail, 0, len(c.mails))
+	for i := len(c.mails) - 1; i >= 0; i-- {
+		res = append(res, c.mails[i])
+	}
+
+	return res, nil
+}
+
+// Get returns the recorded mail by the key
+func (c *InMemory) Get(key string) (*CapturedMail, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, m := range c.mails {
+		if m.Key == key {
+			return m, nil
+		}
+	}
+
+	return nil, ErrCapturedMailNotFound
+}
+
+// Sent returns the recorded mails in the sent order
+func (c *InMemory) Sent() []*CapturedMail {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]*CapturedMail(nil), c.mails...)
+}
+
+// Count returns the number of the recorded mails
+func (c *InMemory) Count() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.mails)
+}
+
+// Last returns the last recorded mail, or nil if none
+func (c *InMemory) Last() *CapturedMail {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.mails) == 0 {
+		return nil
+	}
+
+	return c.mails[len(c.mails)-1]
+}
+
+// Find returns the recorded mails matching fn in the sent order
+func (c *InMemory) Find(fn func(mail *Mail) bool) []*CapturedMail {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var res []*CapturedMail
+	for _, m := range c.mails {
+		if fn(m.Mail) {
+			res = append(res, m)
+		}
+	}
+
+	return res
+}
+
+// FindByRecipient returns the recorded mails sent to the email as To, Cc or Bcc. Email is case insensitive
+func (c *InMemory) FindByRecipient(email string) []*CapturedMail {
+	return c.Find(func(mail *Mail) bool {
+		for _, receipients := range [][]GenericReceipient{mail.To, mail.Cc, mail.Bcc} {
+			for _, r := range receipients {
+				if strings.EqualFold(r.Email, email) {
+					return true
+				}
+			}
+		}
+
+		return false
+	})
+}
+
+// FindBySubject returns the recorded mails whose subject contains the substr
+func (c *InMemory) FindBySubject(substr string) []*CapturedMail {
+	return c.Find(func(mail *Mail) bool {
+		return strings.Contains(mail.Subject, substr)
+	})
+}
+
+// Reset removes all the recorded mails
+func (c *InMemory) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.mails = nil
+}
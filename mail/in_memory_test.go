@@ -0,0 +1,126 @@
+package mail_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+func TestInMemory(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("ok - record and query", func(t *testing.T) {
+		client := mail.NewInMemoryClient()
+		assert.Equal(t, mail.InMemorySignature, client.GetClientName())
+		assert.Nil(t, client.Last())
+
+		welcome := &mail.Mail{
+			ID:          "welcome",
+			To:          []mail.GenericReceipient{{Email: "Tom@example.com"}},
+			Subject:     "Welcome Tom",
+			HTMLContent: "<p>Welcome</p>",
+		}
+		invoice := &mail.Mail{
+			ID:      "invoice",
+			To:      []mail.GenericReceipient{{Email: "jerry@example.com"}},
+			Bcc:     []mail.GenericReceipient{{Email: "tom@example.com"}},
+			Subject: "Your invoice",
+		}
+
+		messageID, err := client.SendEmail(ctx, welcome)
+		assert.NoError(t, err)
+		assert.Equal(t, "<welcome@localhost>", messageID)
+
+		_, err = client.SendEmail(ctx, invoice)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, client.Count())
+		assert.Equal(t, invoice, client.Last().Mail)
+
+		sent := client.Sent()
+		assert.Len(t, sent, 2)
+		assert.Equal(t, welcome, sent[0].Mail)
+		assert.Contains(t, string(sent[0].Raw), "Message-ID: <welcome@localhost>")
+
+		list, err := client.List()
+		assert.NoError(t, err)
+		assert.Equal(t, []*mail.CapturedMail{sent[1], sent[0]}, list)
+
+		captured, err := client.Get(sent[0].Key)
+		assert.NoError(t, err)
+		assert.Equal(t, sent[0], captured)
+
+		assert.Len(t, client.FindByRecipient("tom@example.com"), 2)
+		assert.Len(t, client.FindByRecipient("jerry@example.com"), 1)
+		assert.Empty(t, client.FindByRecipient("spike@example.com"))
+		assert.Equal(t, []*mail.CapturedMail{sent[1]}, client.FindBySubject("invoice"))
+		assert.Equal(t, []*mail.CapturedMail{sent[0]}, client.Find(func(m *mail.Mail) bool {
+			return m.HTMLContent != ""
+		}))
+
+		client.Reset()
+		assert.Equal(t, 0, client.Count())
+
+		_, err = client.Get(sent[0].Key)
+		assert.ErrorIs(t, err, mail.ErrCapturedMailNotFound)
+
+		// the keys are not reused after reset
+		_, err = client.SendEmail(ctx, welcome)
+		assert.NoError(t, err)
+		assert.NotEqual(t, sent[0].Key, client.Last().Key)
+	})
+
+	t.Run("ok - concurrent send", func(t *testing.T) {
+		client := mail.NewInMemoryClient()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := client.SendEmail(ctx, &mail.Mail{To: []mail.GenericReceipient{{Email: "tom@example.com"}}})
+				assert.NoError(t, err)
+			}()
+		}
+
+		wg.Wait()
+		assert.Equal(t, 10, client.Count())
+	})
+
+	t.Run("ok - modifying sent mail does not change the recorded one", func(t *testing.T) {
+		client := mail.NewInMemoryClient()
+		newMail := func() *mail.Mail {
+			return &mail.Mail{
+				To:            []mail.GenericReceipient{{Email: "tom@example.com"}},
+				From:          &mail.GenericReceipient{Email: "noreply@example.com"},
+				Subject:       "Welcome",
+				Attachments:   []mail.Attachment{{Filename: "a.txt", Content: []byte("hello")}},
+				CustomHeaders: map[string]string{"X-Campaign": "welcome"},
+				Tags:          []string{"welcome"},
+			}
+		}
+
+		m := newMail()
+		_, err := client.SendEmail(ctx, m)
+		assert.NoError(t, err)
+
+		m.To[0].Email = "jerry@example.com"
+		m.From.Email = "spike@example.com"
+		m.Attachments[0].Content[0] = 'j'
+		m.CustomHeaders["X-Campaign"] = "changed"
+		m.Tags[0] = "changed"
+
+		assert.Equal(t, newMail(), client.Last().Mail)
+	})
+
+	t.Run("err - invalid mail", func(t *testing.T) {
+		client := mail.NewInMemoryClient()
+
+		_, err := client.SendEmail(ctx, &mail.Mail{Subject: "no recipient"})
+		assert.ErrorIs(t, err, mail.ErrMailNoRecipient)
+		assert.Equal(t, 0, client.Count())
+	})
+}
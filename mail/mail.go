@@ -1,7 +1,14 @@
 package mail
 
 import (
+	"encoding/base64"
+	"fmt"
+	netmail "net/mail"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
 	"github.com/sendinblue/APIv3-go-library/lib"
+	"github.com/sweet-go/stdlib/requestid"
 	"gopkg.in/guregu/null.v4"
 )
 
@@ -11,6 +18,29 @@ type GenericReceipient struct {
 	Email string `json:"email"`
 }
 
+// String formats the receipient as RFC 5322 address, e.g. `"Name" <email@example.com>`
+func (r GenericReceipient) String() string {
+	return (&netmail.Address{Name: r.Name, Address: r.Email}).String()
+}
+
+// Attachment is the file attached to the mail
+type Attachment struct {
+	Filename string `json:"filename"`
+
+	// ContentType if empty, will be detected from the content
+	ContentType string `json:"content_type,omitempty"`
+	Content     []byte `json:"content"`
+}
+
+// MIMEType returns the ContentType, or the detected one if empty
+func (a *Attachment) MIMEType() string {
+	if a.ContentType != "" {
+		return a.ContentType
+	}
+
+	return mimetype.Detect(a.Content).String()
+}
+
 // Mail is datatype for mail
 type Mail struct {
 	ID          string              `json:"id"`
@@ -20,6 +50,72 @@ type Mail struct {
 	HTMLContent string              `json:"html_content"`
 	Subject     string              `json:"subject"`
 	Metadata    null.String         `json:"metadata,omitempty"`
+
+	// TextContent is the plain text alternative of HTMLContent, shown by the clients not rendering HTML
+	TextContent string `json:"text_content,omitempty"`
+
+	// From if nil, will use the sender configured in the client
+	From    *GenericReceipient `json:"from,omitempty"`
+	ReplyTo *GenericReceipient `json:"reply_to,omitempty"`
+
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	// InlineImages are referred in HTMLContent by the filename, e.g. `<img src="cid:logo.png">`
+	InlineImages []Attachment `json:"inline_images,omitempty"`
+
+	// CustomHeaders are sent alongside the mail, see Headers
+	CustomHeaders map[string]string `json:"custom_headers,omitempty"`
+
+	// Tags are used by the provider to group the mails in the analytics. Mailgun allows up to 3 tags
+	Tags []string `json:"tags,omitempty"`
+
+	// RequestID is the request ID which trigger the mail. Sent as X-Request-ID header to trace the mail end-to-end.
+	// Will be filled from the context by Utility.SendEmail if empty
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// clone returns the deep copy of the mail, so the copy is not changed by modifying the slices and maps of the mail
+func (m *Mail) clone() *Mail {
+	c := *m
+	c.To = append([]GenericReceipient(nil), m.To...)
+	c.Cc = append([]GenericReceipient(nil), m.Cc...)
+	c.Bcc = append([]GenericReceipient(nil), m.Bcc...)
+	c.Attachments = cloneAttachments(m.Attachments)
+	c.InlineImages = cloneAttachments(m.InlineImages)
+	c.Tags = append([]string(nil), m.Tags...)
+
+	if m.From != nil {
+		from := *m.From
+		c.From = &from
+	}
+
+	if m.ReplyTo != nil {
+		replyTo := *m.ReplyTo
+		c.ReplyTo = &replyTo
+	}
+
+	if m.CustomHeaders != nil {
+		c.CustomHeaders = make(map[string]string, len(m.CustomHeaders))
+		for k, v := range m.CustomHeaders {
+			c.CustomHeaders[k] = v
+		}
+	}
+
+	return &c
+}
+
+func cloneAttachments(attachments []Attachment) []Attachment {
+	if attachments == nil {
+		return nil
+	}
+
+	res := make([]Attachment, len(attachments))
+	for i, a := range attachments {
+		a.Content = append([]byte(nil), a.Content...)
+		res[i] = a
+	}
+
+	return res
 }
 
 // SendInBlueTo get send in blue SendSmtpEmailTo
@@ -64,6 +160,51 @@ func (m *Mail) SendInBlueBcc() []lib.SendSmtpEmailBcc {
 	return bcc
 }
 
+// SendInBlueAttachment get send in blue SendSmtpEmailAttachment with base64 encoded content
+func (m *Mail) SendInBlueAttachment() []lib.SendSmtpEmailAttachment {
+	var attachments []lib.SendSmtpEmailAttachment
+
+	for _, a := range m.Attachments {
+		attachments = append(attachments, lib.SendSmtpEmailAttachment{
+			Name:    a.Filename,
+			Content: base64.StdEncoding.EncodeToString(a.Content),
+		})
+	}
+
+	return attachments
+}
+
+// SendInBlueHTMLContent get the HTML content with the inline images references replaced by data URI
+func (m *Mail) SendInBlueHTMLContent() string {
+	return m.htmlContentWithDataURI()
+}
+
+// htmlContentWithDataURI returns the HTML content with the inline images references replaced by data URI,
+// for the clients not supporting Content-ID references
+func (m *Mail) htmlContentWithDataURI() string {
+	content := m.HTMLContent
+	for _, a := range m.InlineImages {
+		dataURI := "data:" + a.MIMEType() + ";base64," + base64.StdEncoding.EncodeToString(a.Content)
+		content = strings.ReplaceAll(content, "cid:"+a.Filename, dataURI)
+	}
+
+	return content
+}
+
+// sender returns From, or the parsed serverSenderEmail if From is nil
+func (m *Mail) sender(serverSenderEmail string) (GenericReceipient, error) {
+	if m.From != nil {
+		return *m.From, nil
+	}
+
+	address, err := netmail.ParseAddress(serverSenderEmail)
+	if err != nil {
+		return GenericReceipient{}, fmt.Errorf("invalid server sender email: %w", err)
+	}
+
+	return GenericReceipient{Name: address.Name, Email: address.Address}, nil
+}
+
 // MailgunTo convert to to mailgun compatible to
 func (m *Mail) MailgunTo() []string {
 	var res []string
@@ -93,3 +234,59 @@ func (m *Mail) MailgunBCC() []string {
 
 	return bcc
 }
+
+// Headers returns the custom headers to be sent alongside the mail, including X-Request-ID header
+// unless already set in CustomHeaders
+func (m *Mail) Headers() map[string]string {
+	headers := map[string]string{}
+	for k, v := range m.CustomHeaders {
+		headers[k] = v
+	}
+
+	if _, ok := headers[requestid.Header]; !ok && m.RequestID != "" {
+		headers[requestid.Header] = m.RequestID
+	}
+
+	return headers
+}
+
+// Size returns the bytes of the contents, and the base64 encoded attachments and inline images including
+// the line breaks, since they are sent encoded. The MIME headers are not counted
+func (m *Mail) Size() int64 {
+	size := int64(len(m.HTMLContent) + len(m.TextContent))
+	for _, attachments := range [][]Attachment{m.Attachments, m.InlineImages} {
+		for _, a := range attachments {
+			size += encodedAttachmentSize(len(a.Content))
+		}
+	}
+
+	return size
+}
+
+// encodedAttachmentSize returns the base64 encoded size of n bytes, broken into 76 characters lines by CRLF
+func encodedAttachmentSize(n int) int64 {
+	encoded := int64(base64.StdEncoding.EncodedLen(n))
+	return encoded + 2*((encoded+75)/76)
+}
+
+// Validate checks the mail has recipient, every attachment and inline image has filename and content,
+// and the Size doesn't exceed maxSize. Zero maxSize means no limit
+func (m *Mail) Validate(maxSize int64) error {
+	if len(m.To)+len(m.Cc)+len(m.Bcc) == 0 {
+		return ErrMailNoRecipient
+	}
+
+	for _, attachments := range [][]Attachment{m.Attachments, m.InlineImages} {
+		for _, a := range attachments {
+			if a.Filename == "" || len(a.Content) == 0 {
+				return ErrInvalidAttachment
+			}
+		}
+	}
+
+	if size := m.Size(); maxSize > 0 && size > maxSize {
+		return fmt.Errorf("%w: %d bytes, max %d bytes", ErrMailTooLarge, size, maxSize)
+	}
+
+	return nil
+}
@@ -1,8 +1,10 @@
 package mail_test
 
 import (
+	"encoding/base64"
 	"testing"
 
+	"github.com/sendinblue/APIv3-go-library/lib"
 	"github.com/stretchr/testify/assert"
 	"github.com/sweet-go/stdlib/helper"
 	"github.com/sweet-go/stdlib/mail"
@@ -70,4 +72,56 @@ func TestMail(t *testing.T) {
 
 		assert.Equal(t, len(res), 1)
 	})
+
+	t.Run("headers", func(t *testing.T) {
+		assert.Empty(t, m.Headers())
+
+		withRequestID := m
+		withRequestID.RequestID = "request-id"
+		assert.Equal(t, map[string]string{"X-Request-Id": "request-id"}, withRequestID.Headers())
+
+		withRequestID.CustomHeaders = map[string]string{"X-Request-Id": "custom-id", "X-Invoice-Id": "1"}
+		assert.Equal(t, map[string]string{"X-Request-Id": "custom-id", "X-Invoice-Id": "1"}, withRequestID.Headers())
+	})
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+
+	t.Run("receipient string", func(t *testing.T) {
+		assert.Equal(t, `"test name" <test@example.com>`, mail.GenericReceipient{Name: "test name", Email: "test@example.com"}.String())
+		assert.Equal(t, `<test@example.com>`, mail.GenericReceipient{Email: "test@example.com"}.String())
+	})
+
+	t.Run("attachment mime type", func(t *testing.T) {
+		assert.Equal(t, "image/png", (&mail.Attachment{Content: png}).MIMEType())
+		assert.Equal(t, "application/pdf", (&mail.Attachment{ContentType: "application/pdf", Content: png}).MIMEType())
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		withAttachment := m
+		withAttachment.Attachments = []mail.Attachment{{Filename: "invoice.pdf", Content: []byte("pdf")}}
+		withAttachment.InlineImages = []mail.Attachment{{Filename: "logo.png", Content: png}}
+
+		assert.EqualValues(t, len(m.HTMLContent)+
+			len(base64.StdEncoding.EncodeToString([]byte("pdf")))+2+
+			len(base64.StdEncoding.EncodeToString(png))+2*((len(base64.StdEncoding.EncodeToString(png))+75)/76),
+			withAttachment.Size())
+		assert.NoError(t, withAttachment.Validate(0))
+		assert.NoError(t, withAttachment.Validate(withAttachment.Size()))
+		assert.ErrorIs(t, withAttachment.Validate(withAttachment.Size()-1), mail.ErrMailTooLarge)
+
+		withAttachment.InlineImages = []mail.Attachment{{Filename: "logo.png"}}
+		assert.ErrorIs(t, withAttachment.Validate(0), mail.ErrInvalidAttachment)
+
+		assert.ErrorIs(t, (&mail.Mail{}).Validate(0), mail.ErrMailNoRecipient)
+	})
+
+	t.Run("send in blue attachment and inline images", func(t *testing.T) {
+		withAttachment := m
+		withAttachment.HTMLContent = `<img src="cid:logo.png">`
+		withAttachment.Attachments = []mail.Attachment{{Filename: "invoice.pdf", Content: []byte("pdf")}}
+		withAttachment.InlineImages = []mail.Attachment{{Filename: "logo.png", Content: png}}
+
+		assert.Equal(t, []lib.SendSmtpEmailAttachment{{Name: "invoice.pdf", Content: "cGRm"}}, withAttachment.SendInBlueAttachment())
+		assert.Equal(t, `<img src="data:image/png;base64,iVBORw0KGgo=">`, withAttachment.SendInBlueHTMLContent())
+	})
 }
@@ -3,6 +3,15 @@ package mail
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sweet-go/stdlib/internal/circuitbreaker"
+	"github.com/sweet-go/stdlib/requestid"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
 // ClientSignature signature for every registered mailing client
@@ -11,8 +20,38 @@ type ClientSignature string
 // Utility mail utility interface
 type Utility interface {
 	// SendEmail send email using any available mailinng client. Returning metadata, client signature and error
-	// will retry using the next available client if the previous returning error
+	// will retry using the next available client if the previous returning error.
+	// The request ID found in ctx will be stamped to the mail if Mail.RequestID is empty
 	SendEmail(ctx context.Context, mail *Mail) (string, ClientSignature, error)
+
+	// SendEmailWithResult same as SendEmail, but returning the result reporting every attempt made
+	// and every dropped recipient. The result is returned even on error
+	SendEmailWithResult(ctx context.Context, mail *Mail) (*SendResult, error)
+}
+
+// SendAttempt is the attempt to send the mail using the client
+type SendAttempt struct {
+	Client   ClientSignature
+	Metadata string
+	Err      error
+
+	// Class is the class of Err, empty when succeeded or skipped
+	Class ErrorClass
+
+	// Skipped is true when the client is not used, because its circuit is open or it is rate limited
+	Skipped  bool
+	Duration time.Duration
+}
+
+// SendResult is the result of sending the mail, reporting every attempt in order
+type SendResult struct {
+	// Metadata and Client are of the successful attempt, empty if all attempts failed
+	Metadata string
+	Client   ClientSignature
+	Attempts []SendAttempt
+
+	// DroppedRecipients are the invalid or suppressed recipients not sent to
+	DroppedRecipients []DroppedRecipient
 }
 
 // Client must be implemented by any client to be registered in mail utility
@@ -26,26 +65,223 @@ type Client interface {
 
 type mail struct {
 	clients []Client
+
+	// tracer if nil, tracing is disabled
+	tracer trace.Tracer
+
+	strategy            Strategy
+	classifier          ErrorClassifier
+	failoverOnAmbiguous bool
+
+	// breaker if nil, circuit breaker is disabled
+	breaker  *circuitbreaker.Breaker
+	limiters map[ClientSignature]*rate.Limiter
+
+	// validator and suppressions if nil, the recipients are not filtered
+	validator    RecipientValidator
+	suppressions SuppressionList
+}
+
+// UtilityOpts is the options for NewUtilityWithOpts
+type UtilityOpts struct {
+	// TracerProvider enables tracing when not nil. A span is created for every sent mail
+	// and a child span for every client attempt
+	TracerProvider trace.TracerProvider
+
+	// Strategy if nil, will use NewPriorityStrategy
+	Strategy Strategy
+
+	// ErrorClassifier if nil, will use ClassifyError. The next client is tried only on the retryable error
+	ErrorClassifier ErrorClassifier
+
+	// FailoverOnAmbiguousError tries the next client on the ambiguous error, at the risk of sending the duplicate.
+	// Otherwise ErrMailDeliveryUnknown is returned
+	FailoverOnAmbiguousError bool
+
+	// CircuitBreaker enables the per client circuit breaker when not nil. The client with open circuit is skipped
+	CircuitBreaker *CircuitBreakerOpts
+
+	// RateLimits is the rate limit by the client signature. The rate limited client is skipped without waiting
+	RateLimits map[ClientSignature]RateLimit
+
+	// RecipientValidator drops the invalid To, Cc and Bcc recipients before sending when not nil
+	RecipientValidator RecipientValidator
+
+	// SuppressionList drops the suppressed To, Cc and Bcc recipients before sending when not nil.
+	// The mail is not sent if the list fails, to never send to the suppressed recipient
+	SuppressionList SuppressionList
 }
 
 // NewUtility return new mail utility
 func NewUtility(clients ...Client) Utility {
-	return &mail{
-		clients,
+	return NewUtilityWithOpts(nil, clients...)
+}
+
+// NewUtilityWithOpts return new mail utility with options
+func NewUtilityWithOpts(opts *UtilityOpts, clients ...Client) Utility {
+	if opts == nil {
+		opts = &UtilityOpts{}
+	}
+
+	m := &mail{
+		clients:             clients,
+		strategy:            opts.Strategy,
+		classifier:          opts.ErrorClassifier,
+		failoverOnAmbiguous: opts.FailoverOnAmbiguousError,
+		limiters:            newRateLimiters(opts.RateLimits),
+		validator:           opts.RecipientValidator,
+		suppressions:        opts.SuppressionList,
+	}
+
+	if opts.TracerProvider != nil {
+		m.tracer = tracing.Tracer(opts.TracerProvider)
+	}
+
+	if m.strategy == nil {
+		m.strategy = NewPriorityStrategy()
+	}
+
+	if m.classifier == nil {
+		m.classifier = ClassifyError
 	}
+
+	if opts.CircuitBreaker != nil {
+		m.breaker = circuitbreaker.New(opts.CircuitBreaker)
+	}
+
+	return m
 }
 
 func (m *mail) SendEmail(ctx context.Context, mail *Mail) (string, ClientSignature, error) {
+	res, err := m.SendEmailWithResult(ctx, mail)
+	if err != nil {
+		return "", "", err
+	}
+
+	return res.Metadata, res.Client, nil
+}
+
+func (m *mail) SendEmailWithResult(ctx context.Context, mail *Mail) (_ *SendResult, err error) {
+	if m.tracer != nil {
+		var span trace.Span
+		ctx, span = m.tracer.Start(ctx, "mail send", trace.WithAttributes(
+			attribute.String("mail.id", mail.ID),
+			attribute.Int("mail.recipients", len(mail.To)+len(mail.Cc)+len(mail.Bcc)),
+		))
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+	}
+
+	if rid := requestid.FromContext(ctx); mail.RequestID == "" && rid != "" {
+		stamped := *mail
+		stamped.RequestID = rid
+		mail = &stamped
+	}
+
+	res := &SendResult{}
 	fullErr := errors.New("send email error: ")
-	for _, client := range m.clients {
-		metadata, err := client.SendEmail(ctx, mail)
 
-		if err == nil {
-			return metadata, client.GetClientName(), nil
+	filtered, dropped, err := m.filterRecipients(ctx, mail)
+	if err != nil {
+		return res, errors.Join(fullErr, err)
+	}
+
+	res.DroppedRecipients = dropped
+	if len(dropped) > 0 {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int("mail.dropped_recipients", len(dropped)))
+		if len(filtered.To)+len(filtered.Cc)+len(filtered.Bcc) == 0 {
+			return res, errors.Join(fullErr, ErrAllRecipientsDropped)
 		}
+	}
+
+	mail = filtered
+	for _, client := range m.strategy.Order(m.clients) {
+		if ctx.Err() != nil {
+			return res, errors.Join(fullErr, ctx.Err())
+		}
+
+		attempt := m.attempt(ctx, client, mail)
+		res.Attempts = append(res.Attempts, attempt)
+
+		if attempt.Skipped {
+			fullErr = errors.Join(fullErr, attempt.Err)
+			continue
+		}
+
+		if attempt.Err == nil {
+			res.Metadata = attempt.Metadata
+			res.Client = attempt.Client
+			return res, nil
+		}
+
+		fullErr = errors.Join(fullErr, attempt.Err)
+
+		switch {
+		case ctx.Err() != nil:
+			return res, fullErr
+		case attempt.Class == ErrorClassPermanent:
+			return res, fullErr
+		case attempt.Class == ErrorClassAmbiguous && !m.failoverOnAmbiguous:
+			return res, errors.Join(fullErr, ErrMailDeliveryUnknown)
+		}
+	}
+
+	return res, fullErr
+}
+
+// attempt sends the mail using the client unless its circuit is open or it is rate limited
+func (m *mail) attempt(ctx context.Context, client Client, mail *Mail) SendAttempt {
+	attempt := SendAttempt{Client: client.GetClientName()}
+	if !m.breaker.Allow(string(attempt.Client)) {
+		attempt.Skipped = true
+		attempt.Err = fmt.Errorf("%w: %s", ErrMailCircuitOpen, attempt.Client)
+		return attempt
+	}
+
+	if limiter, ok := m.limiters[attempt.Client]; ok && !limiter.Allow() {
+		m.breaker.Release(string(attempt.Client))
+		attempt.Skipped = true
+		attempt.Err = fmt.Errorf("%w: %s", ErrMailRateLimited, attempt.Client)
+		return attempt
+	}
+
+	start := time.Now()
+	attempt.Metadata, attempt.Err = m.sendUsingClient(ctx, client, mail)
+	attempt.Duration = time.Since(start)
+
+	if attempt.Err == nil {
+		m.breaker.Success(string(attempt.Client))
+		return attempt
+	}
+
+	attempt.Class = m.classifier(attempt.Err)
+
+	switch {
+	case ctx.Err() != nil:
+		// cancelled by the caller, the client is not to blame
+		m.breaker.Release(string(attempt.Client))
+	case attempt.Class == ErrorClassPermanent:
+		// the client responded properly to the invalid mail
+		m.breaker.Success(string(attempt.Client))
+	default:
+		m.breaker.Failure(string(attempt.Client))
+	}
+
+	return attempt
+}
 
-		fullErr = errors.Join(fullErr, err)
+func (m *mail) sendUsingClient(ctx context.Context, client Client, mail *Mail) (metadata string, err error) {
+	if m.tracer != nil {
+		var span trace.Span
+		ctx, span = m.tracer.Start(ctx, "mail send "+string(client.GetClientName()),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("mail.client", string(client.GetClientName()))),
+		)
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
 	}
 
-	return "", "", fullErr
+	return client.SendEmail(ctx, mail)
 }
@@ -9,6 +9,10 @@ import (
 	"github.com/sweet-go/stdlib/helper"
 	"github.com/sweet-go/stdlib/mail"
 	mail_mock "github.com/sweet-go/stdlib/mail/mock"
+	"github.com/sweet-go/stdlib/requestid"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestMailUtility_SendEmail(t *testing.T) {
@@ -19,6 +23,10 @@ func TestMailUtility_SendEmail(t *testing.T) {
 
 	utility := mail.NewUtility(sendInBlue, mailgun)
 
+	// every attempt is reported by the client name
+	sendInBlue.EXPECT().GetClientName().Return(mail.SendInBlueSignature).AnyTimes()
+	mailgun.EXPECT().GetClientName().Return(mail.MailgunSignature).AnyTimes()
+
 	ctx := context.TODO()
 
 	m := &mail.Mail{
@@ -37,7 +45,6 @@ func TestMailUtility_SendEmail(t *testing.T) {
 
 	t.Run("ok - first client", func(t *testing.T) {
 		sendInBlue.EXPECT().SendEmail(ctx, m).Return(sendInBlueMD, nil)
-		sendInBlue.EXPECT().GetClientName().Return(mail.SendInBlueSignature)
 
 		metadata, signature, err := utility.SendEmail(ctx, m)
 
@@ -49,7 +56,6 @@ func TestMailUtility_SendEmail(t *testing.T) {
 	t.Run("ok - second client", func(t *testing.T) {
 		sendInBlue.EXPECT().SendEmail(ctx, m).Return("", mail.ErrSendInBlueNotActivated)
 		mailgun.EXPECT().SendEmail(ctx, m).Return(mailgunMD, nil)
-		mailgun.EXPECT().GetClientName().Return(mail.MailgunSignature)
 
 		metadata, signature, err := utility.SendEmail(ctx, m)
 
@@ -66,4 +72,77 @@ func TestMailUtility_SendEmail(t *testing.T) {
 
 		assert.Error(t, err)
 	})
+
+	t.Run("stamp request id from context", func(t *testing.T) {
+		ctxWithRID := requestid.NewContext(ctx, "request-id")
+		stamped := *m
+		stamped.RequestID = "request-id"
+
+		sendInBlue.EXPECT().SendEmail(ctxWithRID, &stamped).Return(sendInBlueMD, nil)
+
+		_, _, err := utility.SendEmail(ctxWithRID, m)
+		assert.NoError(t, err)
+		assert.Empty(t, m.RequestID)
+	})
+}
+
+func TestMailUtility_Tracing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	sendInBlue := mail_mock.NewMockClient(ctrl)
+	mailgun := mail_mock.NewMockClient(ctrl)
+
+	tp, exporter := tracing.NewInMemoryTracerProvider("test-service")
+	utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{TracerProvider: tp}, sendInBlue, mailgun)
+
+	m := &mail.Mail{
+		ID: helper.GenerateID(),
+		To: []mail.GenericReceipient{
+			{
+				Email: "test.email@mail.test",
+			},
+		},
+		Subject: "Testing",
+	}
+
+	sendInBlue.EXPECT().GetClientName().Return(mail.SendInBlueSignature).AnyTimes()
+	mailgun.EXPECT().GetClientName().Return(mail.MailgunSignature).AnyTimes()
+
+	t.Run("span per client attempt", func(t *testing.T) {
+		exporter.Reset()
+
+		sendInBlue.EXPECT().SendEmail(gomock.Any(), m).DoAndReturn(func(ctx context.Context, _ *mail.Mail) (string, error) {
+			assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+			return "", mail.ErrSendInBlueNotActivated
+		})
+		mailgun.EXPECT().SendEmail(gomock.Any(), m).Return("metadata", nil)
+
+		_, signature, err := utility.SendEmail(context.TODO(), m)
+		assert.NoError(t, err)
+		assert.Equal(t, mail.MailgunSignature, signature)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 3)
+		assert.Equal(t, "mail send "+string(mail.SendInBlueSignature), spans[0].Name)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, "mail send "+string(mail.MailgunSignature), spans[1].Name)
+		assert.Equal(t, codes.Unset, spans[1].Status.Code)
+		assert.Equal(t, "mail send", spans[2].Name)
+		assert.Equal(t, spans[2].SpanContext.SpanID(), spans[0].Parent.SpanID())
+		assert.Equal(t, codes.Unset, spans[2].Status.Code)
+	})
+
+	t.Run("all client err", func(t *testing.T) {
+		exporter.Reset()
+
+		sendInBlue.EXPECT().SendEmail(gomock.Any(), m).Return("", mail.ErrSendInBlueNotActivated)
+		mailgun.EXPECT().SendEmail(gomock.Any(), m).Return("", mail.ErrMailgunNotActivated)
+
+		_, _, err := utility.SendEmail(context.TODO(), m)
+		assert.Error(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 3)
+		assert.Equal(t, codes.Error, spans[2].Status.Code)
+	})
 }
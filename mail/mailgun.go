@@ -2,7 +2,9 @@
 package mail
 
 import (
+	"bytes"
 	"context"
+	"io"
 
 	mailgun "github.com/mailgun/mailgun-go/v4"
 )
@@ -10,12 +12,18 @@ import (
 // MailgunSignature signature of mailgun client
 const MailgunSignature ClientSignature = "mailgun client"
 
+// DefaultMailgunMaxMessageSize is the default max size of the mail sent using mailgun
+const DefaultMailgunMaxMessageSize = 25 << 20
+
 // MailgunConfig configuration for mailgun client
 type MailgunConfig struct {
 	Domain            string
 	PrivateKey        string
 	IsActivated       bool
 	ServerSenderEmail string
+
+	// MaxMessageSize is the max Mail.Size. Default to DefaultMailgunMaxMessageSize
+	MaxMessageSize int64
 }
 
 // Mailgun :nodoc:
@@ -23,6 +31,7 @@ type Mailgun struct {
 	client            *mailgun.MailgunImpl
 	isActivated       bool
 	serverSenderEmail string
+	maxMessageSize    int64
 }
 
 // NewMailgunClient create new mailgun client
@@ -32,18 +41,52 @@ func NewMailgunClient(config MailgunConfig) *Mailgun {
 		client,
 		config.IsActivated,
 		config.ServerSenderEmail,
+		config.MaxMessageSize,
 	}
 }
 
-// SendEmail send email using sendinblue
+// SendEmail send email using mailgun. Returning error if the mail is invalid or exceeds the max message size
 func (mg *Mailgun) SendEmail(ctx context.Context, mail *Mail) (string, error) {
 	if !mg.isActivated {
 		return "", ErrMailgunNotActivated
 	}
 
-	message := mg.client.NewMessage(mg.serverSenderEmail, mail.Subject, "", mail.MailgunTo()...)
+	maxMessageSize := mg.maxMessageSize
+	if maxMessageSize <= 0 {
+		maxMessageSize = DefaultMailgunMaxMessageSize
+	}
+
+	if err := mail.Validate(maxMessageSize); err != nil {
+		return "", err
+	}
+
+	sender := mg.serverSenderEmail
+	if mail.From != nil {
+		sender = mail.From.String()
+	}
+
+	message := mg.client.NewMessage(sender, mail.Subject, mail.TextContent, mail.MailgunTo()...)
 	message.SetHtml(mail.HTMLContent)
 
+	if mail.ReplyTo != nil {
+		message.SetReplyTo(mail.ReplyTo.String())
+	}
+
+	if len(mail.Tags) > 0 {
+		if err := message.AddTag(mail.Tags...); err != nil {
+			return "", err
+		}
+	}
+
+	for _, a := range mail.Attachments {
+		message.AddBufferAttachment(a.Filename, a.Content)
+	}
+
+	// mailgun uses the filename as the content ID of the inline image
+	for _, a := range mail.InlineImages {
+		message.AddReaderInline(a.Filename, io.NopCloser(bytes.NewReader(a.Content)))
+	}
+
 	for _, email := range mail.MailgunCC() {
 		message.AddCC(email)
 	}
@@ -52,6 +95,10 @@ func (mg *Mailgun) SendEmail(ctx context.Context, mail *Mail) (string, error) {
 		message.AddBCC(email)
 	}
 
+	for k, v := range mail.Headers() {
+		message.AddHeader(k, v)
+	}
+
 	_, id, err := mg.client.Send(ctx, message)
 	if err != nil {
 		return "", err
@@ -2,6 +2,7 @@ package mail_test
 
 import (
 	"context"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -46,5 +47,57 @@ func TestMailgun(t *testing.T) {
 		assert.Error(t, err)
 	})
 
-	// TODO: unit test for success case sending email using mailgun
+	t.Run("ok - with attachments, inline images, headers and tags", func(t *testing.T) {
+		var form *multipart.Form
+		mockSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, r.ParseMultipartForm(1<<20))
+			form = r.MultipartForm
+			_, _ = w.Write([]byte(`{"id":"<message-id@example.com>","message":"Queued. Thank you."}`))
+		}))
+		defer mockSrv.Close()
+
+		mgClient := mailgun.NewMailgun("example.com", "key")
+		mgClient.SetAPIBase(mockSrv.URL + "/v3")
+
+		mg := mail.Mailgun{}
+		mg.Set(mgClient, "server@example.com", true)
+
+		id, err := mg.SendEmail(context.TODO(), &mail.Mail{
+			To:            []mail.GenericReceipient{{Email: "to@example.com"}},
+			From:          &mail.GenericReceipient{Name: "Billing", Email: "billing@example.com"},
+			ReplyTo:       &mail.GenericReceipient{Email: "support@example.com"},
+			Subject:       "invoice",
+			HTMLContent:   `<img src="cid:logo.png">`,
+			TextContent:   "invoice",
+			Attachments:   []mail.Attachment{{Filename: "invoice.pdf", Content: []byte("pdf")}},
+			InlineImages:  []mail.Attachment{{Filename: "logo.png", Content: []byte("png")}},
+			CustomHeaders: map[string]string{"X-Invoice-Id": "1"},
+			Tags:          []string{"invoice"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "<message-id@example.com>", id)
+
+		assert.Equal(t, []string{`"Billing" <billing@example.com>`}, form.Value["from"])
+		assert.Equal(t, []string{"<support@example.com>"}, form.Value["h:Reply-To"])
+		assert.Equal(t, []string{"1"}, form.Value["h:X-Invoice-Id"])
+		assert.Equal(t, []string{"invoice"}, form.Value["o:tag"])
+		assert.Equal(t, []string{"invoice"}, form.Value["text"])
+		assert.Equal(t, "invoice.pdf", form.File["attachment"][0].Filename)
+		assert.Equal(t, "logo.png", form.File["inline"][0].Filename)
+	})
+
+	t.Run("err - mail too large", func(t *testing.T) {
+		mg := mail.NewMailgunClient(mail.MailgunConfig{
+			Domain:         "example.com",
+			PrivateKey:     "key",
+			IsActivated:    true,
+			MaxMessageSize: 4,
+		})
+
+		_, err := mg.SendEmail(context.TODO(), &mail.Mail{
+			To:          []mail.GenericReceipient{{Email: "to@example.com"}},
+			Attachments: []mail.Attachment{{Filename: "invoice.pdf", Content: []byte("large pdf")}},
+		})
+		assert.ErrorIs(t, err, mail.ErrMailTooLarge)
+	})
 }
@@ -0,0 +1,204 @@
+package mail
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/sweet-go/stdlib/cacher"
+)
+
+// MailgunWebhookOpts is the options for NewMailgunWebhookHandler
+type MailgunWebhookOpts struct {
+	// SigningKey is required. The HTTP webhook signing key found in the mailgun dashboard
+	SigningKey string
+
+	// Sink is required
+	Sink EventSink
+
+	// MaxAge is the max age of the signature timestamp. Default to DefaultWebhookMaxAge
+	MaxAge time.Duration
+
+	// Cacher enables rejecting the replayed signature token when not nil. The seen tokens are kept for MaxAge.
+	// Without it, the captured request can be replayed until its timestamp is older than MaxAge
+	Cacher cacher.Cacher
+
+	// TokenKeyPrefix is prepended to the cache key of the seen token. Default to `mailgun:webhook:token:`
+	TokenKeyPrefix string
+}
+
+type mailgunWebhookPayload struct {
+	Signature struct {
+		Timestamp string `json:"timestamp"`
+		Token     string `json:"token"`
+		Signature string `json:"signature"`
+	} `json:"signature"`
+
+	EventData struct {
+		ID        string   `json:"id"`
+		Event     string   `json:"event"`
+		Timestamp float64  `json:"timestamp"`
+		Recipient string   `json:"recipient"`
+		Severity  string   `json:"severity"`
+		Reason    string   `json:"reason"`
+		URL       string   `json:"url"`
+		Tags      []string `json:"tags"`
+
+		Message struct {
+			Headers struct {
+				MessageID string `json:"message-id"`
+			} `json:"headers"`
+		} `json:"message"`
+
+		DeliveryStatus struct {
+			Description string `json:"description"`
+			Message     string `json:"message"`
+		} `json:"delivery-status"`
+	} `json:"event-data"`
+}
+
+// NewMailgunWebhookHandler creates echo handler receiving the mailgun JSON webhooks. The request is rejected with 401
+// if the signature is invalid or older than MaxAge, and with 406 if its token is already seen when Cacher is supplied,
+// so mailgun doesn't retry it. The failed event is normalized to EventBounced,
+// permanent when its severity is permanent. The other unsupported events are acknowledged without reaching the sink
+func NewMailgunWebhookHandler(opts *MailgunWebhookOpts) (echo.HandlerFunc, error) {
+	if opts == nil || opts.SigningKey == "" || opts.Sink == nil {
+		return nil, fmt.Errorf("%w: mailgun signing key and sink are required", ErrInvalidWebhookConfig)
+	}
+
+	maxAge := opts.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultWebhookMaxAge
+	}
+
+	tokenKeyPrefix := opts.TokenKeyPrefix
+	if tokenKeyPrefix == "" {
+		tokenKeyPrefix = "mailgun:webhook:token:"
+	}
+
+	key := []byte(opts.SigningKey)
+
+	return func(c echo.Context) error {
+		body, err := readWebhookBody(c)
+		if err != nil {
+			return err
+		}
+
+		payload := &mailgunWebhookPayload{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid mailgun webhook payload").SetInternal(err)
+		}
+
+		if !verifyMailgunSignature(key, payload, maxAge) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid mailgun webhook signature")
+		}
+
+		if opts.Cacher == nil {
+			return handleMailgunEvent(c, opts.Sink, payload)
+		}
+
+		ctx := c.Request().Context()
+		tokenKey := tokenKeyPrefix + payload.Signature.Token
+		fresh, err := opts.Cacher.SetNX(ctx, tokenKey, payload.Signature.Timestamp, maxAge)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to check mailgun webhook token").SetInternal(err)
+		}
+
+		if !fresh {
+			return echo.NewHTTPError(http.StatusNotAcceptable, "replayed mailgun webhook token")
+		}
+
+		if err := handleMailgunEvent(c, opts.Sink, payload); err != nil {
+			// forget the token, so the retried webhook is accepted
+			if delErr := opts.Cacher.Delete(ctx, tokenKey); delErr != nil {
+				logrus.WithError(delErr).Warn("failed to forget mailgun webhook token")
+			}
+
+			return err
+		}
+
+		return nil
+	}, nil
+}
+
+func handleMailgunEvent(c echo.Context, sink EventSink, payload *mailgunWebhookPayload) error {
+	event := payload.event()
+	if event == nil {
+		return c.NoContent(http.StatusOK)
+	}
+
+	return dispatchEvents(c, sink, []*Event{event})
+}
+
+// verifyMailgunSignature checks the signature is the HMAC-SHA256 of the timestamp and token,
+// and the timestamp is not older than maxAge
+func verifyMailgunSignature(key []byte, payload *mailgunWebhookPayload, maxAge time.Duration) bool {
+	timestamp, err := strconv.ParseInt(payload.Signature.Timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	if age := time.Since(time.Unix(timestamp, 0)); age > maxAge || age < -maxAge {
+		return false
+	}
+
+	signature, err := hex.DecodeString(payload.Signature.Signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload.Signature.Timestamp + payload.Signature.Token))
+
+	return hmac.Equal(signature, mac.Sum(nil))
+}
+
+// event normalizes the event data, returning nil if the event is not supported
+func (p *mailgunWebhookPayload) event() *Event {
+	data := p.EventData
+	event := &Event{
+		Provider:        MailgunSignature,
+		MessageID:       NormalizeMessageID(data.Message.Headers.MessageID),
+		Recipient:       data.Recipient,
+		Tags:            data.Tags,
+		ProviderEventID: data.ID,
+	}
+
+	sec, frac := math.Modf(data.Timestamp)
+	event.Timestamp = time.Unix(int64(sec), int64(frac*1e9)).UTC()
+
+	switch data.Event {
+	case "delivered":
+		event.Type = EventDelivered
+	case "failed":
+		event.Type = EventBounced
+		event.Permanent = data.Severity == "permanent"
+		event.Reason = data.DeliveryStatus.Description
+		if event.Reason == "" {
+			event.Reason = data.DeliveryStatus.Message
+		}
+
+		if event.Reason == "" {
+			event.Reason = data.Reason
+		}
+	case "complained":
+		event.Type = EventComplained
+	case "opened":
+		event.Type = EventOpened
+	case "clicked":
+		event.Type = EventClicked
+		event.URL = data.URL
+	default:
+		return nil
+	}
+
+	return event
+}
@@ -0,0 +1,188 @@
+package mail_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/cacher"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+func TestMailgunWebhook(t *testing.T) {
+	const signingKey = "signing-key"
+
+	var events []*mail.Event
+	sink := mail.EventSinkFunc(func(_ context.Context, event *mail.Event) error {
+		events = append(events, event)
+		return nil
+	})
+
+	handler, err := mail.NewMailgunWebhookHandler(&mail.MailgunWebhookOpts{SigningKey: signingKey, Sink: sink})
+	assert.NoError(t, err)
+
+	signedPayload := func(timestamp time.Time, token, key, eventData string) string {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(ts + token))
+
+		return fmt.Sprintf(`{"signature":{"timestamp":%q,"token":%q,"signature":%q},"event-data":%s}`,
+			ts, token, hex.EncodeToString(mac.Sum(nil)), eventData)
+	}
+
+	payload := func(timestamp time.Time, key, eventData string) string {
+		return signedPayload(timestamp, "token", key, eventData)
+	}
+
+	serve := func(h echo.HandlerFunc, body string) error {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/mailgun", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		if err := h(e.NewContext(req, rec)); err != nil {
+			return err
+		}
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		return nil
+	}
+
+	httpStatus := func(err error) int {
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			return httpErr.Code
+		}
+
+		return 0
+	}
+
+	t.Run("ok - normalize events", func(t *testing.T) {
+		events = nil
+		bodies := []string{
+			`{"id":"ev-1","event":"delivered","timestamp":1529006854.5,"recipient":"tom@example.com","tags":["invoice"],
+				"message":{"headers":{"message-id":"20230503182626.18666.16540@example.com"}}}`,
+			`{"id":"ev-2","event":"failed","severity":"permanent","timestamp":1529006854,"recipient":"tom@example.com",
+				"delivery-status":{"description":"No such mailbox"},"message":{"headers":{"message-id":"<20230503182626.18666.16540@example.com>"}}}`,
+			`{"id":"ev-3","event":"failed","severity":"temporary","reason":"generic","timestamp":1529006854,"recipient":"tom@example.com",
+				"message":{"headers":{"message-id":"20230503182626.18666.16540@example.com"}}}`,
+			`{"id":"ev-4","event":"complained","timestamp":1529006854,"recipient":"tom@example.com","message":{"headers":{}}}`,
+			`{"id":"ev-5","event":"opened","timestamp":1529006854,"recipient":"tom@example.com","message":{"headers":{}}}`,
+			`{"id":"ev-6","event":"clicked","url":"https://example.com","timestamp":1529006854,"recipient":"tom@example.com","message":{"headers":{}}}`,
+			`{"id":"ev-7","event":"unsubscribed","timestamp":1529006854,"recipient":"tom@example.com","message":{"headers":{}}}`,
+		}
+
+		for _, body := range bodies {
+			assert.NoError(t, serve(handler, payload(time.Now(), signingKey, body)))
+		}
+
+		assert.Len(t, events, 6)
+		assert.Equal(t, &mail.Event{
+			Type:            mail.EventDelivered,
+			Provider:        mail.MailgunSignature,
+			MessageID:       "20230503182626.18666.16540@example.com",
+			Recipient:       "tom@example.com",
+			Timestamp:       time.Unix(1529006854, 5e8).UTC(),
+			Tags:            []string{"invoice"},
+			ProviderEventID: "ev-1",
+		}, events[0])
+
+		assert.Equal(t, mail.EventBounced, events[1].Type)
+		assert.True(t, events[1].Permanent)
+		assert.Equal(t, "No such mailbox", events[1].Reason)
+		assert.Equal(t, "20230503182626.18666.16540@example.com", events[1].MessageID)
+
+		assert.Equal(t, mail.EventBounced, events[2].Type)
+		assert.False(t, events[2].Permanent)
+		assert.Equal(t, "generic", events[2].Reason)
+
+		assert.Equal(t, mail.EventComplained, events[3].Type)
+		assert.Equal(t, mail.EventOpened, events[4].Type)
+		assert.Equal(t, mail.EventClicked, events[5].Type)
+		assert.Equal(t, "https://example.com", events[5].URL)
+	})
+
+	t.Run("err - invalid signature", func(t *testing.T) {
+		events = nil
+		eventData := `{"event":"delivered","message":{"headers":{}}}`
+
+		err := serve(handler, payload(time.Now(), "wrong-key", eventData))
+		assert.Equal(t, http.StatusUnauthorized, httpStatus(err))
+
+		err = serve(handler, payload(time.Now().Add(-time.Hour), signingKey, eventData))
+		assert.Equal(t, http.StatusUnauthorized, httpStatus(err))
+
+		err = serve(handler, `{"signature":{"timestamp":"x","token":"token","signature":"zz"},"event-data":{}}`)
+		assert.Equal(t, http.StatusUnauthorized, httpStatus(err))
+
+		err = serve(handler, `not json`)
+		assert.Equal(t, http.StatusBadRequest, httpStatus(err))
+
+		assert.Empty(t, events)
+	})
+
+	t.Run("err - replayed token", func(t *testing.T) {
+		mr, err := miniredis.Run()
+		assert.NoError(t, err)
+		defer mr.Close()
+
+		failing := true
+		guarded, err := mail.NewMailgunWebhookHandler(&mail.MailgunWebhookOpts{
+			SigningKey: signingKey,
+			Cacher:     cacher.NewCacher(redis.NewClient(&redis.Options{Addr: mr.Addr()})),
+			Sink: mail.EventSinkFunc(func(_ context.Context, _ *mail.Event) error {
+				if failing {
+					return errors.New("db down")
+				}
+
+				return nil
+			}),
+		})
+		assert.NoError(t, err)
+
+		body := signedPayload(time.Now(), "token-1", signingKey, `{"event":"delivered","message":{"headers":{}}}`)
+		assert.Equal(t, http.StatusInternalServerError, httpStatus(serve(guarded, body)))
+
+		// the failed webhook can be retried
+		failing = false
+		assert.NoError(t, serve(guarded, body))
+
+		assert.Equal(t, http.StatusNotAcceptable, httpStatus(serve(guarded, body)))
+		assert.True(t, mr.Exists("mailgun:webhook:token:token-1"))
+		assert.NoError(t, serve(guarded, signedPayload(time.Now(), "token-2", signingKey, `{"event":"delivered","message":{"headers":{}}}`)))
+	})
+
+	t.Run("err - sink failed", func(t *testing.T) {
+		failing, err := mail.NewMailgunWebhookHandler(&mail.MailgunWebhookOpts{
+			SigningKey: signingKey,
+			Sink: mail.EventSinkFunc(func(_ context.Context, _ *mail.Event) error {
+				return errors.New("db down")
+			}),
+		})
+		assert.NoError(t, err)
+
+		err = serve(failing, payload(time.Now(), signingKey, `{"event":"delivered","message":{"headers":{}}}`))
+		assert.Equal(t, http.StatusInternalServerError, httpStatus(err))
+	})
+
+	t.Run("err - invalid config", func(t *testing.T) {
+		_, err := mail.NewMailgunWebhookHandler(&mail.MailgunWebhookOpts{Sink: sink})
+		assert.ErrorIs(t, err, mail.ErrInvalidWebhookConfig)
+
+		_, err = mail.NewMailgunWebhookHandler(nil)
+		assert.ErrorIs(t, err, mail.ErrInvalidWebhookConfig)
+	})
+}
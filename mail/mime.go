@@ -0,0 +1,241 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sweet-go/stdlib/helper"
+)
+
+// BuildMIMEMessage builds the RFC 5322 message of the mail sent by sender, to be sent using SMTP or saved as .eml file.
+// Returning the generated Message-ID and the message. The body is multipart/alternative of TextContent and HTMLContent,
+// wrapped in multipart/related when the mail has InlineImages, and in multipart/mixed when the mail has Attachments.
+// The non ASCII headers are encoded using RFC 2047. Bcc is not written to the headers
+func BuildMIMEMessage(m *Mail, sender GenericReceipient) (messageID string, message []byte, err error) {
+	id := m.ID
+	if id == "" {
+		id = helper.GenerateID()
+	}
+
+	domain := "localhost"
+	if _, d, ok := strings.Cut(sender.Email, "@"); ok && d != "" {
+		domain = d
+	}
+
+	messageID = fmt.Sprintf("<%s@%s>", id, domain)
+
+	var buf bytes.Buffer
+	writeHeader := func(key, value string) {
+		buf.WriteString(key)
+		buf.WriteString(": ")
+		buf.WriteString(value)
+		buf.WriteString("\r\n")
+	}
+
+	writeHeader("From", sender.String())
+	if len(m.To) > 0 {
+		writeHeader("To", formatAddressList(m.To))
+	}
+
+	if len(m.Cc) > 0 {
+		writeHeader("Cc", formatAddressList(m.Cc))
+	}
+
+	if m.ReplyTo != nil {
+		writeHeader("Reply-To", m.ReplyTo.String())
+	}
+
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", messageID)
+	writeHeader("MIME-Version", "1.0")
+
+	headers := m.Headers()
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := headers[k]
+		if !isValidHeaderName(k) || strings.ContainsAny(v, "\r\n") {
+			return "", nil, fmt.Errorf("%w: %q", ErrInvalidMailHeader, k)
+		}
+
+		writeHeader(textproto.CanonicalMIMEHeaderKey(k), mime.QEncoding.Encode("utf-8", v))
+	}
+
+	rootHeader, body, err := buildMIMEBody(m).render()
+	if err != nil {
+		return "", nil, err
+	}
+
+	rootKeys := make([]string, 0, len(rootHeader))
+	for k := range rootHeader {
+		rootKeys = append(rootKeys, k)
+	}
+
+	sort.Strings(rootKeys)
+	for _, k := range rootKeys {
+		writeHeader(k, rootHeader.Get(k))
+	}
+
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	return messageID, buf.Bytes(), nil
+}
+
+func formatAddressList(receipients []GenericReceipient) string {
+	addresses := make([]string, 0, len(receipients))
+	for _, r := range receipients {
+		addresses = append(addresses, r.String())
+	}
+
+	return strings.Join(addresses, ", ")
+}
+
+func isValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, c := range name {
+		// printable US-ASCII except colon, see RFC 5322 section 2.2
+		if c < 33 || c > 126 || c == ':' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mimePart is either the leaf part with the encoded body, or the multipart of the children
+type mimePart struct {
+	header        textproto.MIMEHeader
+	body          []byte
+	multipartType string
+	children      []*mimePart
+}
+
+func buildMIMEBody(m *Mail) *mimePart {
+	var alternatives []*mimePart
+	if m.TextContent != "" || m.HTMLContent == "" {
+		alternatives = append(alternatives, newTextPart("text/plain", m.TextContent))
+	}
+
+	if m.HTMLContent != "" {
+		alternatives = append(alternatives, newTextPart("text/html", m.HTMLContent))
+	}
+
+	root := alternatives[0]
+	if len(alternatives) > 1 {
+		root = &mimePart{multipartType: "multipart/alternative", children: alternatives}
+	}
+
+	if len(m.InlineImages) > 0 {
+		related := &mimePart{multipartType: "multipart/related", children: []*mimePart{root}}
+		for i := range m.InlineImages {
+			related.children = append(related.children, newAttachmentPart(&m.InlineImages[i], true))
+		}
+
+		root = related
+	}
+
+	if len(m.Attachments) > 0 {
+		mixed := &mimePart{multipartType: "multipart/mixed", children: []*mimePart{root}}
+		for i := range m.Attachments {
+			mixed.children = append(mixed.children, newAttachmentPart(&m.Attachments[i], false))
+		}
+
+		root = mixed
+	}
+
+	return root
+}
+
+func newTextPart(mediaType, content string) *mimePart {
+	var body bytes.Buffer
+	w := quotedprintable.NewWriter(&body)
+
+	// the writer never returns error when writing to bytes.Buffer
+	_, _ = w.Write([]byte(content))
+	_ = w.Close()
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(mediaType, map[string]string{"charset": "utf-8"}))
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	return &mimePart{header: header, body: body.Bytes()}
+}
+
+func newAttachmentPart(a *Attachment, inline bool) *mimePart {
+	disposition := "attachment"
+	if inline {
+		disposition = "inline"
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(a.MIMEType(), map[string]string{"name": a.Filename}))
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}))
+	header.Set("Content-Transfer-Encoding", "base64")
+	if inline {
+		header.Set("Content-ID", "<"+a.Filename+">")
+	}
+
+	// the base64 lines must not exceed 76 characters, see RFC 2045 section 6.8
+	encoded := base64.StdEncoding.EncodeToString(a.Content)
+	var body bytes.Buffer
+	for len(encoded) > 76 {
+		body.WriteString(encoded[:76])
+		body.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+
+	body.WriteString(encoded)
+
+	return &mimePart{header: header, body: body.Bytes()}
+}
+
+// render returns the header and the body of the part. The multipart header has the generated boundary
+func (p *mimePart) render() (textproto.MIMEHeader, []byte, error) {
+	if p.multipartType == "" {
+		return p.header, p.body, nil
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, child := range p.children {
+		header, body, err := child.render()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if _, err := w.Write(body); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(p.multipartType, map[string]string{"boundary": mw.Boundary()}))
+
+	return header, buf.Bytes(), nil
+}
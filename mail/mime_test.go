@@ -0,0 +1,138 @@
+package mail_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+func TestBuildMIMEMessage(t *testing.T) {
+	sender := mail.GenericReceipient{Name: "Tagihan Ñoño", Email: "billing@example.com"}
+
+	readParts := func(t *testing.T, contentType string, body io.Reader) (string, []*multipart.Part, [][]byte) {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		assert.NoError(t, err)
+
+		var parts []*multipart.Part
+		var contents [][]byte
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := r.NextRawPart()
+			if err == io.EOF {
+				break
+			}
+
+			assert.NoError(t, err)
+			content, err := io.ReadAll(p)
+			assert.NoError(t, err)
+
+			parts = append(parts, p)
+			contents = append(contents, content)
+		}
+
+		return mediaType, parts, contents
+	}
+
+	t.Run("ok - attachments and inline images", func(t *testing.T) {
+		pdf := bytes.Repeat([]byte("pdf"), 100)
+		id, data, err := mail.BuildMIMEMessage(&mail.Mail{
+			ID:            "mail-id",
+			To:            []mail.GenericReceipient{{Name: "Tom", Email: "tom@example.com"}, {Email: "jerry@example.com"}},
+			Cc:            []mail.GenericReceipient{{Email: "cc@example.com"}},
+			Bcc:           []mail.GenericReceipient{{Email: "bcc@example.com"}},
+			ReplyTo:       &mail.GenericReceipient{Email: "support@example.com"},
+			Subject:       "Tagihan bulan ini ✓",
+			HTMLContent:   `<p>Halo</p><img src="cid:logo.png">`,
+			TextContent:   "Halo",
+			Attachments:   []mail.Attachment{{Filename: "tagihan.pdf", ContentType: "application/pdf", Content: pdf}},
+			InlineImages:  []mail.Attachment{{Filename: "logo.png", ContentType: "image/png", Content: []byte("png")}},
+			CustomHeaders: map[string]string{"x-invoice-id": "1"},
+			RequestID:     "request-id",
+		}, sender)
+		assert.NoError(t, err)
+		assert.Equal(t, "<mail-id@example.com>", id)
+
+		msg, err := netmail.ReadMessage(bytes.NewReader(data))
+		assert.NoError(t, err)
+
+		dec := new(mime.WordDecoder)
+		subject, err := dec.DecodeHeader(msg.Header.Get("Subject"))
+		assert.NoError(t, err)
+		assert.Equal(t, "Tagihan bulan ini ✓", subject)
+
+		from, err := msg.Header.AddressList("From")
+		assert.NoError(t, err)
+		assert.Equal(t, []*netmail.Address{{Name: "Tagihan Ñoño", Address: "billing@example.com"}}, from)
+
+		to, err := msg.Header.AddressList("To")
+		assert.NoError(t, err)
+		assert.Len(t, to, 2)
+
+		assert.Equal(t, "<cc@example.com>", msg.Header.Get("Cc"))
+		assert.Empty(t, msg.Header.Get("Bcc"))
+		assert.Equal(t, "<support@example.com>", msg.Header.Get("Reply-To"))
+		assert.Equal(t, "1", msg.Header.Get("X-Invoice-Id"))
+		assert.Equal(t, "request-id", msg.Header.Get("X-Request-Id"))
+		assert.Equal(t, "1.0", msg.Header.Get("MIME-Version"))
+		_, err = msg.Header.Date()
+		assert.NoError(t, err)
+
+		mediaType, mixed, contents := readParts(t, msg.Header.Get("Content-Type"), msg.Body)
+		assert.Equal(t, "multipart/mixed", mediaType)
+		assert.Len(t, mixed, 2)
+
+		assert.Equal(t, `attachment; filename=tagihan.pdf`, mixed[1].Header.Get("Content-Disposition"))
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.ReplaceAll(contents[1], []byte("\r\n"), nil)))
+		assert.NoError(t, err)
+		assert.Equal(t, pdf, decoded)
+		for _, line := range bytes.Split(contents[1], []byte("\r\n")) {
+			assert.LessOrEqual(t, len(line), 76)
+		}
+
+		mediaType, related, contents := readParts(t, mixed[0].Header.Get("Content-Type"), bytes.NewReader(contents[0]))
+		assert.Equal(t, "multipart/related", mediaType)
+		assert.Len(t, related, 2)
+		assert.Equal(t, "<logo.png>", related[1].Header.Get("Content-ID"))
+		assert.Equal(t, "inline; filename=logo.png", related[1].Header.Get("Content-Disposition"))
+
+		mediaType, alternative, contents := readParts(t, related[0].Header.Get("Content-Type"), bytes.NewReader(contents[0]))
+		assert.Equal(t, "multipart/alternative", mediaType)
+		assert.Equal(t, "text/plain; charset=utf-8", alternative[0].Header.Get("Content-Type"))
+		assert.Equal(t, "text/html; charset=utf-8", alternative[1].Header.Get("Content-Type"))
+		assert.Equal(t, "quoted-printable", alternative[1].Header.Get("Content-Transfer-Encoding"))
+		assert.Equal(t, "Halo", string(contents[0]))
+	})
+
+	t.Run("ok - html only", func(t *testing.T) {
+		_, data, err := mail.BuildMIMEMessage(&mail.Mail{
+			To:          []mail.GenericReceipient{{Email: "tom@example.com"}},
+			HTMLContent: "<p>Halo</p>",
+		}, sender)
+		assert.NoError(t, err)
+
+		msg, err := netmail.ReadMessage(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, "text/html; charset=utf-8", msg.Header.Get("Content-Type"))
+	})
+
+	t.Run("err - header injection", func(t *testing.T) {
+		_, _, err := mail.BuildMIMEMessage(&mail.Mail{
+			To:            []mail.GenericReceipient{{Email: "tom@example.com"}},
+			CustomHeaders: map[string]string{"X-Invoice-Id": "1\r\nBcc: victim@example.com"},
+		}, sender)
+		assert.ErrorIs(t, err, mail.ErrInvalidMailHeader)
+
+		_, _, err = mail.BuildMIMEMessage(&mail.Mail{
+			To:            []mail.GenericReceipient{{Email: "tom@example.com"}},
+			CustomHeaders: map[string]string{"X-Invoice Id": "1"},
+		}, sender)
+		assert.ErrorIs(t, err, mail.ErrInvalidMailHeader)
+	})
+}
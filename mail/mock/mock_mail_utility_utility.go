@@ -50,3 +50,18 @@ func (mr *MockUtilityMockRecorder) SendEmail(arg0, arg1 interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmail", reflect.TypeOf((*MockUtility)(nil).SendEmail), arg0, arg1)
 }
+
+// SendEmailWithResult mocks base method.
+func (m *MockUtility) SendEmailWithResult(arg0 context.Context, arg1 *mail.Mail) (*mail.SendResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailWithResult", arg0, arg1)
+	ret0, _ := ret[0].(*mail.SendResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendEmailWithResult indicates an expected call of SendEmailWithResult.
+func (mr *MockUtilityMockRecorder) SendEmailWithResult(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailWithResult", reflect.TypeOf((*MockUtility)(nil).SendEmailWithResult), arg0, arg1)
+}
@@ -0,0 +1,233 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hibiken/asynq"
+	mailgun "github.com/mailgun/mailgun-go/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/sweet-go/stdlib/helper"
+	"github.com/sweet-go/stdlib/requestid"
+	"github.com/sweet-go/stdlib/worker"
+)
+
+// TaskTypeSendEmail is the task type of the mail enqueued by EnqueueEmail
+const TaskTypeSendEmail = "email:send"
+
+// list of default EmailTaskHandlerOpts
+const (
+	DefaultEmailTaskMaxRetry       = 5
+	DefaultEmailTaskRateLimitRetry = 30 * time.Second
+	DefaultEmailTaskProcessTimeout = 2 * time.Minute
+)
+
+// EmailDeadLetterHandler is called when the mail will never be sent, because the mail is invalid,
+// the error is permanent or ambiguous, or the retries are exhausted. The task itself is archived by asynq.
+// mail is nil if the task payload is invalid
+type EmailDeadLetterHandler func(ctx context.Context, mail *Mail, err error)
+
+// DefaultEmailDeadLetterHandler is the default EmailDeadLetterHandler. Will only log the error
+var DefaultEmailDeadLetterHandler EmailDeadLetterHandler = func(ctx context.Context, mail *Mail, err error) {
+	entry := logrus.WithError(err)
+	if mail != nil {
+		entry = entry.WithField("mail_id", mail.ID)
+	}
+
+	entry.Error("failed to send email, moved to dead letter")
+}
+
+// NewEmailTask creates the task sending the mail using the handler returned by NewEmailTaskHandler.
+// The mail is validated first and the ID is generated if empty, so the mail can be traced in the dead letter.
+// The request ID found in ctx is stamped to the mail if Mail.RequestID is empty.
+// Default to DefaultEmailTaskMaxRetry retries and DefaultEmailTaskProcessTimeout timeout, overridable by opts.
+// Use EnqueueEmail to enqueue using worker.Client, since the client propagating the metadata drops the task options
+func NewEmailTask(ctx context.Context, mail *Mail, opts ...asynq.Option) (*asynq.Task, error) {
+	payload, err := newEmailTaskPayload(ctx, mail)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(TaskTypeSendEmail, payload, emailTaskOptions(opts)...), nil
+}
+
+// EnqueueEmail enqueues the mail to be sent asynchronously by the worker server registering NewEmailTaskHandler.
+// See NewEmailTask for the mail preparation and the default options. The options are supplied to
+// worker.Client.EnqueueTask, so they are kept when the client propagates the metadata
+func EnqueueEmail(ctx context.Context, client worker.Client, mail *Mail, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	payload, err := newEmailTaskPayload(ctx, mail)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.EnqueueTask(ctx, asynq.NewTask(TaskTypeSendEmail, payload), emailTaskOptions(opts)...)
+}
+
+func newEmailTaskPayload(ctx context.Context, mail *Mail) ([]byte, error) {
+	if err := mail.Validate(0); err != nil {
+		return nil, err
+	}
+
+	m := *mail
+	if m.ID == "" {
+		m.ID = helper.GenerateID()
+	}
+
+	if rid := requestid.FromContext(ctx); m.RequestID == "" && rid != "" {
+		m.RequestID = rid
+	}
+
+	return json.Marshal(&m)
+}
+
+// emailTaskOptions prepends the default options, so opts take precedence
+func emailTaskOptions(opts []asynq.Option) []asynq.Option {
+	return append([]asynq.Option{
+		asynq.MaxRetry(DefaultEmailTaskMaxRetry),
+		asynq.Timeout(DefaultEmailTaskProcessTimeout),
+	}, opts...)
+}
+
+// EmailTaskHandlerOpts is the options for NewEmailTaskHandler
+type EmailTaskHandlerOpts struct {
+	// DeadLetterHandler if nil, will use DefaultEmailDeadLetterHandler
+	DeadLetterHandler EmailDeadLetterHandler
+
+	// RateLimitRetry is the delay before retrying when every client is rate limited,
+	// unless the provider tells the delay using worker.RateLimitError. Default to DefaultEmailTaskRateLimitRetry
+	RateLimitRetry time.Duration
+}
+
+type emailTaskHandler struct {
+	utility        Utility
+	deadLetter     EmailDeadLetterHandler
+	rateLimitRetry time.Duration
+}
+
+// NewEmailTaskHandler creates the handler of TaskTypeSendEmail sending the mail using the utility.
+// The retryable error is returned to be retried by asynq. The permanent and ambiguous errors are not retried,
+// to avoid sending the duplicate, nor the mail whose recipients are all dropped. When every client is rate limited
+// or throttled by the provider, worker.RateLimitError is returned, so the retry is delayed and not counted as failure
+// by worker.DefaultRetryDelayFn and worker.DefaultIsFailureCheckerFn. The handler reads the payload using
+// worker.TaskPayload, so it can be served by the server enabling worker.ServerOpts.UnwrapTaskMetadata,
+// and the handler context carries the request ID and trace context of the enqueuing request
+func NewEmailTaskHandler(utility Utility, opts *EmailTaskHandlerOpts) asynq.Handler {
+	if opts == nil {
+		opts = &EmailTaskHandlerOpts{}
+	}
+
+	h := &emailTaskHandler{
+		utility:        utility,
+		deadLetter:     opts.DeadLetterHandler,
+		rateLimitRetry: opts.RateLimitRetry,
+	}
+
+	if h.deadLetter == nil {
+		h.deadLetter = DefaultEmailDeadLetterHandler
+	}
+
+	if h.rateLimitRetry <= 0 {
+		h.rateLimitRetry = DefaultEmailTaskRateLimitRetry
+	}
+
+	return h
+}
+
+// RegisterEmailTaskHandler registers the handler returned by NewEmailTaskHandler to the mux
+func RegisterEmailTaskHandler(mux *asynq.ServeMux, utility Utility, opts *EmailTaskHandlerOpts) {
+	mux.Handle(TaskTypeSendEmail, NewEmailTaskHandler(utility, opts))
+}
+
+func (h *emailTaskHandler) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	mail := &Mail{}
+	if err := json.Unmarshal(worker.TaskPayload(ctx, task), mail); err != nil {
+		err = fmt.Errorf("invalid email task payload: %v: %w", err, asynq.SkipRetry)
+		h.deadLetter(ctx, nil, err)
+		return err
+	}
+
+	res, err := h.utility.SendEmailWithResult(ctx, mail)
+	if err == nil {
+		return nil
+	}
+
+	if rateLimitErr := h.rateLimitError(res); rateLimitErr != nil {
+		return rateLimitErr
+	}
+
+	if errors.Is(err, ErrMailDeliveryUnknown) || errors.Is(err, ErrMailNoRecipient) || hasPermanentAttempt(res) {
+		err = fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+		h.deadLetter(ctx, mail, err)
+		return err
+	}
+
+	// the retry info is only available when processed by the asynq server
+	retried, ok := asynq.GetRetryCount(ctx)
+	maxRetry, okMax := asynq.GetMaxRetry(ctx)
+	if ok && okMax && retried >= maxRetry {
+		h.deadLetter(ctx, mail, err)
+	}
+
+	return err
+}
+
+// rateLimitError returns worker.RateLimitError when every attempt is skipped by the rate limit or throttled
+// by the provider, or nil otherwise. The longest delay told by the clients is used
+func (h *emailTaskHandler) rateLimitError(res *SendResult) *worker.RateLimitError {
+	if res == nil || len(res.Attempts) == 0 {
+		return nil
+	}
+
+	var retryIn time.Duration
+	for _, attempt := range res.Attempts {
+		var rateLimitErr *worker.RateLimitError
+		switch {
+		case errors.As(attempt.Err, &rateLimitErr):
+			if rateLimitErr.RetryIn > retryIn {
+				retryIn = rateLimitErr.RetryIn
+			}
+		case errors.Is(attempt.Err, ErrMailRateLimited), isThrottled(attempt.Err):
+		default:
+			return nil
+		}
+	}
+
+	if retryIn <= 0 {
+		retryIn = h.rateLimitRetry
+	}
+
+	return worker.NewRateLimitError(retryIn)
+}
+
+// isThrottled reports whether the provider responded 429 Too Many Requests
+func isThrottled(err error) bool {
+	var providerErr *ProviderResponseError
+	if errors.As(err, &providerErr) {
+		return providerErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var mailgunErr *mailgun.UnexpectedResponseError
+	if errors.As(err, &mailgunErr) {
+		return mailgunErr.Actual == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+func hasPermanentAttempt(res *SendResult) bool {
+	if res == nil {
+		return false
+	}
+
+	for _, attempt := range res.Attempts {
+		if attempt.Class == ErrorClassPermanent {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,201 @@
+package mail_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+	"github.com/sweet-go/stdlib/requestid"
+	"github.com/sweet-go/stdlib/worker"
+	worker_mock "github.com/sweet-go/stdlib/worker/mock"
+)
+
+func TestEnqueueEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := worker_mock.NewMockClient(ctrl)
+
+	ctx := requestid.NewContext(context.TODO(), "request-id")
+	m := &mail.Mail{
+		To:          []mail.GenericReceipient{{Email: "tom@example.com"}},
+		Subject:     "Welcome",
+		Attachments: []mail.Attachment{{Filename: "terms.pdf", Content: []byte("pdf")}},
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		client.EXPECT().EnqueueTask(ctx, gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *asynq.Task, _ ...asynq.Option) (*asynq.TaskInfo, error) {
+			assert.Equal(t, mail.TaskTypeSendEmail, task.Type())
+
+			enqueued := &mail.Mail{}
+			assert.NoError(t, json.Unmarshal(task.Payload(), enqueued))
+			assert.NotEmpty(t, enqueued.ID)
+			assert.Equal(t, "request-id", enqueued.RequestID)
+			assert.Equal(t, m.Attachments, enqueued.Attachments)
+
+			return &asynq.TaskInfo{ID: "task-id"}, nil
+		})
+
+		info, err := mail.EnqueueEmail(ctx, client, m)
+		assert.NoError(t, err)
+		assert.Equal(t, "task-id", info.ID)
+		assert.Empty(t, m.ID)
+	})
+
+	t.Run("ok - options kept by propagating client", func(t *testing.T) {
+		mr, err := miniredis.Run()
+		assert.NoError(t, err)
+		defer mr.Close()
+
+		client, err := worker.NewClientWithOpts("redis://"+mr.Addr(), &worker.ClientOpts{PropagateRequestID: true})
+		assert.NoError(t, err)
+
+		info, err := mail.EnqueueEmail(ctx, client, m, asynq.Queue(string(worker.PriorityLow)))
+		assert.NoError(t, err)
+		assert.Equal(t, mail.DefaultEmailTaskMaxRetry, info.MaxRetry)
+		assert.Equal(t, mail.DefaultEmailTaskProcessTimeout, info.Timeout)
+		assert.Equal(t, string(worker.PriorityLow), info.Queue)
+
+		// the wrapped payload is unwrapped for the handler
+		sender := mail.NewInMemoryClient()
+		handler := worker.TaskMetadataMiddleware()(mail.NewEmailTaskHandler(mail.NewUtility(sender), nil))
+		assert.NoError(t, handler.ProcessTask(context.TODO(), asynq.NewTask(info.Type, info.Payload)))
+		assert.Equal(t, "request-id", sender.Last().Mail.RequestID)
+	})
+
+	t.Run("err - invalid mail", func(t *testing.T) {
+		_, err := mail.EnqueueEmail(ctx, client, &mail.Mail{Subject: "no recipient"})
+		assert.ErrorIs(t, err, mail.ErrMailNoRecipient)
+	})
+}
+
+func TestEmailTaskHandler(t *testing.T) {
+	ctx := context.TODO()
+	m := &mail.Mail{ID: "mail-id", To: []mail.GenericReceipient{{Email: "tom@example.com"}}}
+
+	newTask := func(t *testing.T) *asynq.Task {
+		task, err := mail.NewEmailTask(ctx, m)
+		assert.NoError(t, err)
+		return task
+	}
+
+	type deadLetter struct {
+		mail *mail.Mail
+		err  error
+	}
+
+	newHandler := func(utility mail.Utility) (asynq.Handler, *[]deadLetter) {
+		var deadLetters []deadLetter
+		handler := mail.NewEmailTaskHandler(utility, &mail.EmailTaskHandlerOpts{
+			DeadLetterHandler: func(_ context.Context, mail *mail.Mail, err error) {
+				deadLetters = append(deadLetters, deadLetter{mail, err})
+			},
+		})
+
+		return handler, &deadLetters
+	}
+
+	t.Run("ok - sent", func(t *testing.T) {
+		client := mail.NewInMemoryClient()
+		handler, deadLetters := newHandler(mail.NewUtility(client))
+
+		assert.NoError(t, handler.ProcessTask(ctx, newTask(t)))
+		assert.Equal(t, m, client.Last().Mail)
+		assert.Empty(t, *deadLetters)
+	})
+
+	t.Run("ok - registered to mux", func(t *testing.T) {
+		client := mail.NewInMemoryClient()
+		mux := asynq.NewServeMux()
+		mail.RegisterEmailTaskHandler(mux, mail.NewUtility(client), nil)
+
+		assert.NoError(t, mux.ProcessTask(ctx, newTask(t)))
+		assert.Equal(t, 1, client.Count())
+	})
+
+	t.Run("err - retryable", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{mail.ErrMailgunNotActivated}}
+		handler, deadLetters := newHandler(mail.NewUtility(a))
+
+		err := handler.ProcessTask(ctx, newTask(t))
+		assert.ErrorIs(t, err, mail.ErrMailgunNotActivated)
+		assert.False(t, errors.Is(err, asynq.SkipRetry))
+		assert.Empty(t, *deadLetters)
+	})
+
+	t.Run("err - all recipients dropped goes to dead letter", func(t *testing.T) {
+		suppressions := mail.NewInMemorySuppressionList()
+		assert.NoError(t, suppressions.Add(ctx, "tom@example.com", mail.SuppressionReasonHardBounce))
+
+		client := mail.NewInMemoryClient()
+		handler, deadLetters := newHandler(mail.NewUtilityWithOpts(&mail.UtilityOpts{SuppressionList: suppressions}, client))
+
+		err := handler.ProcessTask(ctx, newTask(t))
+		assert.ErrorContains(t, err, mail.ErrAllRecipientsDropped.Error())
+		assert.ErrorIs(t, err, asynq.SkipRetry)
+		assert.Len(t, *deadLetters, 1)
+		assert.Equal(t, 0, client.Count())
+	})
+
+	t.Run("err - permanent goes to dead letter", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{&textproto.Error{Code: 550, Msg: "no such user"}}}
+		handler, deadLetters := newHandler(mail.NewUtility(a))
+
+		err := handler.ProcessTask(ctx, newTask(t))
+		assert.ErrorIs(t, err, asynq.SkipRetry)
+		assert.Len(t, *deadLetters, 1)
+		assert.Equal(t, "mail-id", (*deadLetters)[0].mail.ID)
+		assert.Equal(t, err, (*deadLetters)[0].err)
+	})
+
+	t.Run("err - ambiguous goes to dead letter", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{context.DeadlineExceeded}}
+		handler, deadLetters := newHandler(mail.NewUtility(a))
+
+		err := handler.ProcessTask(ctx, newTask(t))
+		assert.ErrorIs(t, err, asynq.SkipRetry)
+		assert.Len(t, *deadLetters, 1)
+	})
+
+	t.Run("err - invalid payload goes to dead letter", func(t *testing.T) {
+		handler, deadLetters := newHandler(mail.NewUtility(mail.NewInMemoryClient()))
+
+		err := handler.ProcessTask(ctx, asynq.NewTask(mail.TaskTypeSendEmail, []byte("not json")))
+		assert.ErrorIs(t, err, asynq.SkipRetry)
+		assert.Len(t, *deadLetters, 1)
+		assert.Nil(t, (*deadLetters)[0].mail)
+	})
+
+	t.Run("err - rate limited", func(t *testing.T) {
+		a := &stubClient{name: "a"}
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{
+			RateLimits: map[mail.ClientSignature]mail.RateLimit{"a": {Limit: 1, Per: time.Hour}},
+		}, a)
+		handler, deadLetters := newHandler(utility)
+
+		assert.NoError(t, handler.ProcessTask(ctx, newTask(t)))
+
+		err := handler.ProcessTask(ctx, newTask(t))
+		assert.True(t, worker.IsRateLimitError(err))
+		assert.Equal(t, mail.DefaultEmailTaskRateLimitRetry, worker.DefaultRetryDelayFn(1, err, nil))
+		assert.False(t, worker.DefaultIsFailureCheckerFn(err))
+		assert.Empty(t, *deadLetters)
+	})
+
+	t.Run("err - throttled by provider", func(t *testing.T) {
+		a := &stubClient{name: "a", errs: []error{&mail.ProviderResponseError{StatusCode: http.StatusTooManyRequests, Err: errors.New("429")}}}
+		b := &stubClient{name: "b", errs: []error{worker.NewRateLimitError(time.Minute)}}
+		handler, _ := newHandler(mail.NewUtility(a, b))
+
+		err := handler.ProcessTask(ctx, newTask(t))
+		assert.True(t, worker.IsRateLimitError(err))
+		assert.Equal(t, time.Minute, worker.DefaultRetryDelayFn(1, err, nil))
+	})
+}
@@ -0,0 +1,190 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"strings"
+)
+
+// MXResolver looks up the MX records of the domain, and its addresses for the implicit MX. Satisfied by *net.Resolver
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// RecipientValidator validates the recipient email before sending
+type RecipientValidator interface {
+	// Validate returns error wrapping ErrInvalidRecipient if the email must not be sent to
+	Validate(ctx context.Context, email string) error
+}
+
+// RecipientValidatorOpts is the options for NewRecipientValidator
+type RecipientValidatorOpts struct {
+	// CheckMX enables rejecting the domain not accepting mail, see NewRecipientValidator
+	CheckMX bool
+
+	// Resolver if nil, will use net.DefaultResolver
+	Resolver MXResolver
+
+	// DisposableDomains are the rejected domains, including their subdomains. Case insensitive
+	DisposableDomains []string
+}
+
+type recipientValidator struct {
+	checkMX           bool
+	resolver          MXResolver
+	disposableDomains map[string]bool
+}
+
+// NewRecipientValidator creates the validator checking the email is a bare RFC 5322 address, e.g. `tom@example.com`,
+// and its domain is not disposable. When CheckMX is enabled, the domain publishing only the null MX (RFC 7505)
+// is rejected, as well as the domain having neither MX record nor address, since the address is the implicit MX
+// (RFC 5321 section 5.1). The DNS errors other than not found are ignored, so the temporary DNS failure
+// doesn't drop the recipient
+func NewRecipientValidator(opts *RecipientValidatorOpts) RecipientValidator {
+	if opts == nil {
+		opts = &RecipientValidatorOpts{}
+	}
+
+	v := &recipientValidator{
+		checkMX:           opts.CheckMX,
+		resolver:          opts.Resolver,
+		disposableDomains: make(map[string]bool, len(opts.DisposableDomains)),
+	}
+
+	if v.resolver == nil {
+		v.resolver = net.DefaultResolver
+	}
+
+	for _, d := range opts.DisposableDomains {
+		v.disposableDomains[strings.ToLower(strings.TrimSpace(d))] = true
+	}
+
+	return v
+}
+
+func (v *recipientValidator) Validate(ctx context.Context, email string) error {
+	address, err := netmail.ParseAddress(email)
+	if err != nil || address.Name != "" || address.Address != email {
+		return fmt.Errorf("%w: %q is not a valid address", ErrInvalidRecipient, email)
+	}
+
+	_, domain, _ := strings.Cut(email, "@")
+	domain = strings.ToLower(domain)
+
+	for d := domain; d != ""; {
+		if v.disposableDomains[d] {
+			return fmt.Errorf("%w: %q is disposable domain", ErrInvalidRecipient, domain)
+		}
+
+		_, d, _ = strings.Cut(d, ".")
+	}
+
+	if !v.checkMX {
+		return nil
+	}
+
+	records, err := v.resolver.LookupMX(ctx, domain)
+	if err != nil && !isDNSNotFound(err) {
+		return nil
+	}
+
+	if len(records) > 0 {
+		// the null MX tells the domain accepts no mail, see RFC 7505
+		for _, r := range records {
+			if r.Host != "." && r.Host != "" {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%w: %q accepts no mail", ErrInvalidRecipient, domain)
+	}
+
+	if _, err := v.resolver.LookupHost(ctx, domain); err == nil || !isDNSNotFound(err) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q has neither MX record nor address", ErrInvalidRecipient, domain)
+}
+
+func isDNSNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// DroppedRecipient is the recipient removed from the mail before sending
+type DroppedRecipient struct {
+	Email string
+
+	// Err wraps either ErrInvalidRecipient or ErrRecipientSuppressed
+	Err error
+}
+
+// filterRecipients returns the copy of the mail without the invalid and suppressed recipients.
+// The suppression list error is returned, so the suppressed recipient is never sent to when the list is unavailable
+func (m *mail) filterRecipients(ctx context.Context, mail *Mail) (*Mail, []DroppedRecipient, error) {
+	if m.validator == nil && m.suppressions == nil {
+		return mail, nil, nil
+	}
+
+	invalid := map[string]error{}
+	if m.validator != nil {
+		for _, list := range [][]GenericReceipient{mail.To, mail.Cc, mail.Bcc} {
+			for _, r := range list {
+				if _, ok := invalid[r.Email]; ok {
+					continue
+				}
+
+				invalid[r.Email] = m.validator.Validate(ctx, r.Email)
+			}
+		}
+	}
+
+	var suppressed map[string]*Suppression
+	if m.suppressions != nil {
+		var emails []string
+		for _, list := range [][]GenericReceipient{mail.To, mail.Cc, mail.Bcc} {
+			for _, r := range list {
+				if invalid[r.Email] == nil {
+					emails = append(emails, r.Email)
+				}
+			}
+		}
+
+		var err error
+		suppressed, err = m.suppressions.Lookup(ctx, emails...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to lookup the suppression list: %w", err)
+		}
+	}
+
+	var dropped []DroppedRecipient
+	filter := func(list []GenericReceipient) []GenericReceipient {
+		var kept []GenericReceipient
+		for _, r := range list {
+			switch s := suppressed[normalizeEmail(r.Email)]; {
+			case invalid[r.Email] != nil:
+				dropped = append(dropped, DroppedRecipient{Email: r.Email, Err: invalid[r.Email]})
+			case s != nil:
+				dropped = append(dropped, DroppedRecipient{
+					Email: r.Email,
+					Err:   fmt.Errorf("%w: %q by %s", ErrRecipientSuppressed, r.Email, s.Reason),
+				})
+			default:
+				kept = append(kept, r)
+			}
+		}
+
+		return kept
+	}
+
+	filtered := *mail
+	filtered.To = filter(mail.To)
+	filtered.Cc = filter(mail.Cc)
+	filtered.Bcc = filter(mail.Bcc)
+
+	return &filtered, dropped, nil
+}
@@ -0,0 +1,182 @@
+package mail_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+type fakeResolver struct {
+	mx    map[string][]*net.MX
+	hosts map[string][]string
+}
+
+func (r fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	if name == "broken.example" {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: name, IsTemporary: true}
+	}
+
+	records, ok := r.mx[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	return records, nil
+}
+
+func (r fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if host == "broken-host.example" {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: host, IsTemporary: true}
+	}
+
+	addrs, ok := r.hosts[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	return addrs, nil
+}
+
+func TestRecipientValidator(t *testing.T) {
+	ctx := context.TODO()
+	validator := mail.NewRecipientValidator(&mail.RecipientValidatorOpts{
+		CheckMX: true,
+		Resolver: fakeResolver{
+			mx: map[string][]*net.MX{
+				"example.com":      {{Host: "mx.example.com.", Pref: 10}},
+				"nullmx.example":   {{Host: ".", Pref: 0}},
+				"sub.example.com":  {{Host: "mx.example.com.", Pref: 10}},
+				"mailinator.com":   {{Host: "mx.mailinator.com.", Pref: 10}},
+				"x.mailinator.com": {{Host: "mx.mailinator.com.", Pref: 10}},
+			},
+			hosts: map[string][]string{
+				"implicit.example": {"192.0.2.1"},
+				"nullmx.example":   {"192.0.2.2"},
+			},
+		},
+		DisposableDomains: []string{"Mailinator.com"},
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		assert.NoError(t, validator.Validate(ctx, "tom@example.com"))
+		assert.NoError(t, validator.Validate(ctx, "tom+tag@sub.example.com"))
+	})
+
+	t.Run("ok - temporary dns error is ignored", func(t *testing.T) {
+		assert.NoError(t, validator.Validate(ctx, "tom@broken.example"))
+		assert.NoError(t, validator.Validate(ctx, "tom@broken-host.example"))
+	})
+
+	t.Run("ok - address is the implicit mx", func(t *testing.T) {
+		assert.NoError(t, validator.Validate(ctx, "tom@implicit.example"))
+	})
+
+	t.Run("ok - mx is not checked by default", func(t *testing.T) {
+		assert.NoError(t, mail.NewRecipientValidator(nil).Validate(ctx, "tom@unknown.invalid"))
+	})
+
+	t.Run("err - invalid syntax", func(t *testing.T) {
+		for _, email := range []string{"", "tom", "tom@", "Tom <tom@example.com>", " tom@example.com", "tom@@example.com"} {
+			assert.ErrorIs(t, validator.Validate(ctx, email), mail.ErrInvalidRecipient, email)
+		}
+	})
+
+	t.Run("err - disposable domain and subdomain", func(t *testing.T) {
+		assert.ErrorIs(t, validator.Validate(ctx, "tom@MAILINATOR.com"), mail.ErrInvalidRecipient)
+		assert.ErrorIs(t, validator.Validate(ctx, "tom@x.mailinator.com"), mail.ErrInvalidRecipient)
+	})
+
+	t.Run("err - neither mx record nor address", func(t *testing.T) {
+		assert.ErrorIs(t, validator.Validate(ctx, "tom@unknown.example"), mail.ErrInvalidRecipient)
+	})
+
+	t.Run("err - null mx", func(t *testing.T) {
+		assert.ErrorIs(t, validator.Validate(ctx, "tom@nullmx.example"), mail.ErrInvalidRecipient)
+	})
+}
+
+func TestMailUtility_Recipients(t *testing.T) {
+	ctx := context.TODO()
+	newMail := func() *mail.Mail {
+		return &mail.Mail{
+			To:  []mail.GenericReceipient{{Email: "tom@example.com"}, {Email: "bounced@example.com"}},
+			Cc:  []mail.GenericReceipient{{Email: "invalid"}},
+			Bcc: []mail.GenericReceipient{{Email: "tom@mailinator.com"}, {Email: "jerry@example.com"}},
+		}
+	}
+
+	suppressions := mail.NewInMemorySuppressionList()
+	assert.NoError(t, suppressions.Add(ctx, "Bounced@Example.com", mail.SuppressionReasonHardBounce))
+
+	validator := mail.NewRecipientValidator(&mail.RecipientValidatorOpts{DisposableDomains: []string{"mailinator.com"}})
+
+	t.Run("ok - drop invalid and suppressed recipients", func(t *testing.T) {
+		client := mail.NewInMemoryClient()
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{
+			RecipientValidator: validator,
+			SuppressionList:    suppressions,
+		}, client)
+
+		m := newMail()
+		res, err := utility.SendEmailWithResult(ctx, m)
+		assert.NoError(t, err)
+		assert.Len(t, res.DroppedRecipients, 3)
+		assert.Equal(t, "bounced@example.com", res.DroppedRecipients[0].Email)
+		assert.ErrorIs(t, res.DroppedRecipients[0].Err, mail.ErrRecipientSuppressed)
+		assert.Equal(t, "invalid", res.DroppedRecipients[1].Email)
+		assert.ErrorIs(t, res.DroppedRecipients[1].Err, mail.ErrInvalidRecipient)
+		assert.Equal(t, "tom@mailinator.com", res.DroppedRecipients[2].Email)
+		assert.ErrorIs(t, res.DroppedRecipients[2].Err, mail.ErrInvalidRecipient)
+
+		sent := client.Last().Mail
+		assert.Equal(t, []mail.GenericReceipient{{Email: "tom@example.com"}}, sent.To)
+		assert.Empty(t, sent.Cc)
+		assert.Equal(t, []mail.GenericReceipient{{Email: "jerry@example.com"}}, sent.Bcc)
+
+		// the given mail is not modified
+		assert.Equal(t, newMail(), m)
+	})
+
+	t.Run("ok - not filtered by default", func(t *testing.T) {
+		client := mail.NewInMemoryClient()
+		res, err := mail.NewUtility(client).SendEmailWithResult(ctx, newMail())
+		assert.NoError(t, err)
+		assert.Empty(t, res.DroppedRecipients)
+		assert.Len(t, client.Last().Mail.To, 2)
+	})
+
+	t.Run("err - all recipients dropped", func(t *testing.T) {
+		client := mail.NewInMemoryClient()
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{SuppressionList: suppressions}, client)
+
+		res, err := utility.SendEmailWithResult(ctx, &mail.Mail{To: []mail.GenericReceipient{{Email: "bounced@example.com"}}})
+		assert.ErrorIs(t, err, mail.ErrAllRecipientsDropped)
+		assert.ErrorIs(t, err, mail.ErrMailNoRecipient)
+		assert.Len(t, res.DroppedRecipients, 1)
+		assert.Empty(t, res.Attempts)
+		assert.Equal(t, 0, client.Count())
+	})
+
+	t.Run("err - suppression list fails", func(t *testing.T) {
+		client := mail.NewInMemoryClient()
+		utility := mail.NewUtilityWithOpts(&mail.UtilityOpts{SuppressionList: failingSuppressionList{}}, client)
+
+		_, err := utility.SendEmailWithResult(ctx, newMail())
+		assert.ErrorIs(t, err, errSuppressionListDown)
+		assert.Equal(t, 0, client.Count())
+	})
+}
+
+var errSuppressionListDown = errors.New("suppression list is down")
+
+type failingSuppressionList struct {
+	mail.SuppressionList
+}
+
+func (failingSuppressionList) Lookup(_ context.Context, _ ...string) (map[string]*mail.Suppression, error) {
+	return nil, errSuppressionListDown
+}
@@ -10,47 +10,105 @@ import (
 // SendInBlueSignature send in blue client signature
 const SendInBlueSignature ClientSignature = "sendinblue"
 
+// DefaultSendInBlueMaxMessageSize is the default max size of the mail sent using sendinblue
+const DefaultSendInBlueMaxMessageSize = 10 << 20
+
 // SendInBlue send in blue client
 type SendInBlue struct {
-	client      *sendinblue.APIClient
-	sender      *sendinblue.SendSmtpEmailSender
-	isActivated bool
+	client         *sendinblue.APIClient
+	sender         *sendinblue.SendSmtpEmailSender
+	isActivated    bool
+	maxMessageSize int64
+}
+
+// SendInBlueOpts is the options for NewSendInBlueClientWithOpts
+type SendInBlueOpts struct {
+	// MaxMessageSize is the max Mail.Size. Default to DefaultSendInBlueMaxMessageSize
+	MaxMessageSize int64
 }
 
 // NewSendInBlueClient creates a new SendInBlue client
 func NewSendInBlueClient(sender *sendinblue.SendSmtpEmailSender, sibAPIKey string, isActivated bool) *SendInBlue {
+	return NewSendInBlueClientWithOpts(sender, sibAPIKey, isActivated, nil)
+}
+
+// NewSendInBlueClientWithOpts creates a new SendInBlue client with options
+func NewSendInBlueClientWithOpts(sender *sendinblue.SendSmtpEmailSender, sibAPIKey string, isActivated bool, opts *SendInBlueOpts) *SendInBlue {
 	sibConfig := sendinblue.NewConfiguration()
 	sibConfig.AddDefaultHeader("api-key", sibAPIKey)
 
-	return &SendInBlue{
+	s := &SendInBlue{
 		client:      sendinblue.NewAPIClient(sibConfig),
 		sender:      sender,
 		isActivated: isActivated,
 	}
+
+	if opts != nil {
+		s.maxMessageSize = opts.MaxMessageSize
+	}
+
+	return s
 }
 
-// SendEmail sends an email. error if status code from sendinblue server is not 201
+// SendEmail sends an email, returning the Message-ID matching the webhook events. error if status code
+// from sendinblue server is not 201, the mail is invalid or exceeds the max message size.
+// Sendinblue doesn't support content ID, so the inline images are embedded to the HTML content as data URI
 func (s *SendInBlue) SendEmail(ctx context.Context, mail *Mail) (string, error) {
 	if !s.isActivated {
 		return "", ErrSendInBlueNotActivated
 	}
 
+	maxMessageSize := s.maxMessageSize
+	if maxMessageSize <= 0 {
+		maxMessageSize = DefaultSendInBlueMaxMessageSize
+	}
+
+	if err := mail.Validate(maxMessageSize); err != nil {
+		return "", err
+	}
+
 	body := sendinblue.SendSmtpEmail{
 		Sender:      s.sender,
 		To:          mail.SendInBlueTo(),
 		Cc:          mail.SendInBlueCc(),
 		Bcc:         mail.SendInBlueBcc(),
-		HtmlContent: mail.HTMLContent,
+		HtmlContent: mail.SendInBlueHTMLContent(),
+		TextContent: mail.TextContent,
 		Subject:     mail.Subject,
+		Attachment:  mail.SendInBlueAttachment(),
+		Tags:        mail.Tags,
+	}
+
+	if mail.From != nil {
+		body.Sender = &sendinblue.SendSmtpEmailSender{
+			Name:  mail.From.Name,
+			Email: mail.From.Email,
+		}
+	}
+
+	if mail.ReplyTo != nil {
+		body.ReplyTo = &sendinblue.SendSmtpEmailReplyTo{
+			Name:  mail.ReplyTo.Name,
+			Email: mail.ReplyTo.Email,
+		}
+	}
+
+	if headers := mail.Headers(); len(headers) > 0 {
+		var h interface{} = headers
+		body.Headers = &h
 	}
 
 	email, res, err := s.client.TransactionalEmailsApi.SendTransacEmail(ctx, body)
+	if err != nil && res != nil {
+		return "", &ProviderResponseError{StatusCode: res.StatusCode, Err: err}
+	}
+
 	if err != nil {
 		return "", err
 	}
 
 	defer helper.WrapCloser(res.Body.Close)
-	return helper.Dump(email), nil
+	return email.MessageId, nil
 }
 
 // GetClientName return client name signature sendinblue
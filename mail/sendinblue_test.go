@@ -2,6 +2,7 @@ package mail_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -43,6 +44,54 @@ func TestSendInBlue(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("ok - with attachments, inline images, sender and tags", func(t *testing.T) {
+		var body lib.SendSmtpEmail
+		mockSrc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"messageId":"<201798300811.5787683@smtp-relay.mailin.fr>"}`))
+		}))
+		defer mockSrc.Close()
+
+		sibCfg := &lib.Configuration{
+			BasePath:      mockSrc.URL,
+			DefaultHeader: make(map[string]string),
+			UserAgent:     "Swagger-Codegen/1.0.0/go",
+		}
+
+		sib := &mail.SendInBlue{}
+		sib.Set(lib.NewAPIClient(sibCfg), &lib.SendSmtpEmailSender{Email: "server@example.com"}, true)
+
+		messageID, err := sib.SendEmail(context.TODO(), &mail.Mail{
+			To:           m.To,
+			From:         &mail.GenericReceipient{Name: "Billing", Email: "billing@example.com"},
+			ReplyTo:      &mail.GenericReceipient{Email: "support@example.com"},
+			HTMLContent:  `<img src="cid:logo.png">`,
+			Attachments:  []mail.Attachment{{Filename: "invoice.pdf", Content: []byte("pdf")}},
+			InlineImages: []mail.Attachment{{Filename: "logo.png", ContentType: "image/png", Content: []byte("png")}},
+			Tags:         []string{"invoice"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "<201798300811.5787683@smtp-relay.mailin.fr>", messageID)
+
+		assert.Equal(t, &lib.SendSmtpEmailSender{Name: "Billing", Email: "billing@example.com"}, body.Sender)
+		assert.Equal(t, &lib.SendSmtpEmailReplyTo{Email: "support@example.com"}, body.ReplyTo)
+		assert.Equal(t, []lib.SendSmtpEmailAttachment{{Name: "invoice.pdf", Content: "cGRm"}}, body.Attachment)
+		assert.Equal(t, `<img src="data:image/png;base64,cG5n">`, body.HtmlContent)
+		assert.Equal(t, []string{"invoice"}, body.Tags)
+	})
+
+	t.Run("err - mail too large", func(t *testing.T) {
+		sib := mail.NewSendInBlueClientWithOpts(&lib.SendSmtpEmailSender{}, "key", true, &mail.SendInBlueOpts{MaxMessageSize: 4})
+
+		_, err := sib.SendEmail(context.TODO(), &mail.Mail{
+			To:          m.To,
+			Attachments: []mail.Attachment{{Filename: "invoice.pdf", Content: []byte("large pdf")}},
+		})
+		assert.ErrorIs(t, err, mail.ErrMailTooLarge)
+	})
+
 	t.Run("sib not activated", func(t *testing.T) {
 		sib := &mail.SendInBlue{}
 		sib.Set(nil, nil, false)
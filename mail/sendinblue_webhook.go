@@ -0,0 +1,140 @@
+package mail
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SendInBlueWebhookOpts is the options for NewSendInBlueWebhookHandler. Sendinblue doesn't sign the webhooks,
+// so the webhook URL must be configured with either the basic auth credentials or the bearer token
+type SendInBlueWebhookOpts struct {
+	// Username and Password are the basic auth credentials configured in the webhook
+	Username string
+	Password string
+
+	// Token is the bearer token configured in the webhook
+	Token string
+
+	// Sink is required
+	Sink EventSink
+}
+
+type sendInBlueWebhookPayload struct {
+	Event     string   `json:"event"`
+	Email     string   `json:"email"`
+	MessageID string   `json:"message-id"`
+	TsEvent   int64    `json:"ts_event"`
+	TsEpoch   int64    `json:"ts_epoch"`
+	Reason    string   `json:"reason"`
+	Link      string   `json:"link"`
+	Tags      []string `json:"tags"`
+}
+
+// NewSendInBlueWebhookHandler creates echo handler receiving the sendinblue transactional webhooks, either single event
+// or batched. The request is rejected with 401 if the credentials don't match. The hard_bounce, invalid_email and blocked
+// events are normalized to permanent EventBounced, soft_bounce to temporary EventBounced and spam to EventComplained.
+// Only the opened event is normalized to EventOpened, since unique_opened is sent alongside the first opened event.
+// The other unsupported events are acknowledged without reaching the sink
+func NewSendInBlueWebhookHandler(opts *SendInBlueWebhookOpts) (echo.HandlerFunc, error) {
+	if opts == nil || opts.Sink == nil || (opts.Token == "" && (opts.Username == "" || opts.Password == "")) {
+		return nil, fmt.Errorf("%w: sendinblue credentials and sink are required", ErrInvalidWebhookConfig)
+	}
+
+	return func(c echo.Context) error {
+		if !verifySendInBlueCredentials(c.Request(), opts) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid sendinblue webhook credentials")
+		}
+
+		body, err := readWebhookBody(c)
+		if err != nil {
+			return err
+		}
+
+		var payloads []*sendInBlueWebhookPayload
+		if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+			err = json.Unmarshal(body, &payloads)
+		} else {
+			payload := &sendInBlueWebhookPayload{}
+			err = json.Unmarshal(body, payload)
+			payloads = append(payloads, payload)
+		}
+
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid sendinblue webhook payload").SetInternal(err)
+		}
+
+		var events []*Event
+		for _, p := range payloads {
+			if event := p.event(); event != nil {
+				events = append(events, event)
+			}
+		}
+
+		return dispatchEvents(c, opts.Sink, events)
+	}, nil
+}
+
+// verifySendInBlueCredentials compares the configured credentials in constant time
+func verifySendInBlueCredentials(r *http.Request, opts *SendInBlueWebhookOpts) bool {
+	if opts.Token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(token), []byte(opts.Token)) == 1 {
+			return true
+		}
+	}
+
+	if opts.Username != "" && opts.Password != "" {
+		username, password, ok := r.BasicAuth()
+		if ok && subtle.ConstantTimeCompare([]byte(username), []byte(opts.Username))&
+			subtle.ConstantTimeCompare([]byte(password), []byte(opts.Password)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// event normalizes the payload, returning nil if the event is not supported
+func (p *sendInBlueWebhookPayload) event() *Event {
+	event := &Event{
+		Provider:  SendInBlueSignature,
+		MessageID: NormalizeMessageID(p.MessageID),
+		Recipient: p.Email,
+		Timestamp: time.Unix(p.TsEvent, 0).UTC(),
+		Tags:      p.Tags,
+	}
+
+	if p.TsEpoch > 0 {
+		event.Timestamp = time.UnixMilli(p.TsEpoch).UTC()
+	}
+
+	switch p.Event {
+	case "delivered":
+		event.Type = EventDelivered
+	case "hard_bounce", "invalid_email", "blocked":
+		event.Type = EventBounced
+		event.Permanent = true
+		event.Reason = p.Reason
+	case "soft_bounce":
+		event.Type = EventBounced
+		event.Reason = p.Reason
+	case "spam":
+		event.Type = EventComplained
+		event.Reason = p.Reason
+	case "opened":
+		event.Type = EventOpened
+	case "click":
+		event.Type = EventClicked
+		event.URL = p.Link
+	default:
+		return nil
+	}
+
+	return event
+}
@@ -0,0 +1,117 @@
+package mail_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+func TestSendInBlueWebhook(t *testing.T) {
+	var events []*mail.Event
+	sink := mail.EventSinkFunc(func(_ context.Context, event *mail.Event) error {
+		events = append(events, event)
+		return nil
+	})
+
+	serve := func(h echo.HandlerFunc, body string, auth func(r *http.Request)) int {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/sendinblue", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		auth(req)
+		rec := httptest.NewRecorder()
+
+		var httpErr *echo.HTTPError
+		if err := h(e.NewContext(req, rec)); errors.As(err, &httpErr) {
+			return httpErr.Code
+		}
+
+		return rec.Code
+	}
+
+	bearer := func(token string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+	}
+
+	t.Run("ok - normalize events", func(t *testing.T) {
+		events = nil
+		handler, err := mail.NewSendInBlueWebhookHandler(&mail.SendInBlueWebhookOpts{Token: "token", Sink: sink})
+		assert.NoError(t, err)
+
+		body := `[
+			{"event":"delivered","email":"tom@example.com","message-id":"<201798300811.5787683@smtp-relay.mailin.fr>","ts_event":1604933654,"ts_epoch":1604933654123,"tags":["invoice"]},
+			{"event":"hard_bounce","email":"tom@example.com","message-id":"<1@relay>","ts_event":1604933654,"reason":"unknown user"},
+			{"event":"soft_bounce","email":"tom@example.com","message-id":"<1@relay>","ts_event":1604933654,"reason":"mailbox full"},
+			{"event":"spam","email":"tom@example.com","message-id":"<1@relay>","ts_event":1604933654},
+			{"event":"opened","email":"tom@example.com","message-id":"<1@relay>","ts_event":1604933654},
+			{"event":"unique_opened","email":"tom@example.com","message-id":"<1@relay>","ts_event":1604933654},
+			{"event":"click","email":"tom@example.com","message-id":"<1@relay>","ts_event":1604933654,"link":"https://example.com"}
+		]`
+		assert.Equal(t, http.StatusOK, serve(handler, body, bearer("token")))
+
+		assert.Len(t, events, 6)
+		assert.Equal(t, &mail.Event{
+			Type:      mail.EventDelivered,
+			Provider:  mail.SendInBlueSignature,
+			MessageID: "201798300811.5787683@smtp-relay.mailin.fr",
+			Recipient: "tom@example.com",
+			Timestamp: time.UnixMilli(1604933654123).UTC(),
+			Tags:      []string{"invoice"},
+		}, events[0])
+
+		assert.Equal(t, mail.EventBounced, events[1].Type)
+		assert.True(t, events[1].Permanent)
+		assert.Equal(t, "unknown user", events[1].Reason)
+		assert.Equal(t, time.Unix(1604933654, 0).UTC(), events[1].Timestamp)
+		assert.Equal(t, mail.EventBounced, events[2].Type)
+		assert.False(t, events[2].Permanent)
+		assert.Equal(t, mail.EventComplained, events[3].Type)
+		assert.Equal(t, mail.EventOpened, events[4].Type)
+		assert.Equal(t, mail.EventClicked, events[5].Type)
+		assert.Equal(t, "https://example.com", events[5].URL)
+	})
+
+	t.Run("ok - single event with basic auth", func(t *testing.T) {
+		events = nil
+		handler, err := mail.NewSendInBlueWebhookHandler(&mail.SendInBlueWebhookOpts{Username: "user", Password: "pass", Sink: sink})
+		assert.NoError(t, err)
+
+		status := serve(handler, `{"event":"delivered","email":"tom@example.com","message-id":"<1@relay>"}`, func(r *http.Request) {
+			r.SetBasicAuth("user", "pass")
+		})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, events, 1)
+	})
+
+	t.Run("err - invalid credentials", func(t *testing.T) {
+		events = nil
+		handler, err := mail.NewSendInBlueWebhookHandler(&mail.SendInBlueWebhookOpts{Username: "user", Password: "pass", Token: "token", Sink: sink})
+		assert.NoError(t, err)
+
+		body := `{"event":"delivered"}`
+		assert.Equal(t, http.StatusUnauthorized, serve(handler, body, bearer("wrong")))
+		assert.Equal(t, http.StatusUnauthorized, serve(handler, body, func(r *http.Request) {
+			r.SetBasicAuth("user", "wrong")
+		}))
+		assert.Equal(t, http.StatusUnauthorized, serve(handler, body, func(r *http.Request) {}))
+		assert.Equal(t, http.StatusBadRequest, serve(handler, `{`, bearer("token")))
+		assert.Empty(t, events)
+	})
+
+	t.Run("err - invalid config", func(t *testing.T) {
+		_, err := mail.NewSendInBlueWebhookHandler(&mail.SendInBlueWebhookOpts{Username: "user", Sink: sink})
+		assert.ErrorIs(t, err, mail.ErrInvalidWebhookConfig)
+
+		_, err = mail.NewSendInBlueWebhookHandler(&mail.SendInBlueWebhookOpts{Token: "token"})
+		assert.ErrorIs(t, err, mail.ErrInvalidWebhookConfig)
+	})
+}
@@ -0,0 +1,437 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPSignature smtp client signature
+const SMTPSignature ClientSignature = "smtp"
+
+// list of default SMTPConfig
+const (
+	DefaultSMTPMaxConnections = 4
+	DefaultSMTPMaxIdleTime    = time.Minute
+	DefaultSMTPTimeout        = 30 * time.Second
+	DefaultSMTPMaxMessageSize = 25 << 20
+)
+
+// SMTPTLSMode is how the connection to the SMTP server is secured
+type SMTPTLSMode string
+
+// list of SMTPTLSMode
+const (
+	// SMTPTLSModeStartTLS upgrades the plain connection using STARTTLS, failing if the server doesn't support it
+	SMTPTLSModeStartTLS SMTPTLSMode = "starttls"
+
+	// SMTPTLSModeImplicit connects using TLS from the start, usually on port 465
+	SMTPTLSModeImplicit SMTPTLSMode = "implicit"
+
+	// SMTPTLSModeNone uses the plain connection. Should only be used for local development
+	SMTPTLSModeNone SMTPTLSMode = "none"
+)
+
+// SMTPAuthMechanism is the SASL mechanism to authenticate to the SMTP server
+type SMTPAuthMechanism string
+
+// list of SMTPAuthMechanism
+const (
+	SMTPAuthPlain   SMTPAuthMechanism = "PLAIN"
+	SMTPAuthLogin   SMTPAuthMechanism = "LOGIN"
+	SMTPAuthCRAMMD5 SMTPAuthMechanism = "CRAM-MD5"
+)
+
+// SMTPConfig configuration for smtp client
+type SMTPConfig struct {
+	Host string
+
+	// Port default to 587, or 465 for SMTPTLSModeImplicit
+	Port int
+
+	// Username if empty, no authentication is done
+	Username string
+	Password string
+
+	// AuthMechanism default to SMTPAuthPlain. PLAIN and LOGIN are refused on the plain connection, except to localhost
+	AuthMechanism SMTPAuthMechanism
+
+	// TLSMode default to SMTPTLSModeStartTLS
+	TLSMode SMTPTLSMode
+
+	// TLSConfig if nil, will verify the server certificate against Host
+	TLSConfig *tls.Config
+
+	// ServerSenderEmail is the sender used when Mail.From is nil, either `email@example.com` or `Name <email@example.com>`
+	ServerSenderEmail string
+
+	// LocalName is the host name sent on EHLO. Default to localhost
+	LocalName string
+
+	// MaxConnections is the max concurrent connections, also the max idle connections kept in the pool.
+	// Default to DefaultSMTPMaxConnections
+	MaxConnections int
+
+	// MaxIdleTime is how long the idle connection is kept in the pool. Default to DefaultSMTPMaxIdleTime
+	MaxIdleTime time.Duration
+
+	// Timeout is the timeout of dialing and of every mail transaction. Default to DefaultSMTPTimeout
+	Timeout time.Duration
+
+	// MaxMessageSize is the max size of the MIME message, checked against Mail.Size before building the message.
+	// Default to DefaultSMTPMaxMessageSize
+	MaxMessageSize int64
+
+	IsActivated bool
+}
+
+// SMTP is the smtp client keeping a pool of the authenticated connections
+type SMTP struct {
+	config    SMTPConfig
+	addr      string
+	tlsConfig *tls.Config
+
+	// slots limits the concurrent connections, idle holds the connections ready to be reused
+	slots chan struct{}
+	idle  chan *smtpConn
+}
+
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// NewSMTPClient create new smtp client. The connection is established lazily on the first SendEmail
+func NewSMTPClient(config SMTPConfig) *SMTP {
+	if config.TLSMode == "" {
+		config.TLSMode = SMTPTLSModeStartTLS
+	}
+
+	if config.Port == 0 {
+		config.Port = 587
+		if config.TLSMode == SMTPTLSModeImplicit {
+			config.Port = 465
+		}
+	}
+
+	if config.AuthMechanism == "" {
+		config.AuthMechanism = SMTPAuthPlain
+	}
+
+	if config.LocalName == "" {
+		config.LocalName = "localhost"
+	}
+
+	if config.MaxConnections <= 0 {
+		config.MaxConnections = DefaultSMTPMaxConnections
+	}
+
+	if config.MaxIdleTime <= 0 {
+		config.MaxIdleTime = DefaultSMTPMaxIdleTime
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultSMTPTimeout
+	}
+
+	if config.MaxMessageSize <= 0 {
+		config.MaxMessageSize = DefaultSMTPMaxMessageSize
+	}
+
+	tlsConfig := &tls.Config{ServerName: config.Host, MinVersion: tls.VersionTLS12}
+	if config.TLSConfig != nil {
+		tlsConfig = config.TLSConfig.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = config.Host
+		}
+	}
+
+	return &SMTP{
+		config:    config,
+		addr:      net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		tlsConfig: tlsConfig,
+		slots:     make(chan struct{}, config.MaxConnections),
+		idle:      make(chan *smtpConn, config.MaxConnections),
+	}
+}
+
+// SendEmail send email using smtp, returning the Message-ID. Returning error if the mail is invalid
+// or the built MIME message exceeds the max message size. Tags are not supported
+func (s *SMTP) SendEmail(ctx context.Context, mail *Mail) (_ string, err error) {
+	if !s.config.IsActivated {
+		return "", ErrSMTPNotActivated
+	}
+
+	if err := mail.Validate(s.config.MaxMessageSize); err != nil {
+		return "", err
+	}
+
+	sender, err := mail.sender(s.config.ServerSenderEmail)
+	if err != nil {
+		return "", err
+	}
+
+	messageID, message, err := BuildMIMEMessage(mail, sender)
+	if err != nil {
+		return "", err
+	}
+
+	// Size doesn't count the MIME headers and the quoted-printable contents may grow
+	if size := int64(len(message)); size > s.config.MaxMessageSize {
+		return "", fmt.Errorf("%w: %d bytes message, max %d bytes", ErrMailTooLarge, size, s.config.MaxMessageSize)
+	}
+
+	var recipients []string
+	for _, receipients := range [][]GenericReceipient{mail.To, mail.Cc, mail.Bcc} {
+		for _, r := range receipients {
+			recipients = append(recipients, r.Email)
+		}
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		s.release(conn, err)
+	}()
+
+	if err = conn.send(sender.Email, recipients, message); err != nil {
+		return "", err
+	}
+
+	return messageID, nil
+}
+
+// GetClientName returning client name signature
+func (s *SMTP) GetClientName() ClientSignature {
+	return SMTPSignature
+}
+
+// Close closes the idle connections. The connections in use are closed when released
+func (s *SMTP) Close() error {
+	var errs []error
+	for {
+		select {
+		case c := <-s.idle:
+			if err := c.quit(s.config.Timeout); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// acquire takes the idle connection still alive, or dials the new one, waiting for the free slot
+func (s *SMTP) acquire(ctx context.Context) (*smtpConn, error) {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	deadline := time.Now().Add(s.config.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	for {
+		select {
+		case c := <-s.idle:
+			if time.Since(c.lastUsed) > s.config.MaxIdleTime {
+				_ = c.client.Close()
+				continue
+			}
+
+			if err := c.conn.SetDeadline(deadline); err != nil {
+				_ = c.client.Close()
+				continue
+			}
+
+			// the server may have closed the connection, and the previous failed transaction must be aborted
+			if err := c.client.Reset(); err != nil {
+				_ = c.client.Close()
+				continue
+			}
+
+			return c, nil
+		default:
+			c, err := s.dial(ctx, deadline)
+			if err != nil {
+				<-s.slots
+				return nil, err
+			}
+
+			return c, nil
+		}
+	}
+}
+
+// release returns the connection to the pool. The connection is closed if the error is not the SMTP reply,
+// since the connection state is unknown
+func (s *SMTP) release(c *smtpConn, err error) {
+	defer func() {
+		<-s.slots
+	}()
+
+	var reply *textproto.Error
+	if err != nil && !errors.As(err, &reply) {
+		_ = c.client.Close()
+		return
+	}
+
+	c.lastUsed = time.Now()
+	select {
+	case s.idle <- c:
+	default:
+		_ = c.quit(s.config.Timeout)
+	}
+}
+
+func (s *SMTP) dial(ctx context.Context, deadline time.Time) (_ *smtpConn, err error) {
+	dialer := &net.Dialer{Deadline: deadline}
+
+	var conn net.Conn
+	if s.config.TLSMode == SMTPTLSModeImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}).DialContext(ctx, "tcp", s.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.addr)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = client.Close()
+		}
+	}()
+
+	if err := client.Hello(s.config.LocalName); err != nil {
+		return nil, err
+	}
+
+	if s.config.TLSMode == SMTPTLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return nil, ErrSMTPStartTLSNotSupported
+		}
+
+		if err := client.StartTLS(s.tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.config.Username != "" {
+		if err := client.Auth(s.auth()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &smtpConn{conn: conn, client: client}, nil
+}
+
+func (s *SMTP) auth() smtp.Auth {
+	switch s.config.AuthMechanism {
+	case SMTPAuthLogin:
+		return &loginAuth{username: s.config.Username, password: s.config.Password, host: s.config.Host}
+	case SMTPAuthCRAMMD5:
+		return smtp.CRAMMD5Auth(s.config.Username, s.config.Password)
+	default:
+		return smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+}
+
+// quit gracefully ends the session, since the transaction deadline may have passed
+func (c *smtpConn) quit(timeout time.Duration) error {
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		_ = c.client.Close()
+		return err
+	}
+
+	if err := c.client.Quit(); err != nil {
+		_ = c.client.Close()
+		return err
+	}
+
+	return nil
+}
+
+func (c *smtpConn) send(from string, recipients []string, message []byte) error {
+	if err := c.client.Mail(from); err != nil {
+		return err
+	}
+
+	for _, r := range recipients {
+		if err := c.client.Rcpt(r); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// loginAuth implements the LOGIN mechanism, which is not provided by net/smtp
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	// same as smtp.PlainAuth, the credentials must not be sent over the plain connection
+	if !server.TLS && server.Name != "localhost" && server.Name != "127.0.0.1" && server.Name != "::1" {
+		return "", nil, errors.New("unencrypted connection")
+	}
+
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+
+	return string(SMTPAuthLogin), nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+	}
+}
@@ -0,0 +1,422 @@
+package mail_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+type fakeSMTPMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// fakeSMTPServer is the minimal in-process SMTP server supporting STARTTLS, implicit TLS, and PLAIN, LOGIN and CRAM-MD5 auth
+type fakeSMTPServer struct {
+	listener    net.Listener
+	tlsConfig   *tls.Config
+	implicitTLS bool
+	username    string
+	password    string
+
+	mu          sync.Mutex
+	messages    []fakeSMTPMessage
+	connections int
+}
+
+func newFakeSMTPServer(t *testing.T, tlsConfig *tls.Config, implicitTLS bool, username, password string) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	if implicitTLS {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	s := &fakeSMTPServer{
+		listener:    listener,
+		tlsConfig:   tlsConfig,
+		implicitTLS: implicitTLS,
+		username:    username,
+		password:    password,
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			s.connections++
+			s.mu.Unlock()
+
+			go s.serve(conn)
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) lastMessage() fakeSMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.messages) == 0 {
+		return fakeSMTPMessage{}
+	}
+
+	return s.messages[len(s.messages)-1]
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	isTLS := s.implicitTLS
+	authenticated := s.username == ""
+	var msg fakeSMTPMessage
+
+	_ = tp.PrintfLine("220 localhost ESMTP fake")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			lines := []string{"localhost"}
+			if s.tlsConfig != nil && !isTLS {
+				lines = append(lines, "STARTTLS")
+			}
+
+			lines = append(lines, "AUTH PLAIN LOGIN CRAM-MD5", "8BITMIME")
+			for i, l := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+
+				_ = tp.PrintfLine("250%s%s", sep, l)
+			}
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+
+			conn = tlsConn
+			tp = textproto.NewConn(conn)
+			isTLS = true
+		case "AUTH":
+			authenticated = s.auth(tp, arg)
+			if authenticated {
+				_ = tp.PrintfLine("235 authenticated")
+			} else {
+				_ = tp.PrintfLine("535 authentication failed")
+			}
+		case "MAIL":
+			if !authenticated {
+				_ = tp.PrintfLine("530 authentication required")
+				continue
+			}
+
+			msg = fakeSMTPMessage{From: extractSMTPPath(arg)}
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			msg.To = append(msg.To, extractSMTPPath(arg))
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+
+			msg.Data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 ok queued")
+		case "RSET", "NOOP":
+			msg = fakeSMTPMessage{}
+			_ = tp.PrintfLine("250 ok")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) auth(tp *textproto.Conn, arg string) bool {
+	mechanism, initial, _ := strings.Cut(arg, " ")
+	readResponse := func(challenge string) string {
+		_ = tp.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte(challenge)))
+		line, _ := tp.ReadLine()
+		decoded, _ := base64.StdEncoding.DecodeString(line)
+		return string(decoded)
+	}
+
+	switch mechanism {
+	case "PLAIN":
+		decoded, _ := base64.StdEncoding.DecodeString(initial)
+		parts := strings.Split(string(decoded), "\x00")
+		return len(parts) == 3 && parts[1] == s.username && parts[2] == s.password
+	case "LOGIN":
+		return readResponse("Username:") == s.username && readResponse("Password:") == s.password
+	case "CRAM-MD5":
+		challenge := "<12345@localhost>"
+		username, digest, _ := strings.Cut(readResponse(challenge), " ")
+		h := hmac.New(md5.New, []byte(s.password))
+		h.Write([]byte(challenge))
+		return username == s.username && digest == hex.EncodeToString(h.Sum(nil))
+	default:
+		return false
+	}
+}
+
+func extractSMTPPath(arg string) string {
+	start := strings.Index(arg, "<")
+	end := strings.Index(arg, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+
+	return arg[start+1 : end]
+}
+
+func newTestTLSConfig(t *testing.T) (server *tls.Config, client *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}},
+		&tls.Config{RootCAs: pool}
+}
+
+func TestSMTP(t *testing.T) {
+	serverTLS, clientTLS := newTestTLSConfig(t)
+
+	newMail := func() *mail.Mail {
+		return &mail.Mail{
+			ID:          "mail-id",
+			To:          []mail.GenericReceipient{{Name: "To", Email: "to@example.com"}},
+			Cc:          []mail.GenericReceipient{{Email: "cc@example.com"}},
+			Bcc:         []mail.GenericReceipient{{Email: "bcc@example.com"}},
+			Subject:     "Hello",
+			HTMLContent: "<p>Hello</p>",
+			TextContent: "Hello",
+		}
+	}
+
+	t.Run("ok - starttls and plain auth", func(t *testing.T) {
+		srv := newFakeSMTPServer(t, serverTLS, false, "user", "secret")
+		client := mail.NewSMTPClient(mail.SMTPConfig{
+			Host:              "127.0.0.1",
+			Port:              srv.port(),
+			Username:          "user",
+			Password:          "secret",
+			TLSConfig:         clientTLS,
+			ServerSenderEmail: "Server <server@example.com>",
+			IsActivated:       true,
+		})
+		defer client.Close()
+
+		id, err := client.SendEmail(context.TODO(), newMail())
+		assert.NoError(t, err)
+		assert.Equal(t, "<mail-id@example.com>", id)
+
+		msg := srv.lastMessage()
+		assert.Equal(t, "server@example.com", msg.From)
+		assert.Equal(t, []string{"to@example.com", "cc@example.com", "bcc@example.com"}, msg.To)
+		assert.Contains(t, msg.Data, "Message-ID: <mail-id@example.com>")
+		assert.NotContains(t, msg.Data, "bcc@example.com")
+		assert.Equal(t, mail.SMTPSignature, client.GetClientName())
+	})
+
+	t.Run("ok - starttls and login auth", func(t *testing.T) {
+		srv := newFakeSMTPServer(t, serverTLS, false, "user", "secret")
+		client := mail.NewSMTPClient(mail.SMTPConfig{
+			Host:              "127.0.0.1",
+			Port:              srv.port(),
+			Username:          "user",
+			Password:          "secret",
+			AuthMechanism:     mail.SMTPAuthLogin,
+			TLSConfig:         clientTLS,
+			ServerSenderEmail: "server@example.com",
+			IsActivated:       true,
+		})
+		defer client.Close()
+
+		_, err := client.SendEmail(context.TODO(), newMail())
+		assert.NoError(t, err)
+		assert.Equal(t, "server@example.com", srv.lastMessage().From)
+	})
+
+	t.Run("ok - implicit tls and cram-md5 auth", func(t *testing.T) {
+		srv := newFakeSMTPServer(t, serverTLS, true, "user", "secret")
+		client := mail.NewSMTPClient(mail.SMTPConfig{
+			Host:              "127.0.0.1",
+			Port:              srv.port(),
+			Username:          "user",
+			Password:          "secret",
+			AuthMechanism:     mail.SMTPAuthCRAMMD5,
+			TLSMode:           mail.SMTPTLSModeImplicit,
+			TLSConfig:         clientTLS,
+			ServerSenderEmail: "server@example.com",
+			IsActivated:       true,
+		})
+		defer client.Close()
+
+		m := newMail()
+		m.From = &mail.GenericReceipient{Email: "billing@example.com"}
+
+		_, err := client.SendEmail(context.TODO(), m)
+		assert.NoError(t, err)
+		assert.Equal(t, "billing@example.com", srv.lastMessage().From)
+	})
+
+	t.Run("reuse pooled connection", func(t *testing.T) {
+		srv := newFakeSMTPServer(t, nil, false, "", "")
+		client := mail.NewSMTPClient(mail.SMTPConfig{
+			Host:              "127.0.0.1",
+			Port:              srv.port(),
+			TLSMode:           mail.SMTPTLSModeNone,
+			ServerSenderEmail: "server@example.com",
+			IsActivated:       true,
+		})
+		defer client.Close()
+
+		for i := 0; i < 3; i++ {
+			_, err := client.SendEmail(context.TODO(), newMail())
+			assert.NoError(t, err)
+		}
+
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		assert.Len(t, srv.messages, 3)
+		assert.Equal(t, 1, srv.connections)
+	})
+
+	t.Run("err - starttls not supported", func(t *testing.T) {
+		srv := newFakeSMTPServer(t, nil, false, "", "")
+		client := mail.NewSMTPClient(mail.SMTPConfig{
+			Host:              "127.0.0.1",
+			Port:              srv.port(),
+			ServerSenderEmail: "server@example.com",
+			IsActivated:       true,
+		})
+
+		_, err := client.SendEmail(context.TODO(), newMail())
+		assert.ErrorIs(t, err, mail.ErrSMTPStartTLSNotSupported)
+	})
+
+	t.Run("err - authentication failed", func(t *testing.T) {
+		srv := newFakeSMTPServer(t, serverTLS, false, "user", "secret")
+		client := mail.NewSMTPClient(mail.SMTPConfig{
+			Host:              "127.0.0.1",
+			Port:              srv.port(),
+			Username:          "user",
+			Password:          "wrong",
+			TLSConfig:         clientTLS,
+			ServerSenderEmail: "server@example.com",
+			IsActivated:       true,
+		})
+
+		_, err := client.SendEmail(context.TODO(), newMail())
+		assert.Error(t, err)
+	})
+
+	t.Run("err - untrusted certificate", func(t *testing.T) {
+		srv := newFakeSMTPServer(t, serverTLS, false, "", "")
+		client := mail.NewSMTPClient(mail.SMTPConfig{
+			Host:              "127.0.0.1",
+			Port:              srv.port(),
+			ServerSenderEmail: "server@example.com",
+			IsActivated:       true,
+		})
+
+		_, err := client.SendEmail(context.TODO(), newMail())
+		assert.Error(t, err)
+	})
+
+	t.Run("err - invalid mail", func(t *testing.T) {
+		client := mail.NewSMTPClient(mail.SMTPConfig{
+			Host:              "127.0.0.1",
+			ServerSenderEmail: "server@example.com",
+			MaxMessageSize:    4,
+			IsActivated:       true,
+		})
+
+		_, err := client.SendEmail(context.TODO(), newMail())
+		assert.ErrorIs(t, err, mail.ErrMailTooLarge)
+	})
+
+	t.Run("err - encoded message too large", func(t *testing.T) {
+		client := mail.NewSMTPClient(mail.SMTPConfig{
+			Host:              "127.0.0.1",
+			ServerSenderEmail: "server@example.com",
+			MaxMessageSize:    newMail().Size() + 1,
+			IsActivated:       true,
+		})
+
+		_, err := client.SendEmail(context.TODO(), newMail())
+		assert.ErrorIs(t, err, mail.ErrMailTooLarge)
+		assert.ErrorContains(t, err, "bytes message")
+	})
+
+	t.Run("smtp is not activated", func(t *testing.T) {
+		_, err := mail.NewSMTPClient(mail.SMTPConfig{}).SendEmail(context.TODO(), newMail())
+		assert.Equal(t, mail.ErrSMTPNotActivated, err)
+	})
+}
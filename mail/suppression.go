@@ -0,0 +1,188 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultSuppressionListKey is the default redis hash key of the suppression list
+const DefaultSuppressionListKey = "mail:suppressions"
+
+// SuppressionReason is why the email is suppressed
+type SuppressionReason string
+
+// list of SuppressionReason
+const (
+	SuppressionReasonHardBounce   SuppressionReason = "hard_bounce"
+	SuppressionReasonComplaint    SuppressionReason = "complaint"
+	SuppressionReasonUnsubscribed SuppressionReason = "unsubscribed"
+	SuppressionReasonManual       SuppressionReason = "manual"
+)
+
+// Suppression is the suppressed email, never sent to by Utility
+type Suppression struct {
+	Email     string            `json:"email"`
+	Reason    SuppressionReason `json:"reason"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// SuppressionList stores the suppressed emails. The emails are case insensitive
+type SuppressionList interface {
+	// Add suppresses the email, replacing the existing suppression
+	Add(ctx context.Context, email string, reason SuppressionReason) error
+
+	// Remove unsuppresses the email. Removing non suppressed email is not an error
+	Remove(ctx context.Context, email string) error
+
+	// Lookup returns the suppressions of the emails found in the list, keyed by the lower cased email
+	Lookup(ctx context.Context, emails ...string) (map[string]*Suppression, error)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+type inMemorySuppressionList struct {
+	mu           sync.RWMutex
+	suppressions map[string]*Suppression
+}
+
+// NewInMemorySuppressionList creates the suppression list kept in memory, to be used in tests and local development
+func NewInMemorySuppressionList() SuppressionList {
+	return &inMemorySuppressionList{
+		suppressions: make(map[string]*Suppression),
+	}
+}
+
+func (l *inMemorySuppressionList) Add(_ context.Context, email string, reason SuppressionReason) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	email = normalizeEmail(email)
+	l.suppressions[email] = &Suppression{Email: email, Reason: reason, CreatedAt: time.Now().UTC()}
+
+	return nil
+}
+
+func (l *inMemorySuppressionList) Remove(_ context.Context, email string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.suppressions, normalizeEmail(email))
+
+	return nil
+}
+
+func (l *inMemorySuppressionList) Lookup(_ context.Context, emails ...string) (map[string]*Suppression, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	res := map[string]*Suppression{}
+	for _, email := range emails {
+		if s, ok := l.suppressions[normalizeEmail(email)]; ok {
+			res[s.Email] = s
+		}
+	}
+
+	return res, nil
+}
+
+// RedisSuppressionListOpts is the options for NewRedisSuppressionList
+type RedisSuppressionListOpts struct {
+	// Key is the redis hash key. Default to DefaultSuppressionListKey
+	Key string
+}
+
+type redisSuppressionList struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisSuppressionList creates the suppression list stored in a redis hash, shared by every instance
+func NewRedisSuppressionList(client *redis.Client, opts *RedisSuppressionListOpts) SuppressionList {
+	l := &redisSuppressionList{
+		client: client,
+		key:    DefaultSuppressionListKey,
+	}
+
+	if opts != nil && opts.Key != "" {
+		l.key = opts.Key
+	}
+
+	return l
+}
+
+func (l *redisSuppressionList) Add(ctx context.Context, email string, reason SuppressionReason) error {
+	email = normalizeEmail(email)
+	value, err := json.Marshal(&Suppression{Email: email, Reason: reason, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	return l.client.HSet(ctx, l.key, email, value).Err()
+}
+
+func (l *redisSuppressionList) Remove(ctx context.Context, email string) error {
+	return l.client.HDel(ctx, l.key, normalizeEmail(email)).Err()
+}
+
+func (l *redisSuppressionList) Lookup(ctx context.Context, emails ...string) (map[string]*Suppression, error) {
+	res := map[string]*Suppression{}
+	if len(emails) == 0 {
+		return res, nil
+	}
+
+	fields := make([]string, 0, len(emails))
+	for _, email := range emails {
+		fields = append(fields, normalizeEmail(email))
+	}
+
+	values, err := l.client.HMGet(ctx, l.key, fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range values {
+		value, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		s := &Suppression{}
+		if err := json.Unmarshal([]byte(value), s); err != nil {
+			return nil, err
+		}
+
+		res[s.Email] = s
+	}
+
+	return res, nil
+}
+
+type suppressionEventSink struct {
+	list SuppressionList
+}
+
+// NewSuppressionEventSink creates the EventSink suppressing the recipients of the permanent bounces and complaints,
+// so the webhook handlers keep the suppression list up to date. The other events are ignored
+func NewSuppressionEventSink(list SuppressionList) EventSink {
+	return &suppressionEventSink{list: list}
+}
+
+func (s *suppressionEventSink) HandleEvent(ctx context.Context, event *Event) error {
+	switch {
+	case event.Recipient == "":
+		return nil
+	case event.Type == EventBounced && event.Permanent:
+		return s.list.Add(ctx, event.Recipient, SuppressionReasonHardBounce)
+	case event.Type == EventComplained:
+		return s.list.Add(ctx, event.Recipient, SuppressionReasonComplaint)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,94 @@
+package mail_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+func TestSuppressionList(t *testing.T) {
+	ctx := context.TODO()
+
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	defer mr.Close()
+
+	lists := map[string]mail.SuppressionList{
+		"in memory": mail.NewInMemorySuppressionList(),
+		"redis": mail.NewRedisSuppressionList(redis.NewClient(&redis.Options{
+			Addr: mr.Addr(),
+			DB:   0,
+		}), nil),
+	}
+
+	for name, list := range lists {
+		t.Run("ok - "+name, func(t *testing.T) {
+			assert.NoError(t, list.Add(ctx, " Tom@Example.com ", mail.SuppressionReasonHardBounce))
+			assert.NoError(t, list.Add(ctx, "jerry@example.com", mail.SuppressionReasonManual))
+
+			res, err := list.Lookup(ctx, "TOM@example.com", "jerry@example.com", "spike@example.com")
+			assert.NoError(t, err)
+			assert.Len(t, res, 2)
+			assert.Equal(t, "tom@example.com", res["tom@example.com"].Email)
+			assert.Equal(t, mail.SuppressionReasonHardBounce, res["tom@example.com"].Reason)
+			assert.False(t, res["tom@example.com"].CreatedAt.IsZero())
+			assert.Equal(t, mail.SuppressionReasonManual, res["jerry@example.com"].Reason)
+
+			assert.NoError(t, list.Remove(ctx, "Jerry@example.com"))
+			assert.NoError(t, list.Remove(ctx, "spike@example.com"))
+
+			res, err = list.Lookup(ctx, "tom@example.com", "jerry@example.com")
+			assert.NoError(t, err)
+			assert.Len(t, res, 1)
+			assert.Contains(t, res, "tom@example.com")
+
+			res, err = list.Lookup(ctx)
+			assert.NoError(t, err)
+			assert.Empty(t, res)
+		})
+	}
+
+	t.Run("ok - redis key", func(t *testing.T) {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr(), DB: 0})
+		list := mail.NewRedisSuppressionList(client, &mail.RedisSuppressionListOpts{Key: "custom"})
+		assert.NoError(t, list.Add(ctx, "tom@example.com", mail.SuppressionReasonUnsubscribed))
+
+		assert.True(t, mr.Exists("custom"))
+		assert.True(t, mr.Exists(mail.DefaultSuppressionListKey))
+	})
+
+	t.Run("err - redis down", func(t *testing.T) {
+		client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+		_, err := mail.NewRedisSuppressionList(client, nil).Lookup(ctx, "tom@example.com")
+		assert.Error(t, err)
+	})
+}
+
+func TestSuppressionEventSink(t *testing.T) {
+	ctx := context.TODO()
+	list := mail.NewInMemorySuppressionList()
+	sink := mail.NewSuppressionEventSink(list)
+
+	events := []*mail.Event{
+		{Type: mail.EventBounced, Recipient: "hard@example.com", Permanent: true},
+		{Type: mail.EventBounced, Recipient: "soft@example.com"},
+		{Type: mail.EventComplained, Recipient: "spam@example.com"},
+		{Type: mail.EventDelivered, Recipient: "ok@example.com"},
+		{Type: mail.EventComplained},
+	}
+
+	for _, event := range events {
+		assert.NoError(t, sink.HandleEvent(ctx, event))
+	}
+
+	res, err := list.Lookup(ctx, "hard@example.com", "soft@example.com", "spam@example.com", "ok@example.com")
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Equal(t, mail.SuppressionReasonHardBounce, res["hard@example.com"].Reason)
+	assert.Equal(t, mail.SuppressionReasonComplaint, res["spam@example.com"].Reason)
+}
@@ -0,0 +1,393 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/sweet-go/stdlib/helper"
+)
+
+// list of default TemplateOpts
+const (
+	DefaultTemplateLayoutsDir  = "layouts"
+	DefaultTemplatePartialsDir = "partials"
+	DefaultTemplatesDir        = "templates"
+	DefaultTemplateLayout      = "base"
+	TemplateFileExtension      = ".html"
+)
+
+// list of the blocks defined by the templates
+const (
+	// TemplateBlockSubject is the required block rendering the mail subject
+	TemplateBlockSubject = "subject"
+
+	// TemplateBlockContent is the required block rendering the mail body, executed by the layout
+	TemplateBlockContent = "content"
+
+	// TemplateBlockText is the optional block rendering the plain text alternative.
+	// If not defined, the plain text is generated from the HTML using HTMLToText
+	TemplateBlockText = "text"
+)
+
+// TemplateOpts is the options for NewTemplateEngine.
+//
+// The files are laid out as follows, all using TemplateFileExtension:
+//
+//	layouts/base.html         executed as `base`, renders the content block using {{template "content" .}}
+//	partials/button.html      included as {{template "button" .}}
+//	templates/welcome.html    defines the subject, content and optional text blocks
+//	templates/welcome.id.html the variant of welcome template for `id` locale
+type TemplateOpts struct {
+	// FS is required. Use fs.Sub if the directories are not in the FS root
+	FS fs.FS
+
+	// LayoutsDir default to DefaultTemplateLayoutsDir
+	LayoutsDir string
+
+	// PartialsDir default to DefaultTemplatePartialsDir
+	PartialsDir string
+
+	// TemplatesDir default to DefaultTemplatesDir. The template names must not contain dot, as it separates the locale
+	TemplatesDir string
+
+	// Layout is the layout executed by the templates not listed in TemplateLayouts. Default to DefaultTemplateLayout.
+	// If the layouts directory is empty, the content block is used as the whole HTML
+	Layout string
+
+	// TemplateLayouts is the layout by the template name
+	TemplateLayouts map[string]string
+
+	// DefaultLocale is used when the template has no variant for the requested locale,
+	// before falling back to the variant without locale
+	DefaultLocale string
+
+	// Funcs is added to every template
+	Funcs template.FuncMap
+
+	// DisableCSSInlining disables moving the style elements to the style attributes using InlineCSS
+	DisableCSSInlining bool
+
+	// DisableTextFallback disables generating the plain text from the HTML when the template has no text block
+	DisableTextFallback bool
+}
+
+// RenderedTemplate is the rendered mail template
+type RenderedTemplate struct {
+	Subject     string
+	HTMLContent string
+	TextContent string
+}
+
+// Mail creates ready-to-send Mail with generated ID from the rendered template
+func (r *RenderedTemplate) Mail(to ...GenericReceipient) *Mail {
+	return &Mail{
+		ID:          helper.GenerateID(),
+		To:          to,
+		Subject:     r.Subject,
+		HTMLContent: r.HTMLContent,
+		TextContent: r.TextContent,
+	}
+}
+
+// TemplateEngine renders the named mail templates. Safe for concurrent use
+type TemplateEngine interface {
+	// Render renders the variant of the named template for the locale. The variant is looked up by the exact locale,
+	// the language of the locale, the default locale, then the variant without locale. Locale is case insensitive
+	// and both `en-US` and `en_US` are accepted
+	Render(name, locale string, data any) (*RenderedTemplate, error)
+
+	// NewMail renders the named template to ready-to-send Mail with generated ID
+	NewMail(name, locale string, data any, to ...GenericReceipient) (*Mail, error)
+
+	// Exists reports whether the named template has any variant
+	Exists(name string) bool
+}
+
+type templateVariant struct {
+	tmpl    *template.Template
+	layout  string
+	hasText bool
+}
+
+type templateEngine struct {
+	// variants by the template name, then by the normalized locale. The variant without locale uses empty locale
+	variants            map[string]map[string]*templateVariant
+	defaultLocale       string
+	disableCSSInlining  bool
+	disableTextFallback bool
+}
+
+// NewTemplateEngine parses all the layouts, partials and templates from the FS. Returning ErrInvalidTemplate
+// if any template is missing the required blocks or refers unknown layout, so the mistakes are caught on startup
+func NewTemplateEngine(opts *TemplateOpts) (TemplateEngine, error) {
+	if opts == nil || opts.FS == nil {
+		return nil, fmt.Errorf("%w: FS is required", ErrInvalidTemplate)
+	}
+
+	layoutsDir := opts.LayoutsDir
+	if layoutsDir == "" {
+		layoutsDir = DefaultTemplateLayoutsDir
+	}
+
+	partialsDir := opts.PartialsDir
+	if partialsDir == "" {
+		partialsDir = DefaultTemplatePartialsDir
+	}
+
+	templatesDir := opts.TemplatesDir
+	if templatesDir == "" {
+		templatesDir = DefaultTemplatesDir
+	}
+
+	defaultLayout := opts.Layout
+	if defaultLayout == "" {
+		defaultLayout = DefaultTemplateLayout
+	}
+
+	base := template.New("").Option("missingkey=error").Funcs(opts.Funcs)
+
+	layouts, err := parseTemplateDir(base, opts.FS, layoutsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := parseTemplateDir(base, opts.FS, partialsDir); err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(opts.FS, templatesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &templateEngine{
+		variants:            make(map[string]map[string]*templateVariant),
+		defaultLocale:       normalizeLocale(opts.DefaultLocale),
+		disableCSSInlining:  opts.DisableCSSInlining,
+		disableTextFallback: opts.DisableTextFallback,
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != TemplateFileExtension {
+			continue
+		}
+
+		name, locale, _ := strings.Cut(strings.TrimSuffix(entry.Name(), TemplateFileExtension), ".")
+
+		content, err := fs.ReadFile(opts.FS, path.Join(templatesDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		tmpl, err := base.Clone()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tmpl.New(entry.Name()).Parse(string(content)); err != nil {
+			return nil, err
+		}
+
+		for _, block := range []string{TemplateBlockSubject, TemplateBlockContent} {
+			if tmpl.Lookup(block) == nil {
+				return nil, fmt.Errorf("%w: %s has no %s block", ErrInvalidTemplate, entry.Name(), block)
+			}
+		}
+
+		variant := &templateVariant{
+			tmpl:    tmpl,
+			hasText: tmpl.Lookup(TemplateBlockText) != nil,
+		}
+
+		if len(layouts) > 0 {
+			variant.layout = defaultLayout
+			if layout, ok := opts.TemplateLayouts[name]; ok {
+				variant.layout = layout
+			}
+
+			if !layouts[variant.layout] {
+				return nil, fmt.Errorf("%w: %s refers unknown layout %s", ErrInvalidTemplate, entry.Name(), variant.layout)
+			}
+		}
+
+		if e.variants[name] == nil {
+			e.variants[name] = make(map[string]*templateVariant)
+		}
+
+		e.variants[name][normalizeLocale(locale)] = variant
+	}
+
+	return e, nil
+}
+
+// parseTemplateDir parses every file in the dir as the template named by the file name without extension.
+// Returning the parsed template names. Missing dir is not an error
+func parseTemplateDir(base *template.Template, fsys fs.FS, dir string) (map[string]bool, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != TemplateFileExtension {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimSuffix(entry.Name(), TemplateFileExtension)
+		if _, err := base.New(name).Parse(string(content)); err != nil {
+			return nil, err
+		}
+
+		names[name] = true
+	}
+
+	return names, nil
+}
+
+func (e *templateEngine) Exists(name string) bool {
+	return len(e.variants[name]) > 0
+}
+
+func (e *templateEngine) Render(name, locale string, data any) (*RenderedTemplate, error) {
+	variant, err := e.lookup(name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := executeTemplate(variant.tmpl, TemplateBlockSubject, data)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := TemplateBlockContent
+	if variant.layout != "" {
+		entry = variant.layout
+	}
+
+	htmlContent, err := executeTemplate(variant.tmpl, entry, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.disableCSSInlining {
+		htmlContent, err = InlineCSS(htmlContent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rendered := &RenderedTemplate{
+		// the subject and text are plain text, but escaped by html/template
+		Subject:     strings.Join(strings.Fields(html.UnescapeString(subject)), " "),
+		HTMLContent: htmlContent,
+	}
+
+	switch {
+	case variant.hasText:
+		text, err := executeTemplate(variant.tmpl, TemplateBlockText, data)
+		if err != nil {
+			return nil, err
+		}
+
+		rendered.TextContent = strings.TrimSpace(html.UnescapeString(text))
+	case !e.disableTextFallback:
+		rendered.TextContent = HTMLToText(htmlContent)
+	}
+
+	return rendered, nil
+}
+
+func (e *templateEngine) NewMail(name, locale string, data any, to ...GenericReceipient) (*Mail, error) {
+	rendered, err := e.Render(name, locale, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return rendered.Mail(to...), nil
+}
+
+func (e *templateEngine) lookup(name, locale string) (*templateVariant, error) {
+	variants := e.variants[name]
+	for _, l := range localeCandidates(normalizeLocale(locale), e.defaultLocale) {
+		if v, ok := variants[l]; ok {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s for locale %s", ErrTemplateNotFound, name, locale)
+}
+
+// localeCandidates returns the locales to look up in order: the exact locale, its language,
+// the default locale, its language, then the empty locale
+func localeCandidates(locale, defaultLocale string) []string {
+	var res []string
+	for _, l := range []string{locale, defaultLocale} {
+		if l == "" {
+			continue
+		}
+
+		res = append(res, l)
+		if lang, _, ok := strings.Cut(l, "-"); ok {
+			res = append(res, lang)
+		}
+	}
+
+	return append(res, "")
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+func executeTemplate(tmpl *template.Template, name string, data any) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.ExecuteTemplate(&sb, name, data); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// Template is the typed handle of the named template, so the template data type is checked on compile time
+type Template[T any] struct {
+	engine TemplateEngine
+	name   string
+}
+
+// NewTemplate creates the typed handle of the named template.
+// Returning ErrTemplateNotFound if the engine has no such template, so the typo is caught on startup
+func NewTemplate[T any](engine TemplateEngine, name string) (*Template[T], error) {
+	if !engine.Exists(name) {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	return &Template[T]{
+		engine: engine,
+		name:   name,
+	}, nil
+}
+
+// Render renders the template variant for the locale
+func (t *Template[T]) Render(locale string, data T) (*RenderedTemplate, error) {
+	return t.engine.Render(t.name, locale, data)
+}
+
+// NewMail renders the template variant for the locale to ready-to-send Mail with generated ID
+func (t *Template[T]) NewMail(locale string, data T, to ...GenericReceipient) (*Mail, error) {
+	return t.engine.NewMail(t.name, locale, data, to...)
+}
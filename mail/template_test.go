@@ -0,0 +1,130 @@
+package mail_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+type welcomeData struct {
+	Name string
+	URL  string
+}
+
+func TestTemplateEngine(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`<html><head><style>p { color: #333333; } .button { color: #ffffff; }</style></head>` +
+			`<body>{{template "content" .}}<p>{{template "footer" .}}</p></body></html>`)},
+		"layouts/plain.html":   {Data: []byte(`<div>{{template "content" .}}</div>`)},
+		"partials/footer.html": {Data: []byte(`Sweet Go Team`)},
+		"partials/button.html": {Data: []byte(`<a class="button" href="{{.URL}}">{{.Name}}</a>`)},
+		"templates/welcome.html": {Data: []byte(`{{define "subject"}}Welcome {{.Name}} & friends{{end}}` +
+			`{{define "content"}}<p>Hello {{.Name}}</p>{{template "button" .}}{{end}}`)},
+		"templates/welcome.id.html": {Data: []byte(`{{define "subject"}}Selamat datang {{.Name}}{{end}}` +
+			`{{define "content"}}<p>Halo {{.Name}}</p>{{end}}`)},
+		"templates/reset.html": {Data: []byte(`{{define "subject"}}Reset password{{end}}` +
+			`{{define "content"}}<p>Reset at {{.URL}}</p>{{end}}` +
+			`{{define "text"}}Reset at {{.URL}} & ignore if not you{{end}}`)},
+	}
+
+	engine, err := mail.NewTemplateEngine(&mail.TemplateOpts{
+		FS:              fsys,
+		DefaultLocale:   "en",
+		TemplateLayouts: map[string]string{"reset": "plain"},
+	})
+	assert.NoError(t, err)
+
+	data := welcomeData{Name: "Tom <3", URL: "https://example.com/start?a=1&b=2"}
+
+	t.Run("render with layout, partials and inlined css", func(t *testing.T) {
+		rendered, err := engine.Render("welcome", "", data)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Welcome Tom <3 & friends", rendered.Subject)
+		assert.Contains(t, rendered.HTMLContent, `<p style="color: #333333">Hello Tom &lt;3</p>`)
+		assert.Contains(t, rendered.HTMLContent, `<a class="button" href="https://example.com/start?a=1&amp;b=2" style="color: #ffffff">Tom &lt;3</a>`)
+		assert.NotContains(t, rendered.HTMLContent, "<style>")
+		assert.Equal(t, "Hello Tom <3\n\nTom <3 (https://example.com/start?a=1&b=2)\n\nSweet Go Team", rendered.TextContent)
+	})
+
+	t.Run("locale variants", func(t *testing.T) {
+		for _, locale := range []string{"id", "id-ID", "ID_id"} {
+			rendered, err := engine.Render("welcome", locale, data)
+			assert.NoError(t, err)
+			assert.Equal(t, "Selamat datang Tom <3", rendered.Subject)
+		}
+
+		rendered, err := engine.Render("welcome", "fr", data)
+		assert.NoError(t, err)
+		assert.Equal(t, "Welcome Tom <3 & friends", rendered.Subject)
+	})
+
+	t.Run("explicit text block and template layout", func(t *testing.T) {
+		rendered, err := engine.Render("reset", "en", data)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "<div><p>Reset at https://example.com/start?a=1&amp;b=2</p></div>", rendered.HTMLContent)
+		assert.Equal(t, "Reset at https://example.com/start?a=1&b=2 & ignore if not you", rendered.TextContent)
+	})
+
+	t.Run("typed template to mail", func(t *testing.T) {
+		tmpl, err := mail.NewTemplate[welcomeData](engine, "welcome")
+		assert.NoError(t, err)
+
+		m, err := tmpl.NewMail("id", data, mail.GenericReceipient{Email: "tom@example.com"})
+		assert.NoError(t, err)
+
+		assert.NotEmpty(t, m.ID)
+		assert.Equal(t, "Selamat datang Tom <3", m.Subject)
+		assert.Equal(t, "tom@example.com", m.To[0].Email)
+		assert.Contains(t, m.HTMLContent, "Halo Tom &lt;3")
+		assert.True(t, strings.HasPrefix(m.TextContent, "Halo Tom <3"))
+
+		_, err = mail.NewTemplate[welcomeData](engine, "unknown")
+		assert.ErrorIs(t, err, mail.ErrTemplateNotFound)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := engine.Render("unknown", "en", data)
+		assert.ErrorIs(t, err, mail.ErrTemplateNotFound)
+	})
+
+	t.Run("missing data field", func(t *testing.T) {
+		_, err := engine.Render("welcome", "en", map[string]string{})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		_, err := mail.NewTemplateEngine(nil)
+		assert.ErrorIs(t, err, mail.ErrInvalidTemplate)
+
+		_, err = mail.NewTemplateEngine(&mail.TemplateOpts{FS: fstest.MapFS{
+			"templates/welcome.html": {Data: []byte(`{{define "content"}}Hello{{end}}`)},
+		}})
+		assert.ErrorIs(t, err, mail.ErrInvalidTemplate)
+
+		_, err = mail.NewTemplateEngine(&mail.TemplateOpts{FS: fstest.MapFS{
+			"layouts/main.html":      {Data: []byte(`{{template "content" .}}`)},
+			"templates/welcome.html": {Data: []byte(`{{define "subject"}}Hi{{end}}{{define "content"}}Hello{{end}}`)},
+		}})
+		assert.ErrorIs(t, err, mail.ErrInvalidTemplate)
+	})
+
+	t.Run("without layouts", func(t *testing.T) {
+		engine, err := mail.NewTemplateEngine(&mail.TemplateOpts{
+			FS: fstest.MapFS{
+				"templates/welcome.html": {Data: []byte(`{{define "subject"}}Hi{{end}}{{define "content"}}<p>Hello {{.}}</p>{{end}}`)},
+			},
+			DisableTextFallback: true,
+		})
+		assert.NoError(t, err)
+
+		rendered, err := engine.Render("welcome", "", "Tom")
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>Hello Tom</p>", rendered.HTMLContent)
+		assert.Empty(t, rendered.TextContent)
+	})
+}
@@ -0,0 +1,116 @@
+package mail
+
+import (
+	"embed"
+	"errors"
+	"html/template"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed viewer/*.html
+var viewerFS embed.FS
+
+var viewerTemplates = template.Must(template.ParseFS(viewerFS, "viewer/*.html"))
+
+// viewerHTMLCSP forbids the scripts and the remote resources except images in the captured HTML
+const viewerHTMLCSP = "sandbox; default-src 'none'; img-src data: http: https:; style-src 'unsafe-inline'"
+
+type viewer struct {
+	store CapturedMailStore
+}
+
+// NewViewer returns the read-only HTTP handler to browse the captured mails of the store, e.g. File or InMemory client.
+// Should only be used in local development. The links are relative, so it can be mounted under a prefix
+// using http.StripPrefix:
+//
+//	GET /                                 the list of the mails
+//	GET /messages/{key}                   the mail headers, HTML and text contents
+//	GET /messages/{key}/html              the HTML content, with the inline images embedded
+//	GET /messages/{key}/raw               the .eml file
+//	GET /messages/{key}/attachments/{i}   the i-th attachment
+func NewViewer(store CapturedMailStore) http.Handler {
+	return &viewer{store: store}
+}
+
+func (v *viewer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set(echo.HeaderAllow, "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Path == "/" || r.URL.Path == "" {
+		v.index(w)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/messages/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/messages/"), "/")
+	captured, err := v.store.Get(parts[0])
+	if errors.Is(err, ErrCapturedMailNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		v.render(w, "message.html", captured)
+	case len(parts) == 2 && parts[1] == "html":
+		w.Header().Set(echo.HeaderContentSecurityPolicy, viewerHTMLCSP)
+		w.Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		_, _ = w.Write([]byte(captured.Mail.htmlContentWithDataURI()))
+	case len(parts) == 2 && parts[1] == "raw":
+		w.Header().Set(echo.HeaderContentType, "message/rfc822")
+		w.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": captured.Key + FileExtension}))
+		_, _ = w.Write(captured.Raw)
+	case len(parts) == 3 && parts[1] == "attachments":
+		i, err := strconv.Atoi(parts[2])
+		if err != nil || i < 0 || i >= len(captured.Mail.Attachments) {
+			http.NotFound(w, r)
+			return
+		}
+
+		a := captured.Mail.Attachments[i]
+		w.Header().Set(echo.HeaderContentType, a.MIMEType())
+		w.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+		w.Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+		_, _ = w.Write(a.Content)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (v *viewer) index(w http.ResponseWriter) {
+	mails, err := v.store.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	v.render(w, "index.html", mails)
+}
+
+func (v *viewer) render(w http.ResponseWriter, name string, data any) {
+	var sb strings.Builder
+	if err := viewerTemplates.ExecuteTemplate(&sb, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	_, _ = w.Write([]byte(sb.String()))
+}
@@ -0,0 +1,89 @@
+package mail_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/mail"
+)
+
+func TestViewer(t *testing.T) {
+	client := mail.NewInMemoryClient()
+	_, err := client.SendEmail(context.TODO(), &mail.Mail{
+		ID:           "welcome",
+		To:           []mail.GenericReceipient{{Name: "Tom", Email: "tom@example.com"}},
+		Subject:      "Welcome <Tom>",
+		HTMLContent:  `<p>Welcome</p><img src="cid:logo.png">`,
+		TextContent:  "Welcome",
+		Attachments:  []mail.Attachment{{Filename: "terms.pdf", ContentType: "application/pdf", Content: []byte("pdf")}},
+		InlineImages: []mail.Attachment{{Filename: "logo.png", ContentType: "image/png", Content: []byte("png")}},
+	})
+	assert.NoError(t, err)
+
+	key := client.Last().Key
+	viewer := http.StripPrefix("/mails", mail.NewViewer(client))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		viewer.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	t.Run("ok - index", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/mails/")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `href="messages/`+key+`"`)
+		assert.Contains(t, rec.Body.String(), "Welcome &lt;Tom&gt;")
+	})
+
+	t.Run("ok - message", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/mails/messages/"+key)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `<iframe sandbox src="`+key+`/html">`)
+		assert.Contains(t, rec.Body.String(), `href="`+key+`/attachments/0">terms.pdf`)
+		assert.Contains(t, rec.Body.String(), "&lt;welcome@localhost&gt;")
+	})
+
+	t.Run("ok - html", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/mails/messages/"+key+"/html")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Security-Policy"), "sandbox")
+		assert.Equal(t, `<p>Welcome</p><img src="data:image/png;base64,cG5n">`, rec.Body.String())
+	})
+
+	t.Run("ok - raw", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/mails/messages/"+key+"/raw")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "message/rfc822", rec.Header().Get("Content-Type"))
+		assert.Equal(t, client.Last().Raw, rec.Body.Bytes())
+	})
+
+	t.Run("ok - attachment", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/mails/messages/"+key+"/attachments/0")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment; filename=terms.pdf", rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "pdf", rec.Body.String())
+	})
+
+	t.Run("err - not found", func(t *testing.T) {
+		for _, path := range []string{
+			"/mails/unknown",
+			"/mails/messages/999",
+			"/mails/messages/" + key + "/attachments/1",
+			"/mails/messages/" + key + "/attachments/x",
+			"/mails/messages/" + key + "/unknown",
+		} {
+			assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, path).Code, path)
+		}
+	})
+
+	t.Run("err - method not allowed", func(t *testing.T) {
+		rec := serve(http.MethodDelete, "/mails/messages/"+key)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+	})
+}
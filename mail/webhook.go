@@ -0,0 +1,102 @@
+package mail
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// list of default webhook options
+const (
+	// DefaultWebhookMaxAge is the max age of the signed webhook request, rejecting the requests replayed later
+	DefaultWebhookMaxAge = 5 * time.Minute
+
+	// DefaultWebhookMaxBodySize is the max size of the webhook request body
+	DefaultWebhookMaxBodySize = 1 << 20
+)
+
+// EventType is the normalized type of the delivery status event
+type EventType string
+
+// list of EventType
+const (
+	EventDelivered  EventType = "delivered"
+	EventBounced    EventType = "bounced"
+	EventComplained EventType = "complained"
+	EventOpened     EventType = "opened"
+	EventClicked    EventType = "clicked"
+)
+
+// Event is the delivery status event reported by the provider webhook
+type Event struct {
+	Type     EventType       `json:"type"`
+	Provider ClientSignature `json:"provider"`
+
+	// MessageID is the normalized Message-ID returned by Client.SendEmail, see NormalizeMessageID
+	MessageID string    `json:"message_id"`
+	Recipient string    `json:"recipient"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// Permanent is true when the bounce will not be retried by the provider, e.g. the mailbox doesn't exist
+	Permanent bool `json:"permanent,omitempty"`
+
+	// Reason is the bounce or complaint reason told by the provider
+	Reason string `json:"reason,omitempty"`
+
+	// URL is the clicked link
+	URL  string   `json:"url,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+
+	// ProviderEventID identifies the event in the provider, if any. The provider may deliver the same event more than once
+	ProviderEventID string `json:"provider_event_id,omitempty"`
+}
+
+// EventSink receives the normalized events from the webhook handlers. Should be idempotent,
+// since the providers retry the webhook when the handler fails
+type EventSink interface {
+	// HandleEvent handles the event. Returning error makes the webhook respond 500, so the provider retries it later
+	HandleEvent(ctx context.Context, event *Event) error
+}
+
+// EventSinkFunc is the function implementing EventSink
+type EventSinkFunc func(ctx context.Context, event *Event) error
+
+// HandleEvent calls f(ctx, event)
+func (f EventSinkFunc) HandleEvent(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// NormalizeMessageID strips the angle brackets and spaces of the Message-ID, since the providers are inconsistent
+// whether to include them. Use it to key the sent mails by the Message-ID returned by Client.SendEmail
+func NormalizeMessageID(id string) string {
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(id), "<>"))
+}
+
+// readWebhookBody reads the request body up to DefaultWebhookMaxBodySize
+func readWebhookBody(c echo.Context) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, DefaultWebhookMaxBodySize+1))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "failed to read webhook body").SetInternal(err)
+	}
+
+	if len(body) > DefaultWebhookMaxBodySize {
+		return nil, echo.NewHTTPError(http.StatusRequestEntityTooLarge)
+	}
+
+	return body, nil
+}
+
+// dispatchEvents sends the events to the sink, responding 200 so the provider doesn't retry
+func dispatchEvents(c echo.Context, sink EventSink, events []*Event) error {
+	for _, event := range events {
+		if err := sink.HandleEvent(c.Request().Context(), event); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to handle webhook event").SetInternal(err)
+		}
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,44 @@
+// Package metrics contains Prometheus metrics functionality shared by the other packages
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MustRegister registers collector to reg, and returns the collector to be used. If an equal collector
+// is already registered, the existing one is returned instead, so the instrumentation can be created more than once
+// sharing the same registry. Will panic on any other registration error.
+// If reg is nil, will use prometheus.DefaultRegisterer
+func MustRegister[T prometheus.Collector](reg prometheus.Registerer, collector T) T {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	err := reg.Register(collector)
+	if err == nil {
+		return collector
+	}
+
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+
+	panic(err)
+}
+
+// Handler returns http.Handler exposing the metrics gathered by gatherer in Prometheus text format.
+// If gatherer is nil, will use prometheus.DefaultGatherer
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,29 @@
+// Package requestid carries the request ID in the context without depending on any http framework,
+// so the packages propagating it, e.g. worker and mail, don't pull in echo
+package requestid
+
+import "context"
+
+// Header is the header carrying the request ID, same as echo.HeaderXRequestID
+const Header = "X-Request-Id"
+
+// CtxKeyType is the type for context key for request ID
+type CtxKeyType string
+
+// CtxKey is the key for request ID in context
+const CtxKey CtxKeyType = "github.com/sweet-go/stdlib:echo_middleware:ReqIDCtxKey"
+
+// FromContext returns the request ID found in ctx, or empty string if none
+func FromContext(ctx context.Context) string {
+	id, ok := ctx.Value(CtxKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return id
+}
+
+// NewContext returns a copy of ctx carrying the request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, CtxKey, id)
+}
@@ -0,0 +1,18 @@
+package requestid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		assert.Equal(t, "request-id", FromContext(NewContext(context.TODO(), "request-id")))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		assert.Empty(t, FromContext(context.TODO()))
+	})
+}
@@ -0,0 +1,113 @@
+// Package tracing contains OpenTelemetry tracing functionality shared by the other packages
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the instrumentation scope name used by every tracer created in this module
+const InstrumentationName = "github.com/sweet-go/stdlib"
+
+// TracerProviderOpts is the options for NewTracerProvider
+type TracerProviderOpts struct {
+	// ServiceName is required
+	ServiceName string
+
+	// Exporter if nil, spans are recorded but never exported anywhere
+	Exporter sdktrace.SpanExporter
+
+	// SampleRatio is the ratio of the root spans to be sampled. Zero or greater than one will sample every span.
+	// Child spans always follow the parent sampling decision
+	SampleRatio float64
+
+	// Synchronous exports every span as soon as it ends instead of batching it. Should only be used for testing
+	Synchronous bool
+}
+
+// NewTracerProvider creates sdk tracer provider. Remember to call Shutdown when the application stops
+// so the buffered spans are flushed
+func NewTracerProvider(opts *TracerProviderOpts) *sdktrace.TracerProvider {
+	sampler := sdktrace.AlwaysSample()
+	if opts.SampleRatio > 0 && opts.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(opts.SampleRatio)
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(opts.ServiceName))),
+	}
+
+	if opts.Exporter != nil {
+		if opts.Synchronous {
+			providerOpts = append(providerOpts, sdktrace.WithSyncer(opts.Exporter))
+		} else {
+			providerOpts = append(providerOpts, sdktrace.WithBatcher(opts.Exporter))
+		}
+	}
+
+	return sdktrace.NewTracerProvider(providerOpts...)
+}
+
+// NewInMemoryTracerProvider creates tracer provider synchronously exporting the spans to memory.
+// Useful for testing since nothing external is required
+func NewInMemoryTracerProvider(serviceName string) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := NewTracerProvider(&TracerProviderOpts{
+		ServiceName: serviceName,
+		Exporter:    exporter,
+		Synchronous: true,
+	})
+
+	return tp, exporter
+}
+
+// SetGlobal registers tp and the W3C propagator as the OpenTelemetry global, so the instrumentation
+// created without explicit tracer provider will use it
+func SetGlobal(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(Propagator())
+}
+
+// Propagator returns the W3C trace context and baggage propagator used to propagate
+// trace context through http headers and task metadata
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// Tracer returns the module tracer from tp. If tp is nil, will use the global tracer provider
+func Tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return tp.Tracer(InstrumentationName)
+}
+
+// EndSpan records err to span if not nil, then ends the span
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// TraceIDFromContext returns the trace ID of the span found in ctx, or empty string if none
+func TraceIDFromContext(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+
+	return sc.TraceID().String()
+}
@@ -0,0 +1,60 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestTracing(t *testing.T) {
+	tp, exporter := tracing.NewInMemoryTracerProvider("test-service")
+	tracer := tracing.Tracer(tp)
+
+	t.Run("ok", func(t *testing.T) {
+		exporter.Reset()
+
+		ctx, span := tracer.Start(context.TODO(), "operation")
+		assert.NotEmpty(t, tracing.TraceIDFromContext(ctx))
+		tracing.EndSpan(span, nil)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "operation", spans[0].Name)
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+		assert.Equal(t, tracing.InstrumentationName, spans[0].InstrumentationLibrary.Name)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		exporter.Reset()
+
+		_, span := tracer.Start(context.TODO(), "operation")
+		tracing.EndSpan(span, errors.New("boom"))
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, "boom", spans[0].Status.Description)
+		assert.Len(t, spans[0].Events, 1)
+	})
+
+	t.Run("propagate trace context", func(t *testing.T) {
+		ctx, span := tracer.Start(context.TODO(), "parent")
+		defer span.End()
+
+		carrier := propagation.MapCarrier{}
+		tracing.Propagator().Inject(ctx, carrier)
+		assert.Contains(t, carrier.Get("traceparent"), tracing.TraceIDFromContext(ctx))
+
+		extracted := tracing.Propagator().Extract(context.TODO(), carrier)
+		assert.Equal(t, tracing.TraceIDFromContext(ctx), tracing.TraceIDFromContext(extracted))
+	})
+
+	t.Run("no span", func(t *testing.T) {
+		assert.Empty(t, tracing.TraceIDFromContext(context.TODO()))
+	})
+}
@@ -10,17 +10,65 @@ import (
 	"github.com/hibiken/asynq"
 	"github.com/sirupsen/logrus"
 	"github.com/sweet-go/stdlib/helper"
+	"github.com/sweet-go/stdlib/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type worker struct {
 	client    *asynq.Client
 	server    *asynq.Server
 	scheduler *asynq.Scheduler
+
+	metadataInjectors  []TaskMetadataInjector
+	metadataExtractors []TaskMetadataExtractor
+	unwrapTaskMetadata bool
+
+	// tracerProvider if nil, tracing is disabled
+	tracerProvider trace.TracerProvider
+
+	// metricsOpts if nil, metrics are disabled
+	metricsOpts *TaskMetricsOpts
 }
 
 // Client is the worker client
 type Client interface {
-	EnqueueTask(ctx context.Context, task *asynq.Task) (*asynq.TaskInfo, error)
+	// EnqueueTask enqueue the task. opts will override the options supplied when creating the task
+	EnqueueTask(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+}
+
+// ClientOpts is the options for NewClientWithOpts
+type ClientOpts struct {
+	// PropagateRequestID stamps the request ID found in the enqueue context into the task metadata.
+	// The task not created using NewTask is re-created by wrapping its payload, typed as TaskEnvelopeTypePrefix
+	// followed by the original type, and the options supplied to asynq.NewTask are not kept.
+	// Supply the task options through EnqueueTask opts instead.
+	//
+	// This changes the wire format of the task. Before enabling it, migrate every consumer of the task types:
+	// enable ServerOpts.UnwrapTaskMetadata and read the payload in the handlers using TaskPayload.
+	// The consumer not migrated yet fails the task since no handler is registered for the prefixed type,
+	// so the task is retried until the consumer is migrated, instead of being processed using the wrapped payload
+	PropagateRequestID bool
+
+	// TracerProvider enables tracing when not nil. A producer span is created for every enqueued task
+	// and its W3C trace context is stamped into the task metadata, with the same caveat and consumer migration
+	// as PropagateRequestID
+	TracerProvider trace.TracerProvider
+}
+
+// ServerOpts is the options for NewServerWithOpts
+type ServerOpts struct {
+	// UnwrapTaskMetadata unwraps the task created by NewTask or enqueued by the client propagating the metadata,
+	// so the handler context carries the propagated request ID and the task reaches the handler registered for its
+	// original type. The handlers must read the payload using TaskPayload, since Task.Payload returns the wrapped payload
+	UnwrapTaskMetadata bool
+
+	// TracerProvider enables tracing when not nil. A consumer span is created for every processed task,
+	// as the child of the enqueuing span propagated through the task metadata if UnwrapTaskMetadata is enabled
+	TracerProvider trace.TracerProvider
+
+	// Metrics enables the task outcome metrics recorded by TaskMetricsMiddleware when not nil
+	Metrics *TaskMetricsOpts
 }
 
 // Server is the worker server
@@ -102,6 +150,15 @@ var DefaultEnqueueTaskFailureHandler = func(task *asynq.Task, opts []asynq.Optio
 
 // NewClient create a new worker client
 func NewClient(redisHost string) (Client, error) {
+	return NewClientWithOpts(redisHost, nil)
+}
+
+// NewClientWithOpts create a new worker client with options
+func NewClientWithOpts(redisHost string, opts *ClientOpts) (Client, error) {
+	if opts == nil {
+		opts = &ClientOpts{}
+	}
+
 	redisOpts, err := asynq.ParseRedisURI(redisHost)
 	if err != nil {
 		logrus.Error(err)
@@ -112,13 +169,33 @@ func NewClient(redisHost string) (Client, error) {
 
 	logrus.Info("worker client created")
 
-	return &worker{
+	w := &worker{
 		client: client,
-	}, nil
+	}
+
+	if opts.PropagateRequestID {
+		w.metadataInjectors = append(w.metadataInjectors, InjectRequestID)
+	}
+
+	if opts.TracerProvider != nil {
+		w.tracerProvider = opts.TracerProvider
+		w.metadataInjectors = append(w.metadataInjectors, InjectTraceContext)
+	}
+
+	return w, nil
 }
 
 // NewServer creates a new worker server
 func NewServer(redisHost string, serverCfg asynq.Config, schedulerCfg *asynq.SchedulerOpts) (Server, error) {
+	return NewServerWithOpts(redisHost, serverCfg, schedulerCfg, nil)
+}
+
+// NewServerWithOpts creates a new worker server with options
+func NewServerWithOpts(redisHost string, serverCfg asynq.Config, schedulerCfg *asynq.SchedulerOpts, opts *ServerOpts) (Server, error) {
+	if opts == nil {
+		opts = &ServerOpts{}
+	}
+
 	redisOpts, err := asynq.ParseRedisURI(redisHost)
 	if err != nil {
 		logrus.Error(err)
@@ -133,17 +210,46 @@ func NewServer(redisHost string, serverCfg asynq.Config, schedulerCfg *asynq.Sch
 
 	scheduler := asynq.NewScheduler(redisOpts, schedulerCfg)
 
-	return &worker{
-		client:    client,
-		server:    server,
-		scheduler: scheduler,
-	}, nil
+	w := &worker{
+		client:             client,
+		server:             server,
+		scheduler:          scheduler,
+		unwrapTaskMetadata: opts.UnwrapTaskMetadata,
+		metadataExtractors: []TaskMetadataExtractor{ExtractRequestID},
+		metricsOpts:        opts.Metrics,
+	}
+
+	if opts.TracerProvider != nil {
+		w.tracerProvider = opts.TracerProvider
+		w.metadataExtractors = append(w.metadataExtractors, ExtractTraceContext)
+	}
+
+	return w, nil
 }
 
-// Start start worker server
+// Start start worker server. If ServerOpts.UnwrapTaskMetadata is enabled, tasks created using NewTask or enqueued
+// by client with metadata propagation are unwrapped before reaching the mux, so the handlers receive the propagated
+// request ID and read the original payload using TaskPayload
 func (w *worker) Start(mux *asynq.ServeMux, errch chan error) {
 	logrus.Info("starting worker...")
 
+	var handler asynq.Handler = mux
+	if w.unwrapTaskMetadata {
+		handler = RouteByTaskType(mux)
+	}
+
+	if w.metricsOpts != nil {
+		handler = TaskMetricsMiddleware(w.metricsOpts)(handler)
+	}
+
+	if w.tracerProvider != nil {
+		handler = TaskTracingMiddleware(w.tracerProvider)(handler)
+	}
+
+	if w.unwrapTaskMetadata {
+		handler = TaskMetadataMiddleware(w.metadataExtractors...)(handler)
+	}
+
 	go func() {
 		logrus.Info("start to run the scheduler")
 		if err := w.scheduler.Run(); err != nil {
@@ -155,7 +261,7 @@ func (w *worker) Start(mux *asynq.ServeMux, errch chan error) {
 
 	go func() {
 		logrus.Info("worker running...")
-		if err := w.server.Run(mux); err != nil {
+		if err := w.server.Run(handler); err != nil {
 			logrus.Error(err)
 			errch <- err
 		}
@@ -194,11 +300,33 @@ func (w *worker) RegisterScheduler(task *asynq.Task, cronspec string) error {
 	return nil
 }
 
-func (w *worker) EnqueueTask(ctx context.Context, task *asynq.Task) (*asynq.TaskInfo, error) {
-	info, err := w.client.EnqueueContext(ctx, task)
+func (w *worker) EnqueueTask(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (_ *asynq.TaskInfo, err error) {
+	var span trace.Span
+	if w.tracerProvider != nil {
+		ctx, span = startEnqueueSpan(ctx, tracing.Tracer(w.tracerProvider), task)
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+	}
+
+	if _, wrapped := unwrapTask(task); len(w.metadataInjectors) > 0 && !wrapped {
+		task, err = NewTask(ctx, task.Type(), task.Payload(), w.metadataInjectors)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := w.client.EnqueueContext(ctx, task, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	if span != nil {
+		span.SetAttributes(
+			semconv.MessagingMessageIDKey.String(info.ID),
+			semconv.MessagingDestinationNameKey.String(info.Queue),
+		)
+	}
+
 	return info, nil
 }
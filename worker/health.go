@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"sync"
+)
+
+// HealthStatus keeps the last health check result reported by the worker server,
+// so it can be exposed through the readiness endpoint
+type HealthStatus struct {
+	mu  sync.RWMutex
+	err error
+}
+
+// NewHealthStatus creates a new HealthStatus. Use HealthCheckFn as asynq.Config.HealthCheckFunc
+func NewHealthStatus() *HealthStatus {
+	return &HealthStatus{}
+}
+
+// HealthCheckFn records the health check result, and logs the error using DefaultHealtCheckFn
+func (h *HealthStatus) HealthCheckFn(err error) {
+	DefaultHealtCheckFn(err)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.err = err
+}
+
+// Check returns the last reported health check error. Return nil if no health check is reported yet
+func (h *HealthStatus) Check(_ context.Context) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.err
+}
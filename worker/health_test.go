@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorker_HealthStatus(t *testing.T) {
+	status := NewHealthStatus()
+	assert.NoError(t, status.Check(context.TODO()))
+
+	status.HealthCheckFn(errors.New("redis is down"))
+	assert.EqualError(t, status.Check(context.TODO()), "redis is down")
+
+	status.HealthCheckFn(nil)
+	assert.NoError(t, status.Check(context.TODO()))
+}
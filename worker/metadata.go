@@ -0,0 +1,182 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/hibiken/asynq"
+	"github.com/sweet-go/stdlib/requestid"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// TaskHeaderRequestID is the task metadata header carrying the request ID
+const TaskHeaderRequestID = requestid.Header
+
+// TaskHeaderTraceParent is the task metadata header carrying the W3C trace context
+const TaskHeaderTraceParent = "traceparent"
+
+// TaskEnvelopeTypePrefix prefixes the type of the task carrying the metadata. The server not unwrapping the metadata
+// finds no handler for the prefixed type and fails the task, instead of passing the wrapped payload to the handler
+const TaskEnvelopeTypePrefix = "stdlib:envelope:"
+
+const taskEnvelopeVersion = 1
+
+type taskMetadataCtxKey struct{}
+
+// taskMetadata is the original type and payload of the task unwrapped by TaskMetadataMiddleware
+type taskMetadata struct {
+	typename string
+	payload  []byte
+}
+
+// taskEnvelope wraps the original task payload alongside the propagated metadata since asynq task has no headers
+type taskEnvelope struct {
+	Version int               `json:"stdlib_task_envelope"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Payload []byte            `json:"payload"`
+}
+
+// TaskMetadataInjector returns headers to be stamped into task metadata based on the enqueue context
+type TaskMetadataInjector func(ctx context.Context, headers map[string]string)
+
+// TaskMetadataExtractor returns handler context enriched using the task metadata headers
+type TaskMetadataExtractor func(ctx context.Context, headers map[string]string) context.Context
+
+// InjectRequestID is TaskMetadataInjector stamping the request ID found in ctx
+func InjectRequestID(ctx context.Context, headers map[string]string) {
+	if rid := requestid.FromContext(ctx); rid != "" {
+		headers[TaskHeaderRequestID] = rid
+	}
+}
+
+// ExtractRequestID is TaskMetadataExtractor re-hydrating the request ID into the handler context
+func ExtractRequestID(ctx context.Context, headers map[string]string) context.Context {
+	if rid := headers[TaskHeaderRequestID]; rid != "" {
+		return requestid.NewContext(ctx, rid)
+	}
+
+	return ctx
+}
+
+// InjectTraceContext is TaskMetadataInjector stamping the W3C trace context of the span found in ctx
+func InjectTraceContext(ctx context.Context, headers map[string]string) {
+	tracing.Propagator().Inject(ctx, propagation.MapCarrier(headers))
+}
+
+// ExtractTraceContext is TaskMetadataExtractor re-hydrating the W3C trace context into the handler context,
+// so the spans created by the handler will be the children of the enqueuing span
+func ExtractTraceContext(ctx context.Context, headers map[string]string) context.Context {
+	return tracing.Propagator().Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// NewTask creates asynq task which payload is wrapped alongside metadata returned by the injectors,
+// typed as TaskEnvelopeTypePrefix followed by typename. If no injector is supplied, InjectRequestID is used.
+// The task must be processed by the server enabling ServerOpts.UnwrapTaskMetadata, or wrapped with
+// TaskMetadataMiddleware and routed using RouteByTaskType, and the handler must read the original payload using TaskPayload
+func NewTask(ctx context.Context, typename string, payload []byte, injectors []TaskMetadataInjector, opts ...asynq.Option) (*asynq.Task, error) {
+	if len(injectors) == 0 {
+		injectors = []TaskMetadataInjector{InjectRequestID}
+	}
+
+	headers := map[string]string{}
+	for _, inject := range injectors {
+		inject(ctx, headers)
+	}
+
+	wrapped, err := json.Marshal(&taskEnvelope{
+		Version: taskEnvelopeVersion,
+		Headers: headers,
+		Payload: payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(TaskEnvelopeTypePrefix+typename, wrapped, opts...), nil
+}
+
+// TaskMetadataMiddleware unwraps the task created by NewTask, then calls the next handler using the context enriched
+// by the extractors and carrying the original type and payload, see TaskType and TaskPayload. If no extractor is supplied,
+// ExtractRequestID is used. The task itself is passed as is, so Task.ResultWriter is kept, thus the next handler
+// must route using TaskType, e.g. RouteByTaskType
+func TaskMetadataMiddleware(extractors ...TaskMetadataExtractor) asynq.MiddlewareFunc {
+	if len(extractors) == 0 {
+		extractors = []TaskMetadataExtractor{ExtractRequestID}
+	}
+
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+			envelope, ok := unwrapTask(task)
+			if !ok {
+				return next.ProcessTask(ctx, task)
+			}
+
+			metadata := &taskMetadata{
+				typename: strings.TrimPrefix(task.Type(), TaskEnvelopeTypePrefix),
+				payload:  envelope.Payload,
+			}
+
+			for _, extract := range extractors {
+				ctx = extract(ctx, envelope.Headers)
+			}
+
+			return next.ProcessTask(context.WithValue(ctx, taskMetadataCtxKey{}, metadata), task)
+		})
+	}
+}
+
+// RouteByTaskType returns the handler dispatching the task to the mux handler of TaskType,
+// so the task unwrapped by TaskMetadataMiddleware reaches the handler registered for its original type
+func RouteByTaskType(mux *asynq.ServeMux) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		typename := TaskType(ctx, task)
+		if typename == task.Type() {
+			return mux.ProcessTask(ctx, task)
+		}
+
+		h, _ := mux.Handler(asynq.NewTask(typename, nil))
+
+		return h.ProcessTask(ctx, task)
+	})
+}
+
+// TaskType returns the original type of the task unwrapped by TaskMetadataMiddleware,
+// or Task.Type if the task has no metadata
+func TaskType(ctx context.Context, task *asynq.Task) string {
+	if metadata, ok := ctx.Value(taskMetadataCtxKey{}).(*taskMetadata); ok {
+		return metadata.typename
+	}
+
+	return task.Type()
+}
+
+// TaskPayload returns the original payload of the task unwrapped by TaskMetadataMiddleware,
+// or Task.Payload if the task has no metadata. The handlers of the server unwrapping the metadata
+// must read the payload using it, since Task.Payload returns the wrapped payload
+func TaskPayload(ctx context.Context, task *asynq.Task) []byte {
+	if metadata, ok := ctx.Value(taskMetadataCtxKey{}).(*taskMetadata); ok {
+		return metadata.payload
+	}
+
+	return task.Payload()
+}
+
+func unwrapTask(task *asynq.Task) (*taskEnvelope, bool) {
+	payload := task.Payload()
+	if !strings.HasPrefix(task.Type(), TaskEnvelopeTypePrefix) || len(payload) == 0 || payload[0] != '{' {
+		return nil, false
+	}
+
+	envelope := &taskEnvelope{}
+	if err := json.Unmarshal(payload, envelope); err != nil || envelope.Version != taskEnvelopeVersion {
+		return nil, false
+	}
+
+	if envelope.Headers == nil {
+		envelope.Headers = map[string]string{}
+	}
+
+	return envelope, true
+}
@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/requestid"
+)
+
+func TestWorker_TaskMetadata(t *testing.T) {
+	ctx := requestid.NewContext(context.TODO(), "request-id")
+	payload := []byte(`{"user_id":"123"}`)
+
+	t.Run("ok", func(t *testing.T) {
+		task, err := NewTask(ctx, "email:send", payload, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, TaskEnvelopeTypePrefix+"email:send", task.Type())
+		assert.NotEqual(t, payload, task.Payload())
+
+		handler := TaskMetadataMiddleware()(asynq.HandlerFunc(func(ctx context.Context, received *asynq.Task) error {
+			assert.Equal(t, "request-id", requestid.FromContext(ctx))
+			assert.Equal(t, "email:send", TaskType(ctx, received))
+			assert.Equal(t, payload, TaskPayload(ctx, received))
+			assert.Same(t, task, received)
+			return nil
+		}))
+
+		assert.NoError(t, handler.ProcessTask(context.TODO(), task))
+	})
+
+	t.Run("task without metadata", func(t *testing.T) {
+		task := asynq.NewTask("email:send", payload)
+
+		handler := TaskMetadataMiddleware()(asynq.HandlerFunc(func(ctx context.Context, received *asynq.Task) error {
+			assert.Empty(t, requestid.FromContext(ctx))
+			assert.Equal(t, task, received)
+			assert.Equal(t, "email:send", TaskType(ctx, received))
+			assert.Equal(t, payload, TaskPayload(ctx, received))
+			return nil
+		}))
+
+		assert.NoError(t, handler.ProcessTask(context.TODO(), task))
+	})
+
+	t.Run("route by task type", func(t *testing.T) {
+		task, err := NewTask(ctx, "email:send", payload, nil)
+		assert.NoError(t, err)
+
+		var called bool
+		mux := asynq.NewServeMux()
+		mux.HandleFunc("email:send", func(ctx context.Context, received *asynq.Task) error {
+			called = true
+			assert.Same(t, task, received)
+			assert.Equal(t, payload, TaskPayload(ctx, received))
+			return nil
+		})
+
+		assert.NoError(t, TaskMetadataMiddleware()(RouteByTaskType(mux)).ProcessTask(context.TODO(), task))
+		assert.True(t, called)
+	})
+
+	t.Run("server not unwrapping finds no handler", func(t *testing.T) {
+		task, err := NewTask(ctx, "email:send", payload, nil)
+		assert.NoError(t, err)
+
+		mux := asynq.NewServeMux()
+		mux.HandleFunc("email:send", func(ctx context.Context, received *asynq.Task) error {
+			t.Fatal("wrapped payload must not reach the handler")
+			return nil
+		})
+
+		assert.Error(t, mux.ProcessTask(context.TODO(), task))
+	})
+
+	t.Run("client propagate request id", func(t *testing.T) {
+		mr, err := miniredis.Run()
+		assert.NoError(t, err)
+		defer mr.Close()
+
+		client, err := NewClientWithOpts("redis://"+mr.Addr(), &ClientOpts{PropagateRequestID: true})
+		assert.NoError(t, err)
+
+		info, err := client.EnqueueTask(ctx, asynq.NewTask("email:send", payload), asynq.Queue(string(PriorityHigh)))
+		assert.NoError(t, err)
+		assert.Equal(t, string(PriorityHigh), info.Queue)
+		assert.Equal(t, TaskEnvelopeTypePrefix+"email:send", info.Type)
+
+		envelope, ok := unwrapTask(asynq.NewTask(info.Type, info.Payload))
+		assert.True(t, ok)
+		assert.Equal(t, "request-id", envelope.Headers[TaskHeaderRequestID])
+		assert.Equal(t, payload, envelope.Payload)
+
+		task, err := NewTask(ctx, "email:send", payload, nil, asynq.MaxRetry(1), asynq.Queue(string(PriorityLow)))
+		assert.NoError(t, err)
+
+		info, err = client.EnqueueTask(ctx, task)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, info.MaxRetry)
+		assert.Equal(t, string(PriorityLow), info.Queue)
+	})
+}
@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sweet-go/stdlib/metrics"
+)
+
+// list of task outcome label values
+const (
+	TaskOutcomeSuccess     = "success"
+	TaskOutcomeFailure     = "failure"
+	TaskOutcomeRateLimited = "rate_limited"
+	TaskOutcomeSkipRetry   = "skip_retry"
+)
+
+// TaskMetricsOpts is the options for TaskMetricsMiddleware
+type TaskMetricsOpts struct {
+	// Registerer if nil, will use prometheus.DefaultRegisterer. Use the same registry as the echo Metrics middleware
+	// to expose both http and worker metrics from the same endpoint
+	Registerer prometheus.Registerer
+
+	// Namespace and Subsystem are prepended to the metric names
+	Namespace string
+	Subsystem string
+
+	// Buckets is the task duration histogram buckets in seconds. Default to prometheus.DefBuckets
+	Buckets []float64
+}
+
+// TaskMetricsMiddleware records worker_tasks_processed_total counter and worker_task_duration_seconds histogram
+// labeled by task type, queue and outcome, and worker_tasks_in_progress gauge labeled by task type and queue
+func TaskMetricsMiddleware(opts *TaskMetricsOpts) asynq.MiddlewareFunc {
+	if opts == nil {
+		opts = &TaskMetricsOpts{}
+	}
+
+	buckets := opts.Buckets
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+
+	processed := metrics.MustRegister(opts.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: opts.Namespace,
+		Subsystem: opts.Subsystem,
+		Name:      "worker_tasks_processed_total",
+		Help:      "Total number of worker tasks processed.",
+	}, []string{"task_type", "queue", "outcome"}))
+
+	duration := metrics.MustRegister(opts.Registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: opts.Namespace,
+		Subsystem: opts.Subsystem,
+		Name:      "worker_task_duration_seconds",
+		Help:      "Duration of worker tasks processing in seconds.",
+		Buckets:   buckets,
+	}, []string{"task_type", "queue", "outcome"}))
+
+	inProgress := metrics.MustRegister(opts.Registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: opts.Namespace,
+		Subsystem: opts.Subsystem,
+		Name:      "worker_tasks_in_progress",
+		Help:      "Number of worker tasks currently being processed.",
+	}, []string{"task_type", "queue"}))
+
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+			queue, _ := asynq.GetQueueName(ctx)
+			typename := TaskType(ctx, task)
+
+			gauge := inProgress.WithLabelValues(typename, queue)
+			gauge.Inc()
+			defer gauge.Dec()
+
+			start := time.Now()
+			err := next.ProcessTask(ctx, task)
+
+			outcome := taskOutcome(err)
+			processed.WithLabelValues(typename, queue, outcome).Inc()
+			duration.WithLabelValues(typename, queue, outcome).Observe(time.Since(start).Seconds())
+
+			return err
+		})
+	}
+}
+
+func taskOutcome(err error) string {
+	var rateLimitErr *RateLimitError
+
+	switch {
+	case err == nil:
+		return TaskOutcomeSuccess
+	case errors.As(err, &rateLimitErr):
+		return TaskOutcomeRateLimited
+	case errors.Is(err, asynq.SkipRetry):
+		return TaskOutcomeSkipRetry
+	default:
+		return TaskOutcomeFailure
+	}
+}
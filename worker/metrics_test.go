@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorker_TaskMetricsMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	middleware := TaskMetricsMiddleware(&TaskMetricsOpts{Registerer: reg, Namespace: "app"})
+
+	results := []error{
+		nil,
+		errors.New("boom"),
+		NewRateLimitError(time.Second),
+		fmt.Errorf("invalid payload: %w", asynq.SkipRetry),
+	}
+
+	for _, result := range results {
+		result := result
+		handler := middleware(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+			return result
+		}))
+
+		assert.Equal(t, result, handler.ProcessTask(context.TODO(), asynq.NewTask("email:send", nil)))
+	}
+
+	expected := `
+# HELP app_worker_tasks_processed_total Total number of worker tasks processed.
+# TYPE app_worker_tasks_processed_total counter
+app_worker_tasks_processed_total{outcome="failure",queue="",task_type="email:send"} 1
+app_worker_tasks_processed_total{outcome="rate_limited",queue="",task_type="email:send"} 1
+app_worker_tasks_processed_total{outcome="skip_retry",queue="",task_type="email:send"} 1
+app_worker_tasks_processed_total{outcome="success",queue="",task_type="email:send"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "app_worker_tasks_processed_total"))
+	assert.Equal(t, 4, testutil.CollectAndCount(reg, "app_worker_task_duration_seconds"))
+}
@@ -36,16 +36,21 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 }
 
 // EnqueueTask mocks base method.
-func (m *MockClient) EnqueueTask(arg0 context.Context, arg1 *asynq.Task) (*asynq.TaskInfo, error) {
+func (m *MockClient) EnqueueTask(arg0 context.Context, arg1 *asynq.Task, arg2 ...asynq.Option) (*asynq.TaskInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "EnqueueTask", arg0, arg1)
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EnqueueTask", varargs...)
 	ret0, _ := ret[0].(*asynq.TaskInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // EnqueueTask indicates an expected call of EnqueueTask.
-func (mr *MockClientMockRecorder) EnqueueTask(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) EnqueueTask(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueTask", reflect.TypeOf((*MockClient)(nil).EnqueueTask), arg0, arg1)
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueTask", reflect.TypeOf((*MockClient)(nil).EnqueueTask), varargs...)
 }
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// messagingSystem is the messaging.system attribute value of the task spans
+const messagingSystem = "asynq"
+
+// TaskTracingMiddleware creates a consumer span for every processed task. Must be placed after TaskMetadataMiddleware
+// with ExtractTraceContext, so the span will be the child of the enqueuing span.
+// If tp is nil, will use the OpenTelemetry global tracer provider
+func TaskTracingMiddleware(tp trace.TracerProvider) asynq.MiddlewareFunc {
+	tracer := tracing.Tracer(tp)
+
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) (err error) {
+			typename := TaskType(ctx, task)
+			attrs := []attribute.KeyValue{
+				semconv.MessagingSystemKey.String(messagingSystem),
+				semconv.MessagingOperationProcess,
+				attribute.String("asynq.task.type", typename),
+			}
+
+			if id, ok := asynq.GetTaskID(ctx); ok {
+				attrs = append(attrs, semconv.MessagingMessageIDKey.String(id))
+			}
+
+			if queue, ok := asynq.GetQueueName(ctx); ok {
+				attrs = append(attrs, semconv.MessagingDestinationNameKey.String(queue))
+			}
+
+			if retry, ok := asynq.GetRetryCount(ctx); ok {
+				attrs = append(attrs, attribute.Int("asynq.task.retry_count", retry))
+			}
+
+			ctx, span := tracer.Start(ctx, fmt.Sprintf("%s process", typename),
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(attrs...),
+			)
+			defer func() {
+				tracing.EndSpan(span, err)
+			}()
+
+			return next.ProcessTask(ctx, task)
+		})
+	}
+}
+
+func startEnqueueSpan(ctx context.Context, tracer trace.Tracer, task *asynq.Task) (context.Context, trace.Span) {
+	return tracer.Start(ctx, fmt.Sprintf("%s publish", task.Type()),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(messagingSystem),
+			semconv.MessagingOperationPublish,
+			attribute.String("asynq.task.type", task.Type()),
+		),
+	)
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/sweet-go/stdlib/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWorker_Tracing(t *testing.T) {
+	tp, exporter := tracing.NewInMemoryTracerProvider("test-service")
+	payload := []byte(`{"user_id":"123"}`)
+
+	process := func(task *asynq.Task, handler asynq.HandlerFunc) error {
+		return TaskMetadataMiddleware(ExtractRequestID, ExtractTraceContext)(TaskTracingMiddleware(tp)(handler)).
+			ProcessTask(context.TODO(), task)
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		exporter.Reset()
+
+		ctx, parent := tracing.Tracer(tp).Start(context.TODO(), "parent")
+		task, err := NewTask(ctx, "email:send", payload, []TaskMetadataInjector{InjectTraceContext})
+		assert.NoError(t, err)
+		parent.End()
+
+		err = process(task, func(ctx context.Context, task *asynq.Task) error {
+			assert.Equal(t, parent.SpanContext().TraceID(), trace.SpanContextFromContext(ctx).TraceID())
+			assert.Equal(t, payload, TaskPayload(ctx, task))
+			return nil
+		})
+		assert.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 2)
+		assert.Equal(t, "email:send process", spans[1].Name)
+		assert.Equal(t, trace.SpanKindConsumer, spans[1].SpanKind)
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent.SpanID())
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		exporter.Reset()
+
+		err := process(asynq.NewTask("email:send", payload), func(ctx context.Context, task *asynq.Task) error {
+			return errors.New("boom")
+		})
+		assert.Error(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.False(t, spans[0].Parent.IsValid())
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+	})
+
+	t.Run("client enqueue span", func(t *testing.T) {
+		exporter.Reset()
+
+		mr, err := miniredis.Run()
+		assert.NoError(t, err)
+		defer mr.Close()
+
+		client, err := NewClientWithOpts("redis://"+mr.Addr(), &ClientOpts{TracerProvider: tp})
+		assert.NoError(t, err)
+
+		info, err := client.EnqueueTask(context.TODO(), asynq.NewTask("email:send", payload))
+		assert.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "email:send publish", spans[0].Name)
+		assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind)
+
+		envelope, ok := unwrapTask(asynq.NewTask(info.Type, info.Payload))
+		assert.True(t, ok)
+		assert.Contains(t, envelope.Headers[TaskHeaderTraceParent], spans[0].SpanContext.SpanID().String())
+		assert.Empty(t, envelope.Headers[TaskHeaderRequestID])
+	})
+}